@@ -17,6 +17,8 @@ var (
 	database string
 	dbType   string
 	sslMode  string
+
+	pipesAsConcat bool
 )
 
 var rootCmd = &cobra.Command{
@@ -68,6 +70,8 @@ These commands will be automatically translated to their PostgreSQL equivalents.
 			Database: database,
 			DBType:   dbType,
 			SSLMode:  sslMode,
+
+			PipesAsConcat: pipesAsConcat,
 		}
 
 		c, err := client.New(cfg)
@@ -96,6 +100,7 @@ func init() {
 	rootCmd.Flags().StringVarP(&database, "database", "d", "", "Database name")
 	rootCmd.Flags().StringVarP(&dbType, "type", "t", "mysql", "Database type: mysql or pg/postgresql")
 	rootCmd.Flags().StringVar(&sslMode, "sslmode", "disable", "PostgreSQL SSL mode: disable, require, verify-ca, verify-full")
+	rootCmd.Flags().BoolVar(&pipesAsConcat, "pipes-as-concat", false, "Treat || as string concatenation (MySQL PIPES_AS_CONCAT) instead of logical OR")
 
 	rootCmd.MarkFlagRequired("type")
 }
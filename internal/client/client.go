@@ -23,6 +23,8 @@ type Config struct {
 	Database string
 	DBType   string
 	SSLMode  string
+
+	PipesAsConcat bool // Translate || as concatenation rather than OR
 }
 
 // Client represents the database client
@@ -31,6 +33,17 @@ type Client struct {
 	translator     *translator.Translator
 	config         *Config
 	expandedOutput bool
+	tablesLocked   bool     // LOCK TABLES holds its locks in an open transaction
+	tableLocks     []string // LOCK TABLE statements of LOCK TABLES, taken again after each commit
+	delimiter      string   // Ends a statement; DELIMITER changes it for routine bodies
+
+	vars     map[string]interface{} // Session user variables, by lower-cased name
+	prepared map[string]string      // Statements prepared with PREPARE, by lower-cased name
+	warnings []string               // Warnings of the last statement, for SHOW WARNINGS
+
+	implicitTransactions bool   // SET autocommit = 0: every statement runs in a transaction
+	nextTransaction      string // Modes from SET TRANSACTION for the next transaction
+	xid                  string // Identifier of the open XA transaction
 }
 
 // New creates a new client
@@ -55,15 +68,28 @@ func New(cfg *Config) (*Client, error) {
 		return nil, err
 	}
 
+	tr := translator.New(dbType)
+	if dbType == db.PostgreSQL {
+		tr.SetCatalog(conn)
+		tr.SetPipesAsConcat(cfg.PipesAsConcat)
+	}
+
 	return &Client{
 		conn:       conn,
-		translator: translator.New(dbType),
+		translator: tr,
 		config:     cfg,
+		delimiter:  ";",
+		vars:       map[string]interface{}{},
+		prepared:   map[string]string{},
 	}, nil
 }
 
-// Close closes the client
+// Close closes the client. What ran under LOCK TABLES with autocommit on is
+// committed, as MySQL would have done statement by statement.
 func (c *Client) Close() error {
+	if c.tablesLocked && !c.implicitTransactions {
+		c.conn.Commit()
+	}
 	return c.conn.Close()
 }
 
@@ -136,10 +162,19 @@ func (c *Client) Run() error {
 			continue
 		}
 
-		// Handle multi-line input
-		if !strings.HasSuffix(line, ";") && !strings.HasPrefix(line, "\\") {
+		// DELIMITER lets statements such as CREATE PROCEDURE contain semicolons
+		if !inMultiLine && strings.HasPrefix(lowerLine, "delimiter ") {
+			if delimiter := strings.TrimSpace(line[len("delimiter "):]); delimiter != "" {
+				c.delimiter = delimiter
+			}
+			continue
+		}
+
+		// Handle multi-line input. Lines are kept apart so that -- comments
+		// end where they did.
+		if !strings.HasSuffix(line, c.delimiter) && !strings.HasPrefix(line, "\\") {
 			multiLineBuffer.WriteString(line)
-			multiLineBuffer.WriteString(" ")
+			multiLineBuffer.WriteString("\n")
 			inMultiLine = true
 			continue
 		}
@@ -153,6 +188,7 @@ func (c *Client) Run() error {
 		} else {
 			fullQuery = line
 		}
+		fullQuery = strings.TrimSuffix(fullQuery, c.delimiter)
 
 		if err := c.executeQuery(fullQuery); err != nil {
 			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
@@ -170,23 +206,123 @@ func (c *Client) getPrompt() string {
 
 func (c *Client) executeQuery(query string) error {
 	result, err := c.translator.Translate(query)
+	if result == nil || result.SpecialType != "show_warnings" {
+		c.warnings = nil
+	}
 	if err != nil {
 		return err
 	}
+	return c.execute(result)
+}
+
+// execute carries out a translated statement
+func (c *Client) execute(result *translator.TranslationResult) error {
+	// As in MySQL, a statement that cannot be part of a transaction commits
+	// the open one first, which releases the locks of LOCK TABLES
+	if result.NoTransaction {
+		if err := c.endTransaction("COMMIT"); err != nil {
+			return err
+		}
+	}
 
 	// Handle special commands
 	if result.IsSpecial {
 		return c.handleSpecialCommand(result)
 	}
 
+	if result.NoTransaction {
+		return c.run(result)
+	}
+	if c.tablesLocked && !c.implicitTransactions {
+		return c.runLocked(result)
+	}
+	if c.conn.InTransaction() || c.implicitTransactions {
+		return c.runInTransaction(result)
+	}
+	return c.run(result)
+}
+
+// run executes a translated statement and prints its outcome
+func (c *Client) run(result *translator.TranslationResult) error {
+	if len(result.Setup) > 0 {
+		if err := c.conn.ExecBatch(result.Setup); err != nil {
+			return err
+		}
+	}
+
+	// Statements a single MySQL statement was split into. There may be
+	// none when everything in it was ignored with a warning.
+	if len(result.Statements) > 0 || result.Query == "" {
+		if err := c.conn.ExecBatch(result.Statements); err != nil {
+			return err
+		}
+		fmt.Println("Query OK")
+		c.printWarnings(result.Warnings)
+		return nil
+	}
+
+	args := c.bindVariables(result.Params)
+	if result.ReturnsInsertID {
+		return c.insertReturningID(result, args)
+	}
+	if len(result.OutVars) > 0 {
+		return c.setVariables(result, args)
+	}
+
 	// Execute the query
-	rows, err := c.conn.Query(result.Query)
+	rows, err := c.conn.Query(result.Query, args...)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 
-	return c.printResults(rows)
+	if err := c.printResultsAssigning(rows, result.SelectVars); err != nil {
+		return err
+	}
+	c.printWarnings(result.Warnings)
+	return nil
+}
+
+// insertReturningID runs an INSERT that returns the keys it generated and
+// keeps the first one for LAST_INSERT_ID(), as MySQL does for multi-row inserts
+func (c *Client) insertReturningID(result *translator.TranslationResult, args []interface{}) error {
+	rows, err := c.conn.Query(result.Query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var count, first int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		if count == 0 {
+			first = id
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		fmt.Println("Query OK, 0 row(s) affected")
+	} else {
+		c.translator.SetLastInsertID(first)
+		fmt.Printf("Query OK, %d row(s) affected, last insert id %d\n", count, first)
+	}
+	c.printWarnings(result.Warnings)
+	return nil
+}
+
+// printWarnings reports what the translation could not carry over
+func (c *Client) printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	c.warnings = append(c.warnings, warnings...)
 }
 
 func (c *Client) handleSpecialCommand(result *translator.TranslationResult) error {
@@ -205,6 +341,7 @@ func (c *Client) handleSpecialCommand(result *translator.TranslationResult) erro
 
 	case "quit":
 		fmt.Println("Bye!")
+		c.Close()
 		os.Exit(0)
 		return nil
 
@@ -233,6 +370,39 @@ func (c *Client) handleSpecialCommand(result *translator.TranslationResult) erro
 		}
 		return c.showCreateDatabase(result.Args[0])
 
+	case "show_grants":
+		return c.showGrants(result)
+
+	case "transaction":
+		return c.transaction(result)
+
+	case "lock_tables":
+		return c.lockTables(result)
+
+	case "unlock_tables":
+		return c.unlockTables()
+
+	case "set":
+		return c.set(result)
+
+	case "show_warnings":
+		return c.showWarnings()
+
+	case "prepare":
+		return c.prepare(result)
+
+	case "execute":
+		return c.executePrepared(result)
+
+	case "deallocate":
+		return c.deallocate(result)
+
+	case "create_database_if_not_exists", "drop_database_if_exists":
+		if len(result.Args) < 1 {
+			return fmt.Errorf("database name required")
+		}
+		return c.createOrDropDatabase(result)
+
 	case "cross_db_query":
 		// For cross-database queries, we need to handle specially
 		// For now, just execute the query in current database
@@ -264,6 +434,73 @@ func (c *Client) handleSpecialCommand(result *translator.TranslationResult) erro
 	}
 }
 
+// lockTables takes PostgreSQL table locks in a transaction, which holds them
+// until UNLOCK TABLES. As in MySQL, LOCK TABLES first commits the open
+// transaction, which releases the tables locked before.
+func (c *Client) lockTables(result *translator.TranslationResult) error {
+	if err := c.checkNoXA(); err != nil {
+		return err
+	}
+	if c.conn.InTransaction() {
+		if err := c.conn.Commit(); err != nil {
+			return err
+		}
+	}
+	c.tablesLocked = false
+
+	c.tableLocks = result.Statements
+	if err := c.takeTableLocks(); err != nil {
+		return err
+	}
+	fmt.Println("Query OK")
+	return nil
+}
+
+// takeTableLocks opens the transaction holding the locks of LOCK TABLES
+func (c *Client) takeTableLocks() error {
+	if err := c.conn.Begin("BEGIN"); err != nil {
+		return err
+	}
+	for _, stmt := range c.tableLocks {
+		if _, err := c.conn.Exec(stmt); err != nil {
+			c.conn.Rollback()
+			return err
+		}
+	}
+	c.tablesLocked = true
+	return nil
+}
+
+// runLocked runs a statement under LOCK TABLES with autocommit on. MySQL
+// commits it right away, so it is committed too, which ends the transaction
+// holding the locks; they are then taken again. Another session may get a
+// lock in between, though not while the statement runs.
+func (c *Client) runLocked(result *translator.TranslationResult) error {
+	if err := c.runInTransaction(result); err != nil {
+		return err
+	}
+	c.tablesLocked = false
+	if err := c.conn.Commit(); err != nil {
+		return err
+	}
+	if err := c.takeTableLocks(); err != nil {
+		return fmt.Errorf("table locks could not be taken again: %w", err)
+	}
+	return nil
+}
+
+// unlockTables releases table locks by committing the transaction holding them
+func (c *Client) unlockTables() error {
+	if c.tablesLocked && c.conn.InTransaction() {
+		if err := c.conn.Commit(); err != nil {
+			return err
+		}
+	}
+	c.tablesLocked = false
+	fmt.Println("Query OK")
+	return nil
+}
+
 func (c *Client) showCreateTable(tableName string) error {
 	if c.conn.Config.DBType == db.MySQL {
 		rows, err := c.conn.Query("SHOW CREATE TABLE " + tableName)
@@ -274,10 +511,12 @@ func (c *Client) showCreateTable(tableName string) error {
 		return c.printResults(rows)
 	}
 
-	// PostgreSQL: Generate CREATE TABLE statement
-	query := fmt.Sprintf(`
+	// PostgreSQL: Generate CREATE TABLE statement. A column kept current by
+	// the trigger the translator installs for ON UPDATE CURRENT_TIMESTAMP is
+	// printed with that attribute; tgargs holds the column name.
+	query := `
 		SELECT 
-			'CREATE TABLE ' || '%s' || ' (' || E'\n' ||
+			'CREATE TABLE ' || $1::text || ' (' || E'\n' ||
 			string_agg(
 				'  ' || column_name || ' ' || 
 				CASE 
@@ -287,16 +526,25 @@ func (c *Client) showCreateTable(tableName string) error {
 					ELSE UPPER(data_type)
 				END ||
 				CASE WHEN is_nullable = 'NO' THEN ' NOT NULL' ELSE '' END ||
-				CASE WHEN column_default IS NOT NULL THEN ' DEFAULT ' || column_default ELSE '' END,
+				CASE WHEN column_default IS NOT NULL THEN ' DEFAULT ' || column_default ELSE '' END ||
+				CASE WHEN EXISTS (
+					SELECT FROM pg_trigger tg
+					JOIN pg_proc p ON p.oid = tg.tgfoid
+					JOIN pg_class cl ON cl.oid = tg.tgrelid
+					JOIN pg_namespace n ON n.oid = cl.relnamespace
+					WHERE p.proname = '` + translator.OnUpdateFunction + `'
+						AND n.nspname = c.table_schema AND cl.relname = c.table_name
+						AND tg.tgargs = convert_to(c.column_name::text, current_setting('server_encoding')) || '\x00'::bytea
+				) THEN ' ON UPDATE CURRENT_TIMESTAMP' ELSE '' END,
 				',' || E'\n'
 				ORDER BY ordinal_position
 			) || E'\n);' AS "Create Table"
-		FROM information_schema.columns
-		WHERE table_schema = 'public' AND table_name = '%s'
+		FROM information_schema.columns c
+		WHERE table_schema = 'public' AND table_name = $1
 		GROUP BY table_name
-	`, tableName, tableName)
+	`
 
-	rows, err := c.conn.Query(query)
+	rows, err := c.conn.Query(query, tableName)
 	if err != nil {
 		return err
 	}
@@ -307,6 +555,34 @@ func (c *Client) showCreateTable(tableName string) error {
 	return c.printResults(rows)
 }
 
+// createOrDropDatabase runs CREATE DATABASE IF NOT EXISTS and DROP DATABASE
+// IF EXISTS, checking pg_database first. As in MySQL, skipping the
+// statement is reported as a warning rather than an error.
+func (c *Client) createOrDropDatabase(result *translator.TranslationResult) error {
+	dbName := result.Args[0]
+	exists, err := c.conn.DatabaseExists(dbName)
+	if err != nil {
+		return err
+	}
+	switch create := result.SpecialType == "create_database_if_not_exists"; {
+	case create && exists:
+		fmt.Println("Query OK")
+		c.printWarnings([]string{fmt.Sprintf("Can't create database '%s'; database exists", dbName)})
+		return nil
+	case !create && !exists:
+		fmt.Println("Query OK")
+		c.printWarnings([]string{fmt.Sprintf("Can't drop database '%s'; database doesn't exist", dbName)})
+		return nil
+	}
+
+	if _, err := c.conn.Exec(result.Query); err != nil {
+		return err
+	}
+	fmt.Println("Query OK")
+	c.printWarnings(result.Warnings)
+	return nil
+}
+
 func (c *Client) showCreateDatabase(dbName string) error {
 	if c.conn.Config.DBType == db.MySQL {
 		rows, err := c.conn.Query("SHOW CREATE DATABASE " + dbName)
@@ -317,11 +593,13 @@ func (c *Client) showCreateDatabase(dbName string) error {
 		return c.printResults(rows)
 	}
 
-	// PostgreSQL: Generate CREATE DATABASE statement
-	query := fmt.Sprintf(`
-		SELECT 
-			'CREATE DATABASE ' || datname || 
-			' WITH OWNER = ' || pg_catalog.pg_get_userbyid(datdba) ||
+	// PostgreSQL: Generate CREATE DATABASE statement. The ICU locale column
+	// is daticulocale before PostgreSQL 17 and datlocale after, so it is
+	// read through to_jsonb.
+	query := `
+		SELECT
+			'CREATE DATABASE ' || quote_ident(datname) ||
+			' WITH OWNER = ' || quote_ident(pg_catalog.pg_get_userbyid(datdba)) ||
 			' ENCODING = ''' || pg_encoding_to_char(encoding) || '''' ||
 			CASE 
 				WHEN datcollate IS NOT NULL THEN ' LC_COLLATE = ''' || datcollate || ''''
@@ -331,12 +609,17 @@ func (c *Client) showCreateDatabase(dbName string) error {
 				WHEN datctype IS NOT NULL THEN ' LC_CTYPE = ''' || datctype || ''''
 				ELSE ''
 			END ||
+			CASE
+				WHEN to_jsonb(d)->>'datlocprovider' = 'i' THEN ' LOCALE_PROVIDER = ''icu'' ICU_LOCALE = ''' ||
+					COALESCE(to_jsonb(d)->>'datlocale', to_jsonb(d)->>'daticulocale') || ''''
+				ELSE ''
+			END ||
 			';' AS "Create Database"
-		FROM pg_database 
-		WHERE datname = '%s'
-	`, dbName)
+		FROM pg_database d
+		WHERE datname = $1
+	`
 
-	rows, err := c.conn.Query(query)
+	rows, err := c.conn.Query(query, dbName)
 	if err != nil {
 		return err
 	}
@@ -347,11 +630,55 @@ func (c *Client) showCreateDatabase(dbName string) error {
 	return c.printResults(rows)
 }
 
+// showGrants prints the GRANT statements of a role under MySQL's
+// "Grants for user@host" heading. PostgreSQL roles have no host, so it is always %.
+func (c *Client) showGrants(result *translator.TranslationResult) error {
+	user := result.Args[0]
+	if user == "" {
+		var err error
+		if user, err = c.conn.CurrentUser(); err != nil {
+			return err
+		}
+	}
+
+	rows, err := c.conn.Query(result.Query, user)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var data [][]string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return err
+		}
+		data = append(data, []string{grant})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("there is no such grant defined for user '%s' on host '%%'", user)
+	}
+	return c.printData([]string{fmt.Sprintf("Grants for %s@%%", user)}, data)
+}
+
 func (c *Client) printResults(rows *sql.Rows) error {
+	return c.printResultsAssigning(rows, nil)
+}
+
+// printResultsAssigning prints a result and sets user variables from the
+// columns of its last row, as SELECT @var := expr does in MySQL
+func (c *Client) printResultsAssigning(rows *sql.Rows, vars map[string]string) error {
 	columns, err := rows.Columns()
 	if err != nil {
 		return err
 	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
 
 	if len(columns) == 0 {
 		fmt.Println("Empty set")
@@ -391,6 +718,14 @@ func (c *Client) printResults(rows *sql.Rows) error {
 		return err
 	}
 
+	if len(vars) > 0 && len(data) > 0 {
+		c.assignColumns(types, values, func(i int) string { return vars[columns[i]] })
+	}
+	return c.printData(columns, data)
+}
+
+// printData prints rows already read into strings
+func (c *Client) printData(columns []string, data [][]string) error {
 	if len(data) == 0 {
 		fmt.Println("Empty set")
 		return nil
@@ -443,6 +778,7 @@ General Commands:
   help, \?          Show this help message
   quit, exit, \q    Exit the client
   \x                Toggle expanded output mode
+  DELIMITER //      End statements with // instead of ;
 
 MySQL-style Commands (work on both MySQL and PostgreSQL):
   SHOW DATABASES;                   List all databases
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+	"gomypg/internal/translator"
+)
+
+// recorded lists the statements the recording driver was sent
+var recorded []string
+
+// recordingDriver accepts every statement, recording it, and returns no rows
+type recordingDriver struct{}
+
+type recordingConn struct{}
+
+type recordingTx struct{}
+
+type noRows struct{}
+
+func init() {
+	sql.Register("mygo-recording", recordingDriver{})
+}
+
+func (recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{}, nil }
+
+func (recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+func (recordingConn) Close() error { return nil }
+func (recordingConn) Begin() (driver.Tx, error) {
+	recorded = append(recorded, "BEGIN")
+	return recordingTx{}, nil
+}
+func (recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	recorded = append(recorded, query)
+	return driver.RowsAffected(0), nil
+}
+func (recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	recorded = append(recorded, query)
+	return noRows{}, nil
+}
+
+func (recordingTx) Commit() error {
+	recorded = append(recorded, "COMMIT")
+	return nil
+}
+func (recordingTx) Rollback() error {
+	recorded = append(recorded, "ROLLBACK")
+	return nil
+}
+
+func (noRows) Columns() []string         { return nil }
+func (noRows) Close() error              { return nil }
+func (noRows) Next([]driver.Value) error { return io.EOF }
+
+// newRecordingClient returns a PostgreSQL client whose statements are recorded
+func newRecordingClient(t *testing.T) *Client {
+	recorded = nil
+	sqlDB, err := sql.Open("mygo-recording", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return &Client{
+		conn:       &db.Connection{DB: sqlDB, Config: &db.Config{DBType: db.PostgreSQL}},
+		translator: translator.New(db.PostgreSQL),
+		config:     &Config{},
+		delimiter:  ";",
+		vars:       map[string]interface{}{},
+		prepared:   map[string]string{},
+	}
+}
+
+func TestLockTablesCommitsStatements(t *testing.T) {
+	c := newRecordingClient(t)
+	for _, query := range []string{
+		"LOCK TABLES users WRITE",
+		"INSERT INTO users (name) VALUES ('a')",
+	} {
+		if err := c.executeQuery(query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	if !c.tablesLocked {
+		t.Errorf("expected the tables to stay locked")
+	}
+	c.Close()
+
+	insert := -1
+	for i, stmt := range recorded {
+		if strings.HasPrefix(stmt, "INSERT") {
+			insert = i
+		}
+	}
+	if insert < 0 {
+		t.Fatalf("INSERT was not run: %q", recorded)
+	}
+	after := strings.Join(recorded[insert+1:], "; ")
+	if !strings.Contains(after, "COMMIT") || strings.Contains(after, "ROLLBACK") {
+		t.Errorf("INSERT under LOCK TABLES was not committed: %q", recorded)
+	}
+	// The locks are taken again after the commit
+	if !strings.Contains(after, "LOCK TABLE users IN ACCESS EXCLUSIVE MODE") {
+		t.Errorf("table locks were not taken again: %q", recorded)
+	}
+}
+
+func TestAutocommitOffCommitsBeforeCreateDatabase(t *testing.T) {
+	c := newRecordingClient(t)
+	for _, query := range []string{
+		"SET autocommit = 0",
+		"INSERT INTO users (name) VALUES ('a')",
+		"CREATE DATABASE shop",
+	} {
+		if err := c.executeQuery(query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	if c.conn.InTransaction() {
+		t.Errorf("CREATE DATABASE was left in a transaction")
+	}
+
+	create := -1
+	for i, stmt := range recorded {
+		if strings.HasPrefix(stmt, "CREATE DATABASE") {
+			create = i
+		}
+	}
+	if create < 1 || recorded[create-1] != "COMMIT" {
+		t.Errorf("the open transaction was not committed before CREATE DATABASE: %q", recorded)
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"fmt"
+
+	"gomypg/internal/translator"
+)
+
+// prepare checks that the statement of PREPARE translates and keeps it for
+// EXECUTE, which translates it again so that it sees the session's state then
+func (c *Client) prepare(result *translator.TranslationResult) error {
+	name := result.Args[0]
+	var query string
+	if len(result.Args) > 1 {
+		query = result.Args[1]
+	} else {
+		value := c.vars[result.Params[0]]
+		if value == nil {
+			return fmt.Errorf("syntax error: the statement to prepare is NULL")
+		}
+		query = fmt.Sprint(value)
+	}
+
+	// As in MySQL, a statement of the same name is deallocated even if
+	// preparing this one fails
+	delete(c.prepared, name)
+	stmt, err := c.translator.Translate(query)
+	if err != nil {
+		return err
+	}
+	switch stmt.SpecialType {
+	case "prepare", "execute", "deallocate":
+		return fmt.Errorf("This command is not supported in the prepared statement protocol yet")
+	}
+	c.prepared[name] = query
+	fmt.Println("Statement prepared")
+	return nil
+}
+
+// executePrepared runs a prepared statement with the values of the USING
+// variables bound to its placeholders
+func (c *Client) executePrepared(result *translator.TranslationResult) error {
+	name := result.Args[0]
+	query, ok := c.prepared[name]
+	if !ok {
+		if len(result.Params) == 0 {
+			// A statement prepared with PostgreSQL's PREPARE
+			return c.execute(&translator.TranslationResult{Query: result.Query})
+		}
+		return fmt.Errorf("Unknown prepared statement handler (%s) given to EXECUTE", name)
+	}
+
+	stmt, err := c.translator.Translate(query)
+	if err != nil {
+		return err
+	}
+	if len(result.Params) != stmt.Placeholders {
+		return fmt.Errorf("Incorrect arguments to EXECUTE")
+	}
+	stmt.Params = append(append([]string{}, result.Params...), stmt.Params...)
+	return c.execute(stmt)
+}
+
+// deallocate forgets a prepared statement
+func (c *Client) deallocate(result *translator.TranslationResult) error {
+	name := result.Args[0]
+	if _, ok := c.prepared[name]; !ok {
+		// A statement prepared with PostgreSQL's PREPARE
+		if _, err := c.conn.Exec(result.Query); err != nil {
+			return err
+		}
+	}
+	delete(c.prepared, name)
+	fmt.Println("Query OK")
+	return nil
+}
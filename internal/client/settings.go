@@ -0,0 +1,34 @@
+package client
+
+import (
+	"fmt"
+
+	"gomypg/internal/translator"
+)
+
+// set applies session settings, which the connection keeps when USE
+// reconnects. Args holds "1" or "0" when sql_mode turns || concatenation
+// on or off, which only the translator carries out.
+func (c *Client) set(result *translator.TranslationResult) error {
+	if len(result.Statements) > 0 {
+		if err := c.conn.ExecBatch(result.Statements); err != nil {
+			return err
+		}
+	}
+	if len(result.Args) > 0 {
+		c.translator.SetPipesAsConcat(result.Args[0] == "1")
+	}
+	fmt.Println("Query OK")
+	c.printWarnings(result.Warnings)
+	return nil
+}
+
+// showWarnings lists the warnings reported for the last statement
+func (c *Client) showWarnings() error {
+	data := make([][]string, len(c.warnings))
+	for i, warning := range c.warnings {
+		// ER_UNKNOWN_ERROR, as the warnings have no MySQL code of their own
+		data[i] = []string{"Warning", "1105", warning}
+	}
+	return c.printData([]string{"Level", "Code", "Message"}, data)
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gomypg/internal/translator"
+)
+
+// transaction carries out a transaction control statement. The client tracks
+// the transaction it opened so that SET autocommit = 0, LOCK TABLES and XA
+// can work the way they do in MySQL.
+func (c *Client) transaction(result *translator.TranslationResult) error {
+	switch action := result.Args[0]; action {
+	case "begin":
+		// As in MySQL, starting a transaction commits the open one
+		if err := c.endTransaction("COMMIT"); err != nil {
+			return err
+		}
+		if err := c.beginTransaction(result.Args[1]); err != nil {
+			return err
+		}
+		for _, stmt := range result.Statements {
+			if _, err := c.conn.Exec(stmt); err != nil {
+				return err
+			}
+		}
+
+	case "commit", "rollback":
+		chain := len(result.Args) > 1 && result.Args[1] == "chain"
+		switch {
+		case chain && c.conn.InTransaction():
+			if err := c.checkNoXA(); err != nil {
+				return err
+			}
+			c.releaseTableLocks()
+			if _, err := c.conn.Exec(result.Query); err != nil {
+				return err
+			}
+		case !chain:
+			if err := c.endTransaction(result.Query); err != nil {
+				return err
+			}
+		}
+		if len(result.Args) > 1 && result.Args[1] == "release" {
+			fmt.Println("Bye!")
+			c.Close()
+			os.Exit(0)
+		}
+
+	case "savepoint":
+		if !c.conn.InTransaction() && c.implicitTransactions {
+			if err := c.beginTransaction(""); err != nil {
+				return err
+			}
+		}
+		if _, err := c.conn.Exec(result.Query); err != nil {
+			return err
+		}
+
+	case "next":
+		if c.conn.InTransaction() {
+			return fmt.Errorf("transaction characteristics can't be changed while a transaction is in progress")
+		}
+		c.nextTransaction = result.Args[1]
+
+	case "autocommit":
+		// Turning autocommit back on commits the open transaction
+		on := result.Args[1] == "1"
+		if on {
+			if err := c.endTransaction("COMMIT"); err != nil {
+				return err
+			}
+		}
+		c.implicitTransactions = !on
+
+	default:
+		if strings.HasPrefix(action, "xa_") {
+			return c.xa(strings.TrimPrefix(action, "xa_"), result)
+		}
+		return fmt.Errorf("unknown transaction command: %s", action)
+	}
+	fmt.Println("Query OK")
+	return nil
+}
+
+// xa carries out XA START, END, PREPARE, COMMIT and ROLLBACK
+func (c *Client) xa(action string, result *translator.TranslationResult) error {
+	xid := result.Args[1]
+	active := c.xid != "" && c.conn.InTransaction()
+	switch action {
+	case "start":
+		if c.conn.InTransaction() {
+			return fmt.Errorf("XAER_OUTSIDE: Some work is done outside global transaction")
+		}
+		if err := c.beginTransaction(""); err != nil {
+			return err
+		}
+		c.xid = xid
+
+	case "end":
+		if !active || c.xid != xid {
+			return fmt.Errorf("XAER_NOTA: Unknown XID")
+		}
+
+	case "prepare":
+		if !active || c.xid != xid {
+			return fmt.Errorf("XAER_NOTA: Unknown XID")
+		}
+		c.xid = ""
+		c.releaseTableLocks()
+		if err := c.conn.EndTransaction(result.Query); err != nil {
+			return err
+		}
+
+	case "commit", "rollback":
+		// An XA transaction that was never prepared ends like any other;
+		// a prepared one is finished by identifier, from any session
+		if active && c.xid == xid {
+			if action == "commit" && !(len(result.Args) > 2 && result.Args[2] == "one_phase") {
+				return fmt.Errorf("XAER_RMFAIL: The command cannot be executed when global transaction is in the ACTIVE state")
+			}
+			c.xid = ""
+			if err := c.endTransaction(strings.ToUpper(action)); err != nil {
+				return err
+			}
+			break
+		}
+		if c.conn.InTransaction() {
+			return fmt.Errorf("XAER_RMFAIL: The command cannot be executed when global transaction is in the ACTIVE state")
+		}
+		if _, err := c.conn.Exec(result.Query); err != nil {
+			return err
+		}
+	}
+	fmt.Println("Query OK")
+	return nil
+}
+
+// beginTransaction opens a transaction with the given modes, after those set
+// for it with SET TRANSACTION
+func (c *Client) beginTransaction(modes string) error {
+	var all []string
+	for _, m := range []string{c.nextTransaction, modes} {
+		if m != "" {
+			all = append(all, m)
+		}
+	}
+	c.nextTransaction = ""
+	return c.conn.Begin(strings.TrimSpace("START TRANSACTION " + strings.Join(all, ", ")))
+}
+
+// endTransaction commits or rolls back the open transaction, if there is one
+func (c *Client) endTransaction(query string) error {
+	if !c.conn.InTransaction() {
+		return nil
+	}
+	if err := c.checkNoXA(); err != nil {
+		return err
+	}
+	c.releaseTableLocks()
+	return c.conn.EndTransaction(query)
+}
+
+// checkNoXA fails while an XA transaction is open, which only XA statements may end
+func (c *Client) checkNoXA() error {
+	if c.xid != "" {
+		return fmt.Errorf("XAER_RMFAIL: The command cannot be executed when global transaction is in the ACTIVE state")
+	}
+	return nil
+}
+
+// releaseTableLocks notes that the transaction holding the locks of LOCK
+// TABLES is ending. MySQL keeps them until UNLOCK TABLES, PostgreSQL cannot.
+func (c *Client) releaseTableLocks() {
+	if c.tablesLocked {
+		c.tablesLocked = false
+		c.printWarnings([]string{"Table locks were released with the transaction"})
+	}
+}
+
+// runInTransaction runs a statement in the open transaction, first opening
+// one when autocommit is off. Each statement gets a savepoint, so that a
+// failure only undoes that statement, as in MySQL.
+func (c *Client) runInTransaction(result *translator.TranslationResult) error {
+	if !c.conn.InTransaction() {
+		if err := c.beginTransaction(""); err != nil {
+			return err
+		}
+	}
+	return c.conn.WithSavepoint(func() error {
+		return c.run(result)
+	})
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gomypg/internal/translator"
+)
+
+// bindVariables returns the values of user variables to bind as query
+// parameters. A variable that was never set is NULL, as in MySQL.
+func (c *Client) bindVariables(names []string) []interface{} {
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		args[i] = c.vars[name]
+	}
+	return args
+}
+
+// setVariables runs a query returning at most one row and assigns its
+// columns to the result's user variables, as for SET @var, SELECT ... INTO
+// @var or the OUT parameters of CALL
+func (c *Client) setVariables(result *translator.TranslationResult, args []interface{}) error {
+	rows, err := c.conn.Query(result.Query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	if len(types) != len(result.OutVars) {
+		return fmt.Errorf("The used SELECT statements have a different number of columns")
+	}
+	values := make([]interface{}, len(types))
+	valuePtrs := make([]interface{}, len(types))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	count := 0
+	for rows.Next() {
+		if count++; count > 1 {
+			return fmt.Errorf("Result consisted of more than one row")
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	fmt.Println("Query OK")
+	if count == 0 {
+		// As in MySQL, the variables keep their values
+		c.printWarnings([]string{"No data - zero rows fetched, selected, or processed"})
+	} else {
+		c.assignColumns(types, values, func(i int) string { return result.OutVars[i] })
+	}
+	c.printWarnings(result.Warnings)
+	return nil
+}
+
+// assignColumns sets the user variable of each column of a row, which
+// variable returns, or "" for none. The translator learns the variable's type
+// to cast its parameter to.
+func (c *Client) assignColumns(types []*sql.ColumnType, values []interface{}, variable func(i int) string) {
+	for i, value := range values {
+		name := variable(i)
+		if name == "" {
+			continue
+		}
+		pgType := variableType(types[i].DatabaseTypeName())
+		// Keep text rather than the driver's bytes, which it would send
+		// back in binary format
+		if b, ok := value.([]byte); ok && pgType != "bytea" {
+			value = string(b)
+		}
+		c.vars[name] = value
+		c.translator.SetVariableType(name, pgType)
+	}
+}
+
+// variableType returns the PostgreSQL type to cast a variable's value to,
+// given the driver's name of the type of the column it came from. Strings
+// and values of types the driver does not know stay untyped.
+func variableType(name string) string {
+	switch name {
+	case "", "UNKNOWN", "TEXT", "VARCHAR", "BPCHAR", "CHAR", "NAME":
+		return ""
+	}
+	if strings.HasPrefix(name, "_") {
+		return strings.ToLower(name[1:]) + "[]"
+	}
+	return strings.ToLower(name)
+}
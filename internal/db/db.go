@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
@@ -31,6 +32,8 @@ type Config struct {
 type Connection struct {
 	DB     *sql.DB
 	Config *Config
+
+	inTransaction bool // A transaction opened with Begin is in progress
 }
 
 // New creates a new database connection
@@ -91,6 +94,9 @@ func New(cfg *Config) (*Connection, error) {
 		db.Close()
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	// Keep the session on one server connection: locks and open
+	// transactions belong to the connection that took them
+	db.SetMaxOpenConns(1)
 
 	return &Connection{
 		DB:     db,
@@ -98,8 +104,15 @@ func New(cfg *Config) (*Connection, error) {
 	}, nil
 }
 
-// Close closes the database connection
+// Close ends the session. As when a MySQL client disconnects, an open
+// transaction is rolled back and named locks are released.
 func (c *Connection) Close() error {
+	if c.inTransaction {
+		c.Rollback()
+	}
+	if c.Config.DBType == PostgreSQL {
+		c.DB.Exec("SELECT pg_advisory_unlock_all()")
+	}
 	return c.DB.Close()
 }
 
@@ -113,6 +126,89 @@ func (c *Connection) Exec(query string, args ...interface{}) (sql.Result, error)
 	return c.DB.Exec(query, args...)
 }
 
+// ExecBatch runs statements in order inside one transaction, rolling back
+// all of them if one fails. Inside a transaction opened with Begin they run
+// under a savepoint instead, so a failure leaves the transaction usable.
+func (c *Connection) ExecBatch(statements []string) error {
+	if c.inTransaction {
+		return c.execSavepoint(statements)
+	}
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (c *Connection) execSavepoint(statements []string) error {
+	if _, err := c.DB.Exec("SAVEPOINT mygo_batch"); err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		if _, err := c.DB.Exec(stmt); err != nil {
+			c.DB.Exec("ROLLBACK TO SAVEPOINT mygo_batch")
+			return err
+		}
+	}
+	_, err := c.DB.Exec("RELEASE SAVEPOINT mygo_batch")
+	return err
+}
+
+// Begin runs a statement opening a transaction, such as BEGIN, that spans
+// the following statements until Commit, Rollback or EndTransaction
+func (c *Connection) Begin(query string) error {
+	if _, err := c.DB.Exec(query); err != nil {
+		return err
+	}
+	c.inTransaction = true
+	return nil
+}
+
+// Commit commits the transaction opened with Begin
+func (c *Connection) Commit() error {
+	return c.EndTransaction("COMMIT")
+}
+
+// Rollback rolls back the transaction opened with Begin
+func (c *Connection) Rollback() error {
+	return c.EndTransaction("ROLLBACK")
+}
+
+// EndTransaction runs a statement that ends the transaction opened with
+// Begin, such as PREPARE TRANSACTION. The transaction is over even if the
+// statement fails, as PostgreSQL rolls it back then.
+func (c *Connection) EndTransaction(query string) error {
+	c.inTransaction = false
+	_, err := c.DB.Exec(query)
+	return err
+}
+
+// WithSavepoint runs fn inside the open transaction under a savepoint and
+// rolls back to it if fn fails. This keeps the transaction usable after a
+// failed statement, as in MySQL, where PostgreSQL would abort all of it.
+func (c *Connection) WithSavepoint(fn func() error) error {
+	if _, err := c.DB.Exec("SAVEPOINT mygo_statement"); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		c.DB.Exec("ROLLBACK TO SAVEPOINT mygo_statement")
+		return err
+	}
+	_, err := c.DB.Exec("RELEASE SAVEPOINT mygo_statement")
+	return err
+}
+
+// InTransaction reports whether a transaction opened with Begin is in progress
+func (c *Connection) InTransaction() bool {
+	return c.inTransaction
+}
+
 // GetCurrentDatabase returns the current database name
 func (c *Connection) GetCurrentDatabase() string {
 	return c.Config.Database
@@ -120,6 +216,29 @@ func (c *Connection) GetCurrentDatabase() string {
 
 // SetDatabase changes the current database
 func (c *Connection) SetDatabase(dbName string) error {
+	// Reconnecting would silently drop the transaction and its locks
+	if c.inTransaction {
+		return fmt.Errorf("cannot change database inside a transaction, COMMIT or UNLOCK TABLES first")
+	}
+	// or the named locks of GET_LOCK(), which outlive transactions
+	if c.Config.DBType == PostgreSQL {
+		var locks int
+		if err := c.DB.QueryRow("SELECT count(*) FROM pg_locks WHERE locktype = 'advisory' AND granted AND pid = pg_backend_pid()").Scan(&locks); err != nil {
+			return err
+		}
+		if locks > 0 {
+			return fmt.Errorf("cannot change database while holding named locks from GET_LOCK(), RELEASE_LOCK() them first")
+		}
+	}
+	// MySQL keeps session variables across USE, so the new connection takes
+	// over the settings changed with SET
+	var settings map[string]string
+	if c.Config.DBType == PostgreSQL {
+		var err error
+		if settings, err = c.sessionSettings(); err != nil {
+			return err
+		}
+	}
 	c.Config.Database = dbName
 	
 	// Reconnect with new database
@@ -131,5 +250,182 @@ func (c *Connection) SetDatabase(dbName string) error {
 	}
 	
 	c.DB = newConn.DB
+	for name, value := range settings {
+		if _, err := c.DB.Exec("SELECT set_config($1, $2, false)", name, value); err != nil {
+			return fmt.Errorf("database changed, but setting %s could not be kept: %w", name, err)
+		}
+	}
 	return nil
 }
+
+// sessionSettings returns the settings the session changed, by name. Outside
+// a transaction those are the ones that last: a rolled back SET and SET
+// LOCAL have ended with their transactions.
+func (c *Connection) sessionSettings() (map[string]string, error) {
+	rows, err := c.DB.Query("SELECT name, setting FROM pg_settings WHERE source = 'session'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	settings := map[string]string{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		settings[name] = value
+	}
+	return settings, rows.Err()
+}
+
+// DatabaseExists reports whether a PostgreSQL database with the given catalog name exists
+func (c *Connection) DatabaseExists(name string) (bool, error) {
+	var exists bool
+	err := c.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", name).Scan(&exists)
+	return exists, err
+}
+
+// CurrentUser returns the name of the role the connection is logged in as
+func (c *Connection) CurrentUser() (string, error) {
+	var user string
+	err := c.DB.QueryRow("SELECT current_user").Scan(&user)
+	return user, err
+}
+
+// UniqueKeys returns the column lists of a PostgreSQL table's primary key and
+// unique indexes, primary key first. Partial and expression indexes are skipped
+// because they cannot serve as a plain conflict target.
+func (c *Connection) UniqueKeys(table string) ([][]string, error) {
+	rows, err := c.Query(`
+		SELECT i.indexrelid, a.attname
+		FROM pg_index i
+		JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+		WHERE i.indrelid = to_regclass($1)
+			AND i.indisunique
+			AND i.indpred IS NULL
+			AND i.indexprs IS NULL
+		ORDER BY i.indisprimary DESC, i.indexrelid, k.ord`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys [][]string
+	var lastIndex int64 = -1
+	for rows.Next() {
+		var index int64
+		var column string
+		if err := rows.Scan(&index, &column); err != nil {
+			return nil, err
+		}
+		if index != lastIndex {
+			keys = append(keys, nil)
+			lastIndex = index
+		}
+		keys[len(keys)-1] = append(keys[len(keys)-1], column)
+	}
+	return keys, rows.Err()
+}
+
+// Columns returns the column names of a PostgreSQL table in ordinal order
+func (c *Connection) Columns(table string) ([]string, error) {
+	rows, err := c.Query(`
+		SELECT attname
+		FROM pg_attribute
+		WHERE attrelid = to_regclass($1) AND attnum > 0 AND NOT attisdropped
+		ORDER BY attnum`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+	return columns, nil
+}
+
+// AutoIncrementColumn returns the column of a PostgreSQL table whose default
+// is taken from a sequence, as with serial and identity columns, or "" if the
+// table has none
+func (c *Connection) AutoIncrementColumn(table string) (string, error) {
+	var column string
+	err := c.DB.QueryRow(`
+		SELECT a.attname
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = to_regclass($1) AND a.attnum > 0 AND NOT a.attisdropped
+			AND (a.attidentity <> '' OR pg_get_expr(d.adbin, d.adrelid) LIKE 'nextval(%')
+		ORDER BY a.attnum
+		LIMIT 1`, table).Scan(&column)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return column, err
+}
+
+// Index returns the name of a PostgreSQL table's index called name, or "" if
+// the table has none, and whether it belongs to a primary key, unique or
+// exclusion constraint of the table
+func (c *Connection) Index(table, name string) (string, bool, error) {
+	var index string
+	var constraint bool
+	err := c.DB.QueryRow(`
+		SELECT r.relname, EXISTS (SELECT 1 FROM pg_constraint WHERE conindid = i.indexrelid AND conrelid = i.indrelid)
+		FROM pg_index i
+		JOIN pg_class r ON r.oid = i.indexrelid
+		WHERE i.indrelid = to_regclass($1) AND r.relname = $2`, table, name).Scan(&index, &constraint)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	return index, constraint, err
+}
+
+// HasSetting reports whether PostgreSQL has a configuration parameter of the name
+func (c *Connection) HasSetting(name string) (bool, error) {
+	var exists bool
+	err := c.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_settings WHERE lower(name) = lower($1))", name).Scan(&exists)
+	return exists, err
+}
+
+// procedureModes maps pg_proc.proargmodes to the modes of MySQL parameters
+var procedureModes = map[string]string{"i": "IN", "o": "OUT", "b": "INOUT", "v": "IN"}
+
+// ProcedureModes returns the modes, IN, OUT or INOUT, of the parameters of
+// the PostgreSQL procedure that takes args arguments, or nil if there is none
+func (c *Connection) ProcedureModes(procedure string, args int) ([]string, error) {
+	var list string
+	err := c.DB.QueryRow(`
+		SELECT coalesce(p.proargmodes::text[], array_fill('i'::text, ARRAY[p.pronargs]::int[]))::text
+		FROM pg_proc p, parse_ident($1) AS n(parts)
+		WHERE p.prokind = 'p' AND p.proname = n.parts[array_length(n.parts, 1)]
+			AND CASE WHEN array_length(n.parts, 1) > 1 THEN p.pronamespace = to_regnamespace(n.parts[1])
+				ELSE pg_function_is_visible(p.oid) END
+			AND coalesce(array_length(p.proargmodes, 1), p.pronargs) = $2
+		LIMIT 1`, procedure, args).Scan(&list)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	modes := []string{}
+	for _, mode := range strings.Split(strings.Trim(list, "{}"), ",") {
+		if mode != "" {
+			modes = append(modes, procedureModes[mode])
+		}
+	}
+	return modes, nil
+}
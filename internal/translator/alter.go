@@ -0,0 +1,550 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlterTableStmt is MySQL's ALTER TABLE with its comma-separated alter
+// specifications kept as tokens
+type AlterTableStmt struct {
+	Table []Token
+	Specs [][]Token
+}
+
+func (*AlterTableStmt) statementNode() {}
+
+// isAlterTable reports whether the statement starts with ALTER [ONLINE] [IGNORE] TABLE
+func (p *parser) isAlterTable() bool {
+	for n := 1; n < 4; n++ {
+		switch tok := p.peekN(n); {
+		case tok.Is("TABLE"):
+			return true
+		case !tok.Is("ONLINE") && !tok.Is("IGNORE"):
+			return false
+		}
+	}
+	return false
+}
+
+func (p *parser) parseAlterTable() (Statement, error) {
+	p.next() // ALTER
+	p.accept("ONLINE")
+	p.accept("IGNORE")
+	if err := p.expect("TABLE"); err != nil {
+		return nil, err
+	}
+	start := p.mark()
+	if _, err := p.qualifiedName(); err != nil {
+		return nil, err
+	}
+	stmt := &AlterTableStmt{Table: p.since(start)}
+	for _, spec := range splitTopLevel(p.rest(), ",") {
+		spec = trimTrivia(spec)
+		if len(spec) == 0 {
+			return nil, fmt.Errorf("syntax error: empty alter specification in ALTER TABLE")
+		}
+		if err := checkAlterSpec(spec); err != nil {
+			return nil, err
+		}
+		stmt.Specs = append(stmt.Specs, spec)
+	}
+	return stmt, nil
+}
+
+// tableOptions are the MySQL table options ALTER TABLE may change
+var tableOptions = map[string]bool{
+	"ENGINE": true, "AUTO_INCREMENT": true, "AVG_ROW_LENGTH": true, "CHARSET": true, "CHARACTER SET": true,
+	"CHECKSUM": true, "COLLATE": true, "COMMENT": true, "COMPRESSION": true, "CONNECTION": true,
+	"DATA DIRECTORY": true, "INDEX DIRECTORY": true, "DELAY_KEY_WRITE": true, "ENCRYPTION": true,
+	"INSERT_METHOD": true, "KEY_BLOCK_SIZE": true, "MAX_ROWS": true, "MIN_ROWS": true, "PACK_KEYS": true,
+	"ROW_FORMAT": true, "STATS_AUTO_RECALC": true, "STATS_PERSISTENT": true, "STATS_SAMPLE_PAGES": true,
+	"TABLESPACE": true, "UNION": true, "ALGORITHM": true, "LOCK": true, "AUTOEXTEND_SIZE": true,
+}
+
+// checkAlterSpec rejects an alter specification that is not MySQL's, so that
+// the statement is passed through as PostgreSQL's own ALTER TABLE, such as
+// DISABLE TRIGGER ALL or OWNER TO
+func checkAlterSpec(spec []Token) error {
+	p := newParser(spec)
+	switch first := p.peek(); {
+	case first.Is("RENAME") && p.peekN(1).Is("CONSTRAINT"):
+		return p.errorf("unexpected CONSTRAINT")
+	case first.Is("ADD"), first.Is("DROP"), first.Is("MODIFY"), first.Is("CHANGE"), first.Is("RENAME"),
+		partitionOps[first.Upper()], first.Is("FORCE"):
+		return nil
+	case first.Is("CONVERT") && p.peekN(1).Is("TO"), first.Is("ORDER") && p.peekN(1).Is("BY"),
+		(first.Is("WITH") || first.Is("WITHOUT")) && p.peekN(1).Is("VALIDATION"):
+		return nil
+	case first.Is("ENABLE"), first.Is("DISABLE"):
+		p.next()
+		if !p.accept("KEYS") {
+			return p.errorf("expected KEYS")
+		}
+		return p.expectEnd()
+	case p.accept("ALTER"):
+		// ALTER [COLUMN] col {SET DEFAULT | DROP DEFAULT | SET {VISIBLE | INVISIBLE}},
+		// ALTER INDEX and ALTER {CHECK | CONSTRAINT}
+		if p.peek().Is("INDEX") || p.peek().Is("CHECK") || p.peek().Is("CONSTRAINT") {
+			return nil
+		}
+		p.accept("COLUMN")
+		p.next()
+		if p.acceptSeq("SET", "DEFAULT") || p.acceptSeq("DROP", "DEFAULT") || p.acceptSeq("SET", "VISIBLE") || p.acceptSeq("SET", "INVISIBLE") {
+			return nil
+		}
+		return p.errorf("expected SET DEFAULT, DROP DEFAULT or SET VISIBLE")
+	}
+	var options []*TableOpt
+	if err := p.parseTableOptions(&options); err != nil {
+		return err
+	}
+	for _, opt := range options {
+		if !tableOptions[opt.Name] {
+			return fmt.Errorf("syntax error: unknown table option %s", opt.Name)
+		}
+	}
+	return p.expectEnd()
+}
+
+// alterTable collects the PostgreSQL statements one MySQL ALTER TABLE turns into
+type alterTable struct {
+	t          *Translator
+	table      []Token
+	name       string // Rendered table name
+	statements []string
+	last       []string // AUTO_INCREMENT and RENAME TO, which must see the finished table
+	warnings   []string
+}
+
+// translateAlterTable splits a MySQL ALTER TABLE into PostgreSQL statements
+// that the client runs in one transaction. What PostgreSQL cannot express,
+// such as column positions, is reported as a warning.
+func (t *Translator) translateAlterTable(s *AlterTableStmt) (*TranslationResult, error) {
+	a := &alterTable{t: t, table: s.Table, name: render(s.Table)}
+	for _, spec := range s.Specs {
+		if err := a.spec(spec); err != nil {
+			return nil, err
+		}
+	}
+	result := statementsResult(append(a.statements, a.last...))
+	result.Warnings = a.warnings
+	return result, nil
+}
+
+// alter adds an ALTER TABLE statement for one PostgreSQL action
+func (a *alterTable) alter(action string) {
+	a.statements = append(a.statements, "ALTER TABLE "+a.name+" "+action)
+}
+
+func (a *alterTable) warnf(format string, args ...interface{}) {
+	a.warnings = append(a.warnings, fmt.Sprintf(format, args...))
+}
+
+// partitionOps are alter specifications that manage partitions or tablespaces
+var partitionOps = map[string]bool{
+	"PARTITION": true, "COALESCE": true, "REORGANIZE": true, "EXCHANGE": true, "ANALYZE": true,
+	"OPTIMIZE": true, "REBUILD": true, "REPAIR": true, "REMOVE": true,
+	"TRUNCATE": true, "DISCARD": true, "IMPORT": true,
+}
+
+func (a *alterTable) spec(tokens []Token) error {
+	p := newParser(tokens)
+	switch first := p.peek(); {
+	case first.Is("ADD") && p.peekN(1).Is("PARTITION"),
+		first.Is("DROP") && p.peekN(1).Is("PARTITION"),
+		partitionOps[first.Upper()]:
+		return fmt.Errorf("ALTER TABLE ... %s is not supported on PostgreSQL", renderTrimmed(tokens))
+	case p.accept("ADD"):
+		return a.add(p)
+	case p.accept("DROP"):
+		return a.drop(p)
+	case p.accept("MODIFY"):
+		p.accept("COLUMN")
+		return a.modify(p.rest(), nil)
+	case p.accept("CHANGE"):
+		p.accept("COLUMN")
+		old := p.next()
+		if !old.IsIdent() {
+			return fmt.Errorf("syntax error: expected column name near '%s'", old.Text)
+		}
+		return a.modify(p.rest(), &old)
+	case p.accept("RENAME"):
+		return a.rename(p)
+	case p.accept("ALTER"):
+		return a.alterColumn(p)
+	case p.acceptSeq("CONVERT", "TO"):
+		a.warnf("CONVERT TO %s was ignored: PostgreSQL stores text in the database encoding", renderTrimmed(p.rest()))
+		return nil
+	case p.acceptSeq("ENABLE", "KEYS"), p.acceptSeq("DISABLE", "KEYS"):
+		a.warnf("%s was ignored: PostgreSQL always maintains indexes", renderTrimmed(tokens))
+		return nil
+	case p.acceptSeq("ORDER", "BY"):
+		a.warnf("ORDER BY was ignored: PostgreSQL does not keep rows in a set order, use CLUSTER instead")
+		return nil
+	case p.accept("FORCE"), p.acceptSeq("WITH", "VALIDATION"), p.acceptSeq("WITHOUT", "VALIDATION"):
+		return p.expectEnd()
+	}
+	return a.options(p)
+}
+
+// columnPosition splits a trailing FIRST or AFTER col off a column definition
+func columnPosition(tokens []Token) ([]Token, string) {
+	tokens = trimTrivia(tokens)
+	n := len(tokens)
+	if n > 0 && tokens[n-1].Is("FIRST") {
+		return trimTrivia(tokens[:n-1]), "FIRST"
+	}
+	if n > 1 {
+		if prev := prevSignificant(tokens, n-1); prev >= 0 && tokens[prev].Is("AFTER") && tokens[n-1].IsIdent() {
+			return trimTrivia(tokens[:prev]), "AFTER " + tokens[n-1].Text
+		}
+	}
+	return tokens, ""
+}
+
+// add handles ADD [COLUMN] col_def, ADD (col_def, ...), ADD {INDEX|KEY|UNIQUE|...}
+// and ADD [CONSTRAINT] {PRIMARY KEY|FOREIGN KEY|CHECK}
+func (a *alterTable) add(p *parser) error {
+	column := p.accept("COLUMN")
+	rest := trimTrivia(p.rest())
+	elements := [][]Token{rest}
+	if len(rest) > 0 && rest[0].IsOp("(") && matchParen(rest, 0) == len(rest)-1 {
+		elements = splitTopLevel(rest[1:len(rest)-1], ",")
+	}
+
+	for _, elem := range elements {
+		elem, position := columnPosition(elem)
+		def := &CreateTableStmt{}
+		if column {
+			col, err := newParser(elem).parseColumnDef()
+			if err != nil {
+				return err
+			}
+			def.Columns = append(def.Columns, col)
+		} else if err := def.addElement(elem); err != nil {
+			return err
+		}
+
+		for _, col := range def.Columns {
+			sql, extra, err := a.t.columnDefinition(a.name, col, "")
+			if err != nil {
+				return err
+			}
+			a.alter("ADD COLUMN " + sql)
+			a.statements = append(a.statements, extra...)
+			if position != "" {
+				a.warnf("column position %s is not supported on PostgreSQL, column %s was added as the last column", position, col.Name.Text)
+			}
+		}
+		a.statements = append(a.statements, onUpdateTriggers(a.table, def.Columns, false)...)
+		for _, constraint := range def.Constraints {
+			sql, err := a.t.tableConstraint(constraint)
+			if err != nil {
+				return err
+			}
+			a.alter("ADD " + sql)
+		}
+		for _, index := range def.Indexes {
+			if err := a.addIndex(index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addIndex adds a plain unique key as a constraint and any other index with CREATE INDEX
+func (a *alterTable) addIndex(index *IndexDef) error {
+	if index.Unique && index.isPlain() {
+		sql := "UNIQUE (" + strings.Join(index.columnNames(), ", ") + ")"
+		if name := index.constraintName(a.table); name != "" {
+			sql = "CONSTRAINT " + name + " " + sql
+		}
+		a.alter("ADD " + sql)
+		return nil
+	}
+	stmt, err := a.t.createIndex(a.table, index, false)
+	if err != nil {
+		return err
+	}
+	a.statements = append(a.statements, stmt)
+	return nil
+}
+
+// schemaPrefix returns the table's qualifier with its dot, for naming
+// indexes, which live in the table's schema
+func (a *alterTable) schemaPrefix() string {
+	if len(a.table) < 3 {
+		return ""
+	}
+	return render(a.table[:len(a.table)-1])
+}
+
+// defaultConstraintName builds the name PostgreSQL gives an unnamed
+// constraint of the table, such as t_pkey or t_col_check
+func (a *alterTable) defaultConstraintName(words ...string) string {
+	words = append([]string{a.table[len(a.table)-1].Value}, words...)
+	return pgIdent(strings.Join(words, "_"))
+}
+
+// drop handles DROP [COLUMN] col, DROP {INDEX|KEY} name, DROP PRIMARY KEY and
+// DROP {FOREIGN KEY|CHECK|CONSTRAINT} name
+func (a *alterTable) drop(p *parser) error {
+	switch {
+	case p.accept("INDEX", "KEY"):
+		ifExists := ""
+		if p.acceptSeq("IF", "EXISTS") {
+			ifExists = "IF EXISTS "
+		}
+		name := p.next()
+		if !name.IsIdent() {
+			return fmt.Errorf("syntax error: expected index name near '%s'", name.Text)
+		}
+		if strings.EqualFold(name.Value, "PRIMARY") {
+			a.alter("DROP CONSTRAINT " + ifExists + a.defaultConstraintName("pkey"))
+			break
+		}
+		index, constraint, err := a.t.existingIndex(a.table, name)
+		switch {
+		case err != nil:
+			return err
+		case index == "" && ifExists != "":
+			a.warnf("Can't DROP '%s'; check that column/key exists", name.Value)
+		case index == "":
+			return fmt.Errorf("Can't DROP '%s'; check that column/key exists", name.Value)
+		case constraint:
+			// The index of a unique key made a constraint cannot be dropped directly
+			a.alter("DROP CONSTRAINT " + ifExists + index)
+		default:
+			a.statements = append(a.statements, "DROP INDEX "+ifExists+a.schemaPrefix()+index)
+		}
+	case p.acceptSeq("PRIMARY", "KEY"):
+		a.alter("DROP CONSTRAINT " + a.defaultConstraintName("pkey"))
+	case p.acceptSeq("FOREIGN", "KEY"), p.accept("CHECK", "CONSTRAINT"):
+		name := p.next()
+		if !name.IsIdent() {
+			return fmt.Errorf("syntax error: expected constraint name near '%s'", name.Text)
+		}
+		a.alter("DROP CONSTRAINT " + name.Text)
+	default:
+		p.accept("COLUMN")
+		name := p.next()
+		if !name.IsIdent() {
+			return fmt.Errorf("syntax error: expected column name near '%s'", name.Text)
+		}
+		a.alter("DROP COLUMN " + name.Text)
+		a.statements = append(a.statements, dropOnUpdateTrigger(a.table, catalogName(name)))
+	}
+	return p.expectEnd()
+}
+
+// modify handles MODIFY col col_def and CHANGE old col_def. MySQL redefines
+// the whole column, so the type, default, identity and range check are all
+// set again. A column written neither NULL nor NOT NULL keeps its
+// nullability: PostgreSQL refuses to drop NOT NULL from a primary key
+// column, which MySQL keeps silently.
+func (a *alterTable) modify(tokens []Token, old *Token) error {
+	tokens, position := columnPosition(tokens)
+	col, err := newParser(tokens).parseColumnDef()
+	if err != nil {
+		return err
+	}
+	if col.OnUpdate != nil {
+		if err := checkOnUpdate(col); err != nil {
+			return err
+		}
+	}
+	if col.Generated != nil {
+		return fmt.Errorf("changing generated column %s is not supported on PostgreSQL, drop and add the column instead", col.Name.Text)
+	}
+	pgType, check, err := columnType(col)
+	if err != nil {
+		return err
+	}
+
+	name := col.Name.Text
+	oldCheck := a.defaultConstraintName(col.Name.Value, "check")
+	if old != nil && old.Value != col.Name.Value {
+		a.alter("RENAME COLUMN " + old.Text + " TO " + name)
+		oldCheck = a.defaultConstraintName(old.Value, "check")
+	}
+	column := "ALTER COLUMN " + name + " "
+	identity := col.AutoIncrement || col.Type == "SERIAL"
+
+	a.alter(column + "DROP IDENTITY IF EXISTS")
+	a.alter(column + "DROP DEFAULT")
+	a.alter(column + "TYPE " + pgType + " USING " + name + "::" + pgType)
+	switch {
+	case col.NotNull || identity || col.PrimaryKey:
+		a.alter(column + "SET NOT NULL")
+	case col.Null:
+		a.alter(column + "DROP NOT NULL")
+	}
+	switch {
+	case col.Default != nil:
+		value, err := a.t.rewriteTokens(col.Default)
+		if err != nil {
+			return err
+		}
+		a.alter(column + "SET DEFAULT " + render(value))
+	case col.Type == "ENUM" && col.NotNull:
+		if labels, _ := enumLabels(col.TypeArgs); len(labels) > 0 {
+			a.alter(column + "SET DEFAULT " + labels[0])
+		}
+	}
+
+	a.alter("DROP CONSTRAINT IF EXISTS " + oldCheck)
+	if check != "" {
+		a.alter("ADD CONSTRAINT " + a.defaultConstraintName(col.Name.Value, "check") + " CHECK (" + check + ")")
+	}
+	for _, c := range col.Checks {
+		expr, err := a.t.rewriteTokens(c)
+		if err != nil {
+			return err
+		}
+		a.alter("ADD " + strings.TrimSuffix(strings.TrimSpace(render(expr)), " ENFORCED"))
+	}
+	if col.PrimaryKey {
+		a.alter("ADD PRIMARY KEY (" + name + ")")
+	}
+	if col.Unique || col.Type == "SERIAL" {
+		a.alter("ADD UNIQUE (" + name + ")")
+	}
+	if col.References != nil {
+		a.alter("ADD FOREIGN KEY (" + name + ") " + render(col.References))
+	}
+	if identity {
+		// Continue numbering after the existing rows
+		a.alter(column + "ADD GENERATED BY DEFAULT AS IDENTITY")
+		a.statements = append(a.statements, fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), coalesce(max(%s), 0) + 1, false) FROM %s",
+			quoteLiteral(a.name), quoteLiteral(col.Name.Value), name, a.name))
+	}
+	// Without ON UPDATE the column stops following updates, as in MySQL
+	oldName := col.Name
+	if old != nil {
+		oldName = *old
+	}
+	a.statements = append(a.statements, dropOnUpdateTrigger(a.table, catalogName(oldName)))
+	a.statements = append(a.statements, onUpdateTriggers(a.table, []*ColumnDef{col}, false)...)
+	if col.Comment != nil {
+		a.statements = append(a.statements, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", a.name, name, col.Comment.Text))
+	}
+	if position != "" {
+		a.warnf("column position %s is not supported on PostgreSQL, column %s was left in place", position, name)
+	}
+	return nil
+}
+
+// rename handles RENAME COLUMN a TO b, RENAME {INDEX|KEY} a TO b and RENAME [TO|AS] new_name
+func (a *alterTable) rename(p *parser) error {
+	switch {
+	case p.accept("COLUMN"):
+		from, to, err := renamePair(p)
+		if err != nil {
+			return err
+		}
+		a.alter("RENAME COLUMN " + from.Text + " TO " + to.Text)
+		a.statements = append(a.statements, renameOnUpdateTrigger(a.table, catalogName(from), catalogName(to)))
+	case p.accept("INDEX", "KEY"):
+		from, to, err := renamePair(p)
+		if err != nil {
+			return err
+		}
+		index, _, err := a.t.existingIndex(a.table, from)
+		if err != nil {
+			return err
+		}
+		if index == "" {
+			return fmt.Errorf("Key '%s' doesn't exist in table '%s'", from.Value, a.table[len(a.table)-1].Value)
+		}
+		a.statements = append(a.statements, "ALTER INDEX "+a.schemaPrefix()+index+" RENAME TO "+indexName(a.table, catalogName(to)))
+	default:
+		p.accept("TO", "AS")
+		start := p.mark()
+		parts, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		name := p.since(start)
+		if len(parts) > 1 && a.schemaPrefix() != render(name[:len(name)-1]) {
+			return fmt.Errorf("moving a table to another database is not supported on PostgreSQL")
+		}
+		a.last = append(a.last, "ALTER TABLE "+a.name+" RENAME TO "+name[len(name)-1].Text)
+	}
+	return p.expectEnd()
+}
+
+// renamePair consumes "from TO to"
+func renamePair(p *parser) (Token, Token, error) {
+	from := p.next()
+	if err := p.expect("TO"); err != nil {
+		return Token{}, Token{}, err
+	}
+	to := p.next()
+	if !from.IsIdent() || !to.IsIdent() {
+		return Token{}, Token{}, fmt.Errorf("syntax error: RENAME needs an old and a new name")
+	}
+	return from, to, nil
+}
+
+// alterColumn handles ALTER [COLUMN] col {SET DEFAULT v|DROP DEFAULT|SET VISIBLE|SET INVISIBLE}
+// and ALTER INDEX name {VISIBLE|INVISIBLE}
+func (a *alterTable) alterColumn(p *parser) error {
+	if p.accept("INDEX") {
+		a.warnf("index visibility is not supported on PostgreSQL, %s was ignored", renderTrimmed(p.rest()))
+		return nil
+	}
+	if p.peek().Is("CHECK") || p.peek().Is("CONSTRAINT") {
+		return fmt.Errorf("changing whether a constraint is enforced is not supported on PostgreSQL")
+	}
+	p.accept("COLUMN")
+	name := p.next()
+	if !name.IsIdent() {
+		return fmt.Errorf("syntax error: expected column name near '%s'", name.Text)
+	}
+	column := "ALTER COLUMN " + name.Text + " "
+	switch {
+	case p.acceptSeq("SET", "DEFAULT"):
+		value, err := a.t.rewriteTokens(p.parseOperand())
+		if err != nil {
+			return err
+		}
+		a.alter(column + "SET DEFAULT " + render(value))
+	case p.acceptSeq("DROP", "DEFAULT"):
+		a.alter(column + "DROP DEFAULT")
+	case p.accept("SET") && p.accept("VISIBLE", "INVISIBLE"):
+		a.warnf("column visibility is not supported on PostgreSQL, the change to %s was ignored", name.Text)
+	default:
+		return p.errorf("unexpected input")
+	}
+	return p.expectEnd()
+}
+
+// options handles table options such as AUTO_INCREMENT = N or COMMENT = 'text'
+func (a *alterTable) options(p *parser) error {
+	var options []*TableOpt
+	if err := p.parseTableOptions(&options); err != nil {
+		return err
+	}
+	if err := p.expectEnd(); err != nil {
+		return err
+	}
+	for _, opt := range options {
+		switch opt.Name {
+		case "AUTO_INCREMENT":
+			// Whichever column is the table's identity
+			a.last = append(a.last, fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence(%[1]s, attname), %[2]s, false) FROM pg_attribute "+
+					"WHERE attrelid = %[1]s::regclass AND attnum > 0 AND NOT attisdropped AND pg_get_serial_sequence(%[1]s, attname) IS NOT NULL",
+				quoteLiteral(a.name), opt.Value.Text))
+		case "COMMENT":
+			a.statements = append(a.statements, fmt.Sprintf("COMMENT ON TABLE %s IS %s", a.name, opt.Value.Text))
+		case "ALGORITHM", "LOCK":
+			// Online DDL hints
+		default:
+			a.warnf("table option %s was ignored: it has no PostgreSQL counterpart", opt.Name)
+		}
+	}
+	return nil
+}
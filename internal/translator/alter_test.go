@@ -0,0 +1,219 @@
+package translator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateAlterTable(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{indexes: map[string]map[string]bool{
+		"t":     {"idx_old": false, "t_uk_old": true},
+		"app.t": {"t_i1": false},
+		"users": {"idx_users_email": false},
+	}})
+
+	tests := []struct {
+		input    string
+		want     []string
+		warnings []string
+	}{
+		{
+			"ALTER TABLE users ADD COLUMN age INT UNSIGNED NOT NULL DEFAULT 0 AFTER name",
+			[]string{"ALTER TABLE users ADD COLUMN age bigint NOT NULL DEFAULT 0 CHECK (age >= 0)"},
+			[]string{"column position AFTER name is not supported on PostgreSQL, column age was added as the last column"},
+		},
+		{
+			"ALTER TABLE users MODIFY COLUMN name VARCHAR(100) NOT NULL",
+			[]string{
+				"ALTER TABLE users ALTER COLUMN name DROP IDENTITY IF EXISTS",
+				"ALTER TABLE users ALTER COLUMN name DROP DEFAULT",
+				"ALTER TABLE users ALTER COLUMN name TYPE varchar(100) USING name::varchar(100)",
+				"ALTER TABLE users ALTER COLUMN name SET NOT NULL",
+				"ALTER TABLE users DROP CONSTRAINT IF EXISTS users_name_check",
+				"DROP TRIGGER IF EXISTS users_name_on_update ON users",
+			},
+			nil,
+		},
+		{
+			"ALTER TABLE `users` CHANGE `nick` `nickname` TEXT NULL DEFAULT NULL FIRST",
+			[]string{
+				`ALTER TABLE "users" RENAME COLUMN "nick" TO "nickname"`,
+				`ALTER TABLE "users" ALTER COLUMN "nickname" DROP IDENTITY IF EXISTS`,
+				`ALTER TABLE "users" ALTER COLUMN "nickname" DROP DEFAULT`,
+				`ALTER TABLE "users" ALTER COLUMN "nickname" TYPE text USING "nickname"::text`,
+				`ALTER TABLE "users" ALTER COLUMN "nickname" DROP NOT NULL`,
+				`ALTER TABLE "users" ALTER COLUMN "nickname" SET DEFAULT NULL`,
+				`ALTER TABLE "users" DROP CONSTRAINT IF EXISTS users_nick_check`,
+				`DROP TRIGGER IF EXISTS users_nick_on_update ON "users"`,
+			},
+			[]string{`column position FIRST is not supported on PostgreSQL, column "nickname" was left in place`},
+		},
+		{
+			"ALTER TABLE t MODIFY id INT UNSIGNED NOT NULL AUTO_INCREMENT, AUTO_INCREMENT=42",
+			[]string{
+				"ALTER TABLE t ALTER COLUMN id DROP IDENTITY IF EXISTS",
+				"ALTER TABLE t ALTER COLUMN id DROP DEFAULT",
+				"ALTER TABLE t ALTER COLUMN id TYPE bigint USING id::bigint",
+				"ALTER TABLE t ALTER COLUMN id SET NOT NULL",
+				"ALTER TABLE t DROP CONSTRAINT IF EXISTS t_id_check",
+				"ALTER TABLE t ADD CONSTRAINT t_id_check CHECK (id >= 0)",
+				"ALTER TABLE t ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY",
+				"SELECT setval(pg_get_serial_sequence('t', 'id'), coalesce(max(id), 0) + 1, false) FROM t",
+				"DROP TRIGGER IF EXISTS t_id_on_update ON t",
+				"SELECT setval(pg_get_serial_sequence('t', attname), 42, false) FROM pg_attribute " +
+					"WHERE attrelid = 't'::regclass AND attnum > 0 AND NOT attisdropped AND pg_get_serial_sequence('t', attname) IS NOT NULL",
+			},
+			nil,
+		},
+		{
+			"ALTER TABLE t ADD INDEX idx_a (a), ADD UNIQUE KEY uk_b (b), ADD KEY (c(10)), DROP INDEX idx_old",
+			[]string{
+				"CREATE INDEX t_idx_a ON t (a)",
+				"ALTER TABLE t ADD CONSTRAINT t_uk_b UNIQUE (b)",
+				"CREATE INDEX ON t ((left(c, 10)))",
+				"DROP INDEX idx_old",
+			},
+			nil,
+		},
+		// Indexes are found under their own name, then under their table's prefix
+		{"ALTER TABLE users DROP INDEX idx_users_email", []string{"DROP INDEX idx_users_email"}, nil},
+		{"ALTER TABLE t DROP KEY uk_old", []string{"ALTER TABLE t DROP CONSTRAINT t_uk_old"}, nil},
+		{"ALTER TABLE t DROP INDEX IF EXISTS uk_old", []string{"ALTER TABLE t DROP CONSTRAINT IF EXISTS t_uk_old"}, nil},
+		{"ALTER TABLE t DROP INDEX IF EXISTS idx_gone", nil, []string{"Can't DROP 'idx_gone'; check that column/key exists"}},
+		{
+			"ALTER TABLE app.t RENAME COLUMN a TO b, RENAME INDEX i1 TO i2, RENAME TO t2, DROP PRIMARY KEY, ADD PRIMARY KEY (b)",
+			[]string{
+				"ALTER TABLE app.t RENAME COLUMN a TO b",
+				"DO $mygo$ BEGIN IF EXISTS (SELECT FROM pg_trigger WHERE tgrelid = to_regclass('app.t') AND tgname = 't_a_on_update') THEN " +
+					"DROP TRIGGER IF EXISTS t_a_on_update ON app.t; " +
+					"CREATE TRIGGER t_b_on_update BEFORE UPDATE ON app.t FOR EACH ROW EXECUTE FUNCTION mygo_on_update_current_timestamp('b'); END IF; END $mygo$",
+				"ALTER INDEX app.t_i1 RENAME TO t_i2",
+				"ALTER TABLE app.t DROP CONSTRAINT t_pkey",
+				"ALTER TABLE app.t ADD PRIMARY KEY (b)",
+				"ALTER TABLE app.t RENAME TO t2",
+			},
+			nil,
+		},
+		{
+			"ALTER TABLE t ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id), DROP FOREIGN KEY fk_old, DROP COLUMN legacy",
+			[]string{
+				"ALTER TABLE t ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)",
+				"ALTER TABLE t DROP CONSTRAINT fk_old",
+				"ALTER TABLE t DROP COLUMN legacy",
+				"DROP TRIGGER IF EXISTS t_legacy_on_update ON t",
+			},
+			nil,
+		},
+		{
+			"ALTER TABLE t ALTER COLUMN status SET DEFAULT 'new', ALTER note DROP DEFAULT, COMMENT = 'orders'",
+			[]string{
+				"ALTER TABLE t ALTER COLUMN status SET DEFAULT 'new'",
+				"ALTER TABLE t ALTER COLUMN note DROP DEFAULT",
+				"COMMENT ON TABLE t IS 'orders'",
+			},
+			nil,
+		},
+		{
+			"ALTER TABLE t ADD COLUMN changed TIMESTAMP NULL ON UPDATE CURRENT_TIMESTAMP, MODIFY seen DATETIME",
+			[]string{
+				"ALTER TABLE t ADD COLUMN changed timestamp",
+				onUpdateFunctionSQL,
+				"CREATE TRIGGER t_changed_on_update BEFORE UPDATE ON t FOR EACH ROW EXECUTE FUNCTION mygo_on_update_current_timestamp('changed')",
+				"ALTER TABLE t ALTER COLUMN seen DROP IDENTITY IF EXISTS",
+				"ALTER TABLE t ALTER COLUMN seen DROP DEFAULT",
+				"ALTER TABLE t ALTER COLUMN seen TYPE timestamp USING seen::timestamp",
+				"ALTER TABLE t DROP CONSTRAINT IF EXISTS t_seen_check",
+				"DROP TRIGGER IF EXISTS t_seen_on_update ON t",
+			},
+			nil,
+		},
+		{
+			"ALTER TABLE t CHANGE seen last_seen DATETIME ON UPDATE CURRENT_TIMESTAMP",
+			[]string{
+				"ALTER TABLE t RENAME COLUMN seen TO last_seen",
+				"ALTER TABLE t ALTER COLUMN last_seen DROP IDENTITY IF EXISTS",
+				"ALTER TABLE t ALTER COLUMN last_seen DROP DEFAULT",
+				"ALTER TABLE t ALTER COLUMN last_seen TYPE timestamp USING last_seen::timestamp",
+				"ALTER TABLE t DROP CONSTRAINT IF EXISTS t_seen_check",
+				"DROP TRIGGER IF EXISTS t_seen_on_update ON t",
+				onUpdateFunctionSQL,
+				"CREATE TRIGGER t_last_seen_on_update BEFORE UPDATE ON t FOR EACH ROW EXECUTE FUNCTION mygo_on_update_current_timestamp('last_seen')",
+			},
+			nil,
+		},
+		{
+			"ALTER TABLE t DISABLE KEYS",
+			nil,
+			[]string{"DISABLE KEYS was ignored: PostgreSQL always maintains indexes"},
+		},
+		{
+			"ALTER TABLE t ENGINE=InnoDB, ALGORITHM=INPLACE",
+			nil,
+			[]string{"table option ENGINE was ignored: it has no PostgreSQL counterpart"},
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		got := result.Statements
+		if got == nil && result.Query != "" {
+			got = []string{result.Query}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("for %s:\n got: %q\nwant: %q", tt.input, got, tt.want)
+		}
+		if !reflect.DeepEqual(result.Warnings, tt.warnings) {
+			t.Errorf("for %s:\n got warnings: %q\nwant: %q", tt.input, result.Warnings, tt.warnings)
+		}
+	}
+}
+
+func TestTranslateAlterTableErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{indexes: map[string]map[string]bool{
+		"t":     {"idx_old": false, "t_uk_old": true},
+		"app.t": {"t_i1": false},
+		"users": {"idx_users_email": false},
+	}})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ALTER TABLE t ADD PARTITION (PARTITION p1 VALUES LESS THAN (10))", "not supported"},
+		{"ALTER TABLE t MODIFY total INT AS (a + b)", "generated column"},
+		{"ALTER TABLE t RENAME TO other.t", "another database"},
+		{"ALTER TABLE t DROP INDEX idx_gone", "Can't DROP 'idx_gone'; check that column/key exists"},
+		{"ALTER TABLE t RENAME INDEX idx_gone TO idx_new", "Key 'idx_gone' doesn't exist in table 't'"},
+	}
+	for _, tt := range tests {
+		_, err := tr.Translate(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("for %s: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+
+	// Forms MySQL lacks are PostgreSQL's own and passed through
+	for _, input := range []string{
+		"ALTER TABLE t DISABLE TRIGGER ALL",
+		"ALTER TABLE t ENABLE ROW LEVEL SECURITY",
+		"ALTER TABLE t OWNER TO admin",
+		"ALTER TABLE t ALTER COLUMN a TYPE bigint",
+		"ALTER TABLE t RENAME CONSTRAINT c1 TO c2",
+		"ALTER TABLE t SET LOGGED",
+		"ALTER TABLE t FROB",
+	} {
+		result, err := tr.Translate(input)
+		if err != nil || result.Query != input || result.Statements != nil || result.Warnings != nil {
+			t.Errorf("for %s: got %+v, %v", input, result, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package translator
+
+// Statement is a parsed MySQL statement
+type Statement interface {
+	statementNode()
+}
+
+// ShowStmt is SHOW [FULL] [GLOBAL|SESSION] <object> [FROM name [FROM db]] [LIKE 'pattern']
+type ShowStmt struct {
+	Object   string // Normalized object words, e.g. "TABLES", "INDEX", "CREATE TABLE"
+	Full     bool
+	Global   bool
+	Name     string // Table or database the statement is about
+	Database string // Database given with FROM/IN
+	Like     string
+	HasLike  bool
+	Where    []Token // Tokens of a WHERE filter, if any
+	User     string  // SHOW GRANTS FOR user@host
+	Host     string
+}
+
+// DescribeStmt is DESC/DESCRIBE/EXPLAIN table [column]
+type DescribeStmt struct {
+	Table    string
+	Database string
+	Column   string
+}
+
+// UseStmt is USE database
+type UseStmt struct {
+	Database string
+}
+
+// HelpStmt is a "<command> --help" request
+type HelpStmt struct {
+	Topic string // Upper-cased command words, e.g. "SHOW CREATE"
+}
+
+// RawStmt is a statement mygo does not model structurally. It is translated
+// by rewriting its token stream.
+type RawStmt struct {
+	Tokens []Token
+}
+
+func (*ShowStmt) statementNode()     {}
+func (*DescribeStmt) statementNode() {}
+func (*UseStmt) statementNode()      {}
+func (*HelpStmt) statementNode()     {}
+func (*RawStmt) statementNode()      {}
+
+// Verb returns the upper-cased leading keyword of the statement
+func (s *RawStmt) Verb() string {
+	for _, tok := range s.Tokens {
+		if !tok.IsTrivia() {
+			return tok.Upper()
+		}
+	}
+	return ""
+}
+
+// InsertStmt is INSERT or REPLACE together with MySQL's conflict handling
+// clauses. INSERT ... SET is normalized to a column list and VALUES row.
+type InsertStmt struct {
+	Replace     bool
+	Ignore      bool
+	Table       []Token   // Target table name
+	Columns     []Token   // Column names, nil when the list is omitted
+	Source      []Token   // VALUES rows or SELECT query
+	RowAlias    string    // VALUES (...) AS alias
+	OnDuplicate [][]Token // ON DUPLICATE KEY UPDATE assignments
+}
+
+func (*InsertStmt) statementNode() {}
@@ -0,0 +1,93 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CallStmt is CALL procedure[([arg, ...])]
+type CallStmt struct {
+	Name []Token
+	Args [][]Token
+}
+
+func (*CallStmt) statementNode() {}
+
+func (p *parser) parseCall() (Statement, error) {
+	p.next() // CALL
+	stmt := &CallStmt{}
+	var err error
+	if stmt.Name, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if p.peek().IsOp("(") {
+		start := p.mark()
+		if err := p.skipGroup(); err != nil {
+			return nil, err
+		}
+		if args := trimTrivia(p.tokens[start+1 : p.pos-1]); len(args) > 0 {
+			for _, arg := range splitTopLevel(args, ",") {
+				stmt.Args = append(stmt.Args, trimTrivia(arg))
+			}
+		}
+	}
+	return stmt, p.expectEnd()
+}
+
+// translateCall calls a procedure, which PostgreSQL requires parentheses for.
+// Where MySQL sets the user variables passed for OUT and INOUT parameters,
+// PostgreSQL returns the parameters' values as a row, which the client
+// assigns to them. The catalog tells which parameters those are.
+func (t *Translator) translateCall(s *CallStmt) (*TranslationResult, error) {
+	result := &TranslationResult{}
+	var modes []string
+	if !t.routine && hasUserVariables(s.Args) {
+		catalog, err := t.requireCatalog("CALL with user variables")
+		if err != nil {
+			return nil, err
+		}
+		if modes, err = catalog.ProcedureModes(render(s.Name), len(s.Args)); err != nil {
+			return nil, err
+		}
+	}
+
+	binder := t.newVariableBinder(s.Args...)
+	args := make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		if i < len(modes) && modes[i] != "IN" {
+			if len(arg) != 1 || arg[0].Kind != TokVariable {
+				return nil, fmt.Errorf("OUT or INOUT argument %d for routine %s is not a variable or NEW pseudo-variable in BEFORE trigger",
+					i+1, render(s.Name))
+			}
+			result.OutVars = append(result.OutVars, variableName(arg[0]))
+			if modes[i] == "OUT" {
+				// The argument of an OUT parameter is not evaluated
+				args[i] = "NULL"
+				continue
+			}
+		}
+		if !t.routine {
+			arg = binder.bind(arg)
+		}
+		rewritten, err := t.rewriteTokens(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = render(rewritten)
+	}
+	result.Query = "CALL " + render(s.Name) + "(" + strings.Join(args, ", ") + ")"
+	result.Params = binder.names
+	return result, nil
+}
+
+// hasUserVariables reports whether any of the expressions reads a user variable
+func hasUserVariables(exprs [][]Token) bool {
+	for _, expr := range exprs {
+		for _, tok := range expr {
+			if tok.Kind == TokVariable {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateCall(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{procedures: map[string][]string{
+		"create_order": {"IN", "OUT"},
+		"shop.bump":    {"INOUT", "IN"},
+	}})
+
+	tests := []struct {
+		input   string
+		query   string
+		params  []string
+		outVars []string
+	}{
+		{"CALL refresh_stats", "CALL refresh_stats()", nil, nil},
+		{"CALL refresh_stats()", "CALL refresh_stats()", nil, nil},
+		{"CALL create_order(1, @id)", "CALL create_order(1, NULL)", nil, []string{"id"}},
+		{"CALL create_order(IFNULL(@User, 0), @ID)", "CALL create_order(COALESCE($1, 0), NULL)", []string{"user"}, []string{"id"}},
+		{"CALL shop.bump(@n, @n + 1)", "CALL shop.bump($1, $1 + 1)", []string{"n"}, []string{"n"}},
+		{"CALL create_order(?, @id)", "CALL create_order($1, NULL)", nil, []string{"id"}},
+		{"CALL unknown(@a)", "CALL unknown($1)", []string{"a"}, nil},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.query || !reflect.DeepEqual(result.Params, tt.params) || !reflect.DeepEqual(result.OutVars, tt.outVars) {
+			t.Errorf("for %s: got %q %q %q, want %q %q %q", tt.input,
+				result.Query, result.Params, result.OutVars, tt.query, tt.params, tt.outVars)
+		}
+	}
+
+	for _, input := range []string{
+		"CALL create_order(1, @b + 1)",
+		"CALL shop.bump(@n + 1, 2)",
+	} {
+		if _, err := tr.Translate(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+	if _, err := New(db.PostgreSQL).Translate("CALL create_order(1, @id)"); err == nil {
+		t.Errorf("expected an error without a catalog")
+	}
+}
@@ -0,0 +1,47 @@
+package translator
+
+import "fmt"
+
+// Catalog answers schema questions that cannot be settled from the statement
+// text alone. Table names are passed as PostgreSQL identifier text, quoted
+// where the statement quoted them.
+type Catalog interface {
+	// UniqueKeys returns the column lists of the table's primary key and
+	// unique indexes, primary key first
+	UniqueKeys(table string) ([][]string, error)
+
+	// Columns returns the table's column names in ordinal order
+	Columns(table string) ([]string, error)
+
+	// AutoIncrementColumn returns the column a sequence fills in, as for
+	// serial and identity columns, or "" if the table has none
+	AutoIncrementColumn(table string) (string, error)
+
+	// ProcedureModes returns the modes, IN, OUT or INOUT, of the parameters
+	// of the procedure called with args arguments, or nil if there is none
+	ProcedureModes(procedure string, args int) ([]string, error)
+
+	// Index returns the name of the table's index called name, or "" if
+	// the table has none, and whether a constraint of the table owns it
+	Index(table, name string) (string, bool, error)
+
+	// GetCurrentDatabase returns the name of the database connected to
+	GetCurrentDatabase() string
+
+	// HasSetting reports whether PostgreSQL has a configuration parameter
+	// of the name
+	HasSetting(name string) (bool, error)
+}
+
+// SetCatalog attaches the live schema used by translations that depend on it
+func (t *Translator) SetCatalog(catalog Catalog) {
+	t.catalog = catalog
+}
+
+// requireCatalog returns the catalog or explains why a statement needs one
+func (t *Translator) requireCatalog(what string) (Catalog, error) {
+	if t.catalog == nil {
+		return nil, fmt.Errorf("translating %s requires a database connection to look up the schema", what)
+	}
+	return t.catalog, nil
+}
@@ -0,0 +1,224 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CreateDatabaseStmt is CREATE DATABASE with MySQL's character set and
+// collation, or with the PostgreSQL options SHOW CREATE DATABASE prints
+type CreateDatabaseStmt struct {
+	IfNotExists bool
+	Name        Token
+	Charset     string      // Lower-cased MySQL character set
+	Collation   string      // Lower-cased MySQL collation
+	Options     []*TableOpt // PostgreSQL options such as OWNER or LC_COLLATE
+}
+
+// DropDatabaseStmt is DROP DATABASE [IF EXISTS] name
+type DropDatabaseStmt struct {
+	IfExists bool
+	Name     Token
+}
+
+func (*CreateDatabaseStmt) statementNode() {}
+func (*DropDatabaseStmt) statementNode()   {}
+
+// pgDatabaseOptions are the CREATE DATABASE options PostgreSQL takes as written
+var pgDatabaseOptions = map[string]bool{
+	"OWNER": true, "TEMPLATE": true, "ENCODING": true, "STRATEGY": true, "LOCALE": true,
+	"LC_COLLATE": true, "LC_CTYPE": true, "BUILTIN_LOCALE": true, "ICU_LOCALE": true,
+	"ICU_RULES": true, "LOCALE_PROVIDER": true, "COLLATION_VERSION": true, "TABLESPACE": true,
+	"ALLOW_CONNECTIONS": true, "CONNECTION LIMIT": true, "IS_TEMPLATE": true, "OID": true,
+}
+
+func (p *parser) parseCreateDatabase() (Statement, error) {
+	p.next() // CREATE
+	p.next() // DATABASE
+	stmt := &CreateDatabaseStmt{IfNotExists: p.acceptSeq("IF", "NOT", "EXISTS")}
+	stmt.Name = p.next()
+	if !stmt.Name.IsIdent() {
+		return nil, fmt.Errorf("syntax error: expected database name near '%s'", stmt.Name.Text)
+	}
+	p.accept("WITH")
+
+	for !p.atEnd() {
+		p.accept("DEFAULT")
+		switch {
+		case p.acceptSeq("CHARACTER", "SET"), p.accept("CHARSET"):
+			p.acceptOp("=")
+			stmt.Charset = strings.ToLower(p.next().Value)
+		case p.accept("COLLATE"):
+			p.acceptOp("=")
+			stmt.Collation = strings.ToLower(p.next().Value)
+		case p.accept("ENCRYPTION"):
+			p.acceptOp("=")
+			if value := p.next(); strings.EqualFold(value.Value, "Y") {
+				return nil, fmt.Errorf("database encryption is not supported on PostgreSQL")
+			}
+		default:
+			name := p.next().Upper()
+			if name == "CONNECTION" && p.accept("LIMIT") {
+				name += " LIMIT"
+			}
+			if !pgDatabaseOptions[name] {
+				return nil, fmt.Errorf("syntax error: unknown database option '%s'", name)
+			}
+			p.acceptOp("=")
+			start := p.mark()
+			p.acceptOp("-")
+			value := p.next()
+			if value.Kind == TokEOF {
+				return nil, p.errorf("expected value for database option %s", name)
+			}
+			stmt.Options = append(stmt.Options, &TableOpt{Name: name, Value: Token{Kind: value.Kind, Text: render(p.since(start))}})
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseDropDatabase() (Statement, error) {
+	p.next() // DROP
+	p.next() // DATABASE
+	stmt := &DropDatabaseStmt{IfExists: p.acceptSeq("IF", "EXISTS")}
+	stmt.Name = p.next()
+	if !stmt.Name.IsIdent() {
+		return nil, fmt.Errorf("syntax error: expected database name near '%s'", stmt.Name.Text)
+	}
+	return stmt, p.expectEnd()
+}
+
+// mysqlCharsets maps MySQL character sets to PostgreSQL server encodings.
+// An empty encoding marks a character set PostgreSQL cannot store. ascii has
+// no counterpart that rejects other characters: SQL_ASCII checks nothing at
+// all, so it is stored as UTF8.
+var mysqlCharsets = map[string]string{
+	"utf8mb4": "UTF8", "utf8mb3": "UTF8", "utf8": "UTF8", "ascii": "UTF8",
+	"latin1": "WIN1252", "latin2": "LATIN2", "latin5": "LATIN5", "latin7": "LATIN7",
+	"cp1250": "WIN1250", "cp1251": "WIN1251", "cp1256": "WIN1256", "cp1257": "WIN1257",
+	"cp866": "WIN866", "koi8r": "KOI8R", "koi8u": "KOI8U", "greek": "ISO_8859_7",
+	"hebrew": "ISO_8859_8", "gb2312": "EUC_CN", "ujis": "EUC_JP", "eucjpms": "EUC_JP",
+	"euckr": "EUC_KR", "tis620": "WIN874",
+	"big5": "", "gbk": "", "gb18030": "", "sjis": "", "cp932": "", "ucs2": "",
+	"utf16": "", "utf16le": "", "utf32": "", "binary": "",
+}
+
+// collationLanguages maps the language words of MySQL collation names to ICU locales
+var collationLanguages = map[string]string{
+	"swedish": "sv", "danish": "da", "german1": "de", "german2": "de-u-co-phonebk",
+	"spanish": "es", "spanish2": "es-u-co-trad", "polish": "pl", "czech": "cs",
+	"turkish": "tr", "icelandic": "is", "latvian": "lv", "romanian": "ro",
+	"slovenian": "sl", "estonian": "et", "slovak": "sk", "lithuanian": "lt",
+	"croatian": "hr", "hungarian": "hu", "persian": "fa", "sinhala": "si",
+	"vietnamese": "vi", "esperanto": "eo", "roman": "la",
+}
+
+// icuLocale returns the ICU locale for the language part of a MySQL
+// collation, such as de_pb_0900_ai_ci or swedish_ci; und is the root locale
+func icuLocale(language string) string {
+	words := strings.Split(language, "_")
+	if tag, ok := collationLanguages[words[0]]; ok {
+		return tag
+	}
+	if len(words) > 1 && len(words[0]) == 2 && words[0] != "ai" && words[0] != "as" && words[0] != "ci" && words[0] != "cs" {
+		switch words[1] {
+		case "pb":
+			return words[0] + "-u-co-phonebk"
+		case "trad":
+			return words[0] + "-u-co-trad"
+		}
+		return words[0]
+	}
+	return "und"
+}
+
+// databaseLocale converts a MySQL character set and collation to
+// CREATE DATABASE options. A _bin collation compares bytes, as the C locale
+// does; other collations use ICU. PostgreSQL cannot make a case- or
+// accent-insensitive collation a database default, so that is a warning.
+func databaseLocale(charset, collation string) ([]string, []string, error) {
+	if collation != "" && charset == "" {
+		charset, _, _ = strings.Cut(collation, "_")
+	}
+	var options, warnings []string
+	if charset != "" {
+		encoding, ok := mysqlCharsets[charset]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown character set: '%s'", charset)
+		}
+		if encoding == "" {
+			return nil, nil, fmt.Errorf("character set %s cannot be used for a PostgreSQL database", charset)
+		}
+		options = append(options, "ENCODING "+quoteLiteral(encoding))
+		if charset == "ascii" {
+			warnings = append(warnings, "character set ascii is stored as UTF8: PostgreSQL has no encoding that rejects characters beyond ASCII")
+		}
+	}
+	if collation == "" {
+		return options, warnings, nil
+	}
+
+	language, ok := strings.CutPrefix(collation, charset+"_")
+	if !ok {
+		return nil, nil, fmt.Errorf("COLLATION '%s' is not valid for CHARACTER SET '%s'", collation, charset)
+	}
+	if language == "bin" || strings.HasSuffix(language, "_bin") {
+		return append(options, "LC_COLLATE 'C'"), warnings, nil
+	}
+	options = append(options, "LOCALE_PROVIDER icu", "ICU_LOCALE "+quoteLiteral(icuLocale(language)))
+	if strings.HasSuffix(language, "_ci") || strings.Contains(language, "_ai_") {
+		warnings = append(warnings, fmt.Sprintf("collation %s ignores case or accents, but PostgreSQL database collations compare them; "+
+			"use a nondeterministic collation on the columns that need it", collation))
+	}
+	return options, warnings, nil
+}
+
+// translateCreateDatabase maps the MySQL character set and collation to
+// encoding and locale options. A new encoding or locale has to be copied
+// from template0, which every cluster keeps in its initial state.
+// IF NOT EXISTS has no PostgreSQL form, so the client checks pg_database.
+func (t *Translator) translateCreateDatabase(s *CreateDatabaseStmt) (*TranslationResult, error) {
+	options, warnings, err := databaseLocale(s.Charset, s.Collation)
+	if err != nil {
+		return nil, err
+	}
+	needsTemplate, hasTemplate := len(options) > 0, false
+	var given []string
+	for _, opt := range s.Options {
+		switch opt.Name {
+		case "TEMPLATE":
+			hasTemplate = true
+		case "ENCODING", "LOCALE", "LC_COLLATE", "LC_CTYPE", "LOCALE_PROVIDER", "ICU_LOCALE", "BUILTIN_LOCALE":
+			needsTemplate = true
+		}
+		given = append(given, opt.Name+" "+opt.Value.Text)
+	}
+	options = append(given, options...)
+	if needsTemplate && !hasTemplate {
+		options = append(options, "TEMPLATE template0")
+	}
+
+	query := "CREATE DATABASE " + s.Name.Text
+	if len(options) > 0 {
+		query += " " + strings.Join(options, " ")
+	}
+	result := &TranslationResult{Query: query, Warnings: warnings, NoTransaction: true}
+	if s.IfNotExists {
+		result.IsSpecial = true
+		result.SpecialType = "create_database_if_not_exists"
+		result.Args = []string{catalogName(s.Name)}
+	}
+	return result, nil
+}
+
+// translateDropDatabase leaves IF EXISTS to the client, which checks
+// pg_database and reports a missing database as MySQL does
+func (t *Translator) translateDropDatabase(s *DropDatabaseStmt) (*TranslationResult, error) {
+	result := &TranslationResult{Query: "DROP DATABASE " + s.Name.Text, NoTransaction: true}
+	if s.IfExists {
+		result.IsSpecial = true
+		result.SpecialType = "drop_database_if_exists"
+		result.Args = []string{catalogName(s.Name)}
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package translator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateCreateDatabase(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input       string
+		want        string
+		specialType string
+		warnings    []string
+	}{
+		{"CREATE DATABASE app", "CREATE DATABASE app", "", nil},
+		{
+			"CREATE DATABASE IF NOT EXISTS app CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci",
+			"CREATE DATABASE app ENCODING 'UTF8' LOCALE_PROVIDER icu ICU_LOCALE 'und' TEMPLATE template0",
+			"create_database_if_not_exists",
+			[]string{"collation utf8mb4_unicode_ci ignores case or accents, but PostgreSQL database collations compare them; " +
+				"use a nondeterministic collation on the columns that need it"},
+		},
+		{"CREATE DATABASE `Shop` DEFAULT CHARSET=latin1", `CREATE DATABASE "Shop" ENCODING 'WIN1252' TEMPLATE template0`, "", nil},
+		{
+			"CREATE DATABASE app CHARACTER SET ascii COLLATE ascii_bin",
+			"CREATE DATABASE app ENCODING 'UTF8' LC_COLLATE 'C' TEMPLATE template0", "",
+			[]string{"character set ascii is stored as UTF8: PostgreSQL has no encoding that rejects characters beyond ASCII"},
+		},
+		{"CREATE DATABASE app COLLATE utf8mb4_bin", "CREATE DATABASE app ENCODING 'UTF8' LC_COLLATE 'C' TEMPLATE template0", "", nil},
+		{
+			"CREATE DATABASE app DEFAULT CHARACTER SET = utf8mb4 DEFAULT COLLATE = utf8mb4_de_pb_0900_as_cs",
+			"CREATE DATABASE app ENCODING 'UTF8' LOCALE_PROVIDER icu ICU_LOCALE 'de-u-co-phonebk' TEMPLATE template0", "", nil,
+		},
+		{"CREATE DATABASE app COLLATE latin2_czech_cs", "CREATE DATABASE app ENCODING 'LATIN2' LOCALE_PROVIDER icu ICU_LOCALE 'cs' TEMPLATE template0", "", nil},
+		{
+			// SHOW CREATE DATABASE output
+			"CREATE DATABASE app WITH OWNER = bob ENCODING = 'UTF8' LC_COLLATE = 'en_US.utf8' LC_CTYPE = 'en_US.utf8' LOCALE_PROVIDER = 'icu' ICU_LOCALE = 'und';",
+			"CREATE DATABASE app OWNER bob ENCODING 'UTF8' LC_COLLATE 'en_US.utf8' LC_CTYPE 'en_US.utf8' LOCALE_PROVIDER 'icu' ICU_LOCALE 'und' TEMPLATE template0",
+			"", nil,
+		},
+		{"CREATE DATABASE app TEMPLATE template1 CONNECTION LIMIT = -1", "CREATE DATABASE app TEMPLATE template1 CONNECTION LIMIT -1", "", nil},
+		{"DROP DATABASE app", "DROP DATABASE app", "", nil},
+		{"DROP DATABASE IF EXISTS App", "DROP DATABASE App", "drop_database_if_exists", nil},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.want)
+		}
+		if result.SpecialType != tt.specialType {
+			t.Errorf("for %s: got special type %q, want %q", tt.input, result.SpecialType, tt.specialType)
+		}
+		if !reflect.DeepEqual(result.Warnings, tt.warnings) {
+			t.Errorf("for %s:\n got warnings: %q\nwant: %q", tt.input, result.Warnings, tt.warnings)
+		}
+	}
+
+	// The existence check uses the name PostgreSQL stores
+	result, err := tr.Translate("DROP DATABASE IF EXISTS App")
+	if err != nil || !reflect.DeepEqual(result.Args, []string{"app"}) {
+		t.Errorf("expected catalog name app, got %v (%v)", result, err)
+	}
+}
+
+func TestTranslateCreateDatabaseErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"CREATE DATABASE app CHARACTER SET gbk", "cannot be used"},
+		{"CREATE DATABASE app CHARACTER SET klingon", "unknown character set"},
+		{"CREATE DATABASE app CHARACTER SET utf8mb4 COLLATE latin1_swedish_ci", "not valid for CHARACTER SET"},
+		{"CREATE DATABASE app ENCRYPTION = 'Y'", "encryption"},
+	}
+	for _, tt := range tests {
+		_, err := tr.Translate(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("for %s: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+}
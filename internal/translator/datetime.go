@@ -0,0 +1,412 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// dateFormatSpecifiers maps MySQL DATE_FORMAT specifiers to to_char patterns.
+// FM suppresses the padding PostgreSQL adds where MySQL prints none.
+var dateFormatSpecifiers = map[byte]string{
+	'a': "Dy", 'b': "Mon", 'c': "FMMM", 'D': "FMDDth", 'd': "DD", 'e': "FMDD",
+	'f': "US", 'H': "HH24", 'h': "HH12", 'I': "HH12", 'i': "MI", 'j': "DDD",
+	'k': "FMHH24", 'l': "FMHH12", 'M': "FMMonth", 'm': "MM", 'p': "AM",
+	'r': "HH12:MI:SS AM", 'S': "SS", 's': "SS", 'T': "HH24:MI:SS", 'v': "IW",
+	'W': "FMDay", 'x': "IYYY", 'Y': "YYYY", 'y': "YY",
+}
+
+// timeSpecifiers are the DATE_FORMAT specifiers that carry a time of day
+const timeSpecifiers = "fHhIiklprSsT"
+
+// unsupportedSpecifiers have no to_char counterpart
+const unsupportedSpecifiers = "UuVXw"
+
+// mysqlDateFormat converts a MySQL format string to a to_char pattern. Text
+// that is not a specifier is double-quoted so to_char does not read it as a
+// pattern. It also reports which kinds of fields the format contains.
+func mysqlDateFormat(format string) (pattern string, hasDate, hasTime bool, err error) {
+	var b, literal strings.Builder
+	flush := func() {
+		text := literal.String()
+		literal.Reset()
+		if strings.IndexFunc(text, func(r rune) bool {
+			return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '"' || r == '\\'
+		}) < 0 {
+			b.WriteString(text)
+			return
+		}
+		b.WriteString(`"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`)
+	}
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i+1 == len(format) {
+			literal.WriteByte(c)
+			continue
+		}
+		i++
+		spec := format[i]
+		switch {
+		case strings.IndexByte(unsupportedSpecifiers, spec) >= 0:
+			return "", false, false, fmt.Errorf("date format specifier %%%c has no PostgreSQL equivalent", spec)
+		case dateFormatSpecifiers[spec] != "":
+			flush()
+			b.WriteString(dateFormatSpecifiers[spec])
+			if strings.IndexByte(timeSpecifiers, spec) >= 0 {
+				hasTime = true
+			} else {
+				hasDate = true
+			}
+		default:
+			// %% and unknown specifiers print the character itself
+			literal.WriteByte(spec)
+		}
+	}
+	flush()
+	return b.String(), hasDate, hasTime, nil
+}
+
+// formatArg converts the literal format argument of DATE_FORMAT and friends
+func formatArg(c *funcCall, i int) (pattern string, hasDate, hasTime bool, err error) {
+	arg := c.Args[i]
+	if len(arg) != 1 || arg[0].Kind != TokString {
+		return "", false, false, fmt.Errorf("%s needs a literal format string to be translated to PostgreSQL", c.Name)
+	}
+	pattern, hasDate, hasTime, err = mysqlDateFormat(arg[0].Value)
+	if err != nil {
+		return "", false, false, err
+	}
+	pattern, err = pgString(pattern)
+	return pattern, hasDate, hasTime, err
+}
+
+var dateLiteral = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}$`)
+
+// temporal renders a date/time argument. String literals are cast, since
+// PostgreSQL cannot pick a function or operator for an untyped literal.
+func temporal(arg []Token) string {
+	if len(arg) == 1 && arg[0].Kind == TokString {
+		if dateLiteral.MatchString(arg[0].Value) {
+			return arg[0].Text + "::date"
+		}
+		return arg[0].Text + "::timestamp"
+	}
+	return render(arg)
+}
+
+// isDateValued reports whether an argument is known to be a DATE rather than a DATETIME
+func isDateValued(arg []Token) bool {
+	return len(arg) == 1 && (arg[0].Is("CURRENT_DATE") || arg[0].Kind == TokString && dateLiteral.MatchString(arg[0].Value))
+}
+
+// intervalUnits maps MySQL interval units to PostgreSQL interval text. The
+// flag marks units of a day or more, which keep a DATE a DATE in MySQL.
+var intervalUnits = map[string]struct {
+	unit  string
+	whole bool
+}{
+	"MICROSECOND": {"microseconds", false},
+	"SECOND":      {"seconds", false},
+	"MINUTE":      {"minutes", false},
+	"HOUR":        {"hours", false},
+	"DAY":         {"days", true},
+	"WEEK":        {"weeks", true},
+	"MONTH":       {"months", true},
+	"QUARTER":     {"months", true}, // amount multiplied by 3
+	"YEAR":        {"years", true},
+}
+
+// compoundUnits maps MySQL compound units to PostgreSQL interval field qualifiers
+var compoundUnits = map[string]string{
+	"YEAR_MONTH":    "YEAR TO MONTH",
+	"DAY_HOUR":      "DAY TO HOUR",
+	"DAY_MINUTE":    "DAY TO MINUTE",
+	"DAY_SECOND":    "DAY TO SECOND",
+	"HOUR_MINUTE":   "HOUR TO MINUTE",
+	"HOUR_SECOND":   "HOUR TO SECOND",
+	"MINUTE_SECOND": "MINUTE TO SECOND",
+}
+
+// isIntervalUnit reports whether tok names a MySQL interval unit
+func isIntervalUnit(tok Token) bool {
+	if tok.Kind != TokIdent {
+		return false
+	}
+	name := tok.Upper()
+	_, simple := intervalUnits[name]
+	return simple || compoundUnits[name] != "" || strings.HasSuffix(name, "_MICROSECOND")
+}
+
+// mysqlInterval converts the amount and unit of a MySQL INTERVAL to a
+// PostgreSQL interval expression:
+//
+//	INTERVAL 3 DAY               -> INTERVAL '3 days'
+//	INTERVAL n HOUR              -> n * INTERVAL '1 hours'
+//	INTERVAL '1:30' HOUR_MINUTE  -> INTERVAL '1:30' HOUR TO MINUTE
+func mysqlInterval(amount []Token, unitTok Token) (expr string, whole bool, err error) {
+	unitName := unitTok.Upper()
+	amount = trimTrivia(amount)
+	if len(amount) == 0 {
+		return "", false, fmt.Errorf("syntax error: missing INTERVAL amount before %s", unitTok.Text)
+	}
+
+	if qualifier, ok := compoundUnits[unitName]; ok {
+		value := renderTrimmed(amount)
+		if len(amount) == 1 && amount[0].Kind == TokString {
+			value = amount[0].Value
+		} else if len(amount) != 1 || amount[0].Kind != TokNumber {
+			return "", false, fmt.Errorf("INTERVAL ... %s needs a literal amount to be translated to PostgreSQL", unitName)
+		}
+		return fmt.Sprintf("INTERVAL %s %s", quoteLiteral(value), qualifier), unitName == "YEAR_MONTH", nil
+	}
+	unit, ok := intervalUnits[unitName]
+	if !ok {
+		return "", false, fmt.Errorf("INTERVAL unit %s is not supported on PostgreSQL", unitName)
+	}
+
+	factor := 1.0
+	if unitName == "QUARTER" {
+		factor = 3
+	}
+	text := strings.ReplaceAll(render(amount), " ", "")
+	if len(amount) == 1 && amount[0].Kind == TokString {
+		text = strings.TrimSpace(amount[0].Value)
+	}
+	if n, err := strconv.ParseFloat(text, 64); err == nil {
+		return fmt.Sprintf("INTERVAL '%s %s'", strconv.FormatFloat(n*factor, 'f', -1, 64), unit.unit), unit.whole, nil
+	}
+	expr = render(amount)
+	if len(amount) > 1 {
+		expr = "(" + expr + ")"
+	}
+	return fmt.Sprintf("%s * INTERVAL '%s %s'", expr, strconv.FormatFloat(factor, 'f', -1, 64), unit.unit), unit.whole, nil
+}
+
+// intervalStop are keywords that cannot be part of an INTERVAL amount
+var intervalStop = map[string]bool{
+	"AND": true, "OR": true, "FROM": true, "WHERE": true, "THEN": true, "ELSE": true,
+	"WHEN": true, "END": true, "AS": true, "ORDER": true, "GROUP": true, "HAVING": true,
+	"LIMIT": true, "ON": true, "SET": true,
+}
+
+// findIntervalUnit returns the index of the unit ending the INTERVAL
+// expression that starts after from, or -1 when there is none
+func findIntervalUnit(tokens []Token, from int) int {
+	depth := 0
+	for i := from; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.IsOp("("):
+			depth++
+		case tok.IsOp(")"):
+			if depth == 0 {
+				return -1
+			}
+			depth--
+		case depth > 0:
+		case tok.IsOp(",") || tok.IsOp(";") || intervalStop[tok.Upper()]:
+			return -1
+		case isIntervalUnit(tok):
+			return i
+		}
+	}
+	return -1
+}
+
+// rewriteIntervals converts MySQL INTERVAL expressions used with date
+// arithmetic operators, e.g. now() - INTERVAL 1 DAY
+func rewriteIntervals(tokens []Token) ([]Token, error) {
+	var out []Token
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if !tok.Is("INTERVAL") || tok.Kind != TokIdent {
+			out = append(out, tok)
+			continue
+		}
+		unit := findIntervalUnit(tokens, i+1)
+		if unit < 0 {
+			out = append(out, tok)
+			continue
+		}
+		if to := nextSignificant(tokens, unit+1); to < len(tokens) && tokens[to].Is("TO") {
+			// Already PostgreSQL syntax: INTERVAL '1:30' HOUR TO MINUTE
+			out = append(out, tokens[i:to+1]...)
+			i = to
+			continue
+		}
+		expr, _, err := mysqlInterval(tokens[i+1:unit], tokens[unit])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pgTokens(expr)...)
+		i = unit
+	}
+	return out, nil
+}
+
+// intervalArg converts the INTERVAL argument of DATE_ADD and friends. A bare
+// amount counts days, as in ADDDATE(d, 3).
+func intervalArg(arg []Token) (string, bool, error) {
+	if len(arg) > 0 && arg[0].Is("INTERVAL") {
+		unit := findIntervalUnit(arg, 1)
+		if unit < 0 || unit != len(arg)-1 {
+			return "", false, fmt.Errorf("syntax error: expected INTERVAL expr unit near '%s'", render(arg))
+		}
+		return mysqlInterval(arg[1:unit], arg[unit])
+	}
+	return mysqlInterval(arg, Token{Kind: TokIdent, Text: "DAY", Value: "DAY"})
+}
+
+// dateArithmetic builds DATE_ADD/DATE_SUB and ADDDATE/SUBDATE
+func dateArithmetic(op string) funcRewrite {
+	return func(c *funcCall) (string, error) {
+		if err := c.argCount(2, 2); err != nil {
+			return "", err
+		}
+		interval, whole, err := intervalArg(c.Args[1])
+		if err != nil {
+			return "", err
+		}
+		expr := fmt.Sprintf("%s %s %s", temporal(c.Args[0]), op, interval)
+		if whole && isDateValued(c.Args[0]) {
+			// date + interval is a timestamp in PostgreSQL, a DATE in MySQL
+			return "(" + expr + ")::date", nil
+		}
+		return "(" + expr + ")", nil
+	}
+}
+
+// DATE_FORMAT(d, '%Y-%m-%d') -> to_char(d, 'YYYY-MM-DD')
+func rewriteDateFormat(c *funcCall) (string, error) {
+	if err := c.argCount(2, 2); err != nil {
+		return "", err
+	}
+	pattern, _, _, err := formatArg(c, 1)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("to_char(%s, %s)", temporal(c.Args[0]), pattern), nil
+}
+
+// STR_TO_DATE(s, format) returns a DATE, DATETIME or TIME depending on the
+// fields in the format, as MySQL does
+func rewriteStrToDate(c *funcCall) (string, error) {
+	if err := c.argCount(2, 2); err != nil {
+		return "", err
+	}
+	pattern, hasDate, hasTime, err := formatArg(c, 1)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case !hasTime:
+		return fmt.Sprintf("to_date(%s, %s)", c.arg(0), pattern), nil
+	case !hasDate:
+		return fmt.Sprintf("to_timestamp(%s, %s)::time", c.arg(0), pattern), nil
+	}
+	return fmt.Sprintf("to_timestamp(%s, %s)::timestamp", c.arg(0), pattern), nil
+}
+
+// UNIX_TIMESTAMP([d]) -> seconds since the epoch, reading d in the session time zone
+func rewriteUnixTimestamp(c *funcCall) (string, error) {
+	if err := c.argCount(0, 1); err != nil {
+		return "", err
+	}
+	if len(c.Args) == 0 {
+		return "floor(extract(epoch FROM now()))::bigint", nil
+	}
+	return fmt.Sprintf("floor(extract(epoch FROM %s))::bigint", castExpr(c.Args[0], "timestamptz")), nil
+}
+
+// FROM_UNIXTIME(n [, format]) -> to_timestamp(n) as a local DATETIME or formatted text
+func rewriteFromUnixtime(c *funcCall) (string, error) {
+	if err := c.argCount(1, 2); err != nil {
+		return "", err
+	}
+	if len(c.Args) == 1 {
+		return fmt.Sprintf("to_timestamp(%s)::timestamp", c.arg(0)), nil
+	}
+	pattern, _, _, err := formatArg(c, 1)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("to_char(to_timestamp(%s), %s)", c.arg(0), pattern), nil
+}
+
+// DATEDIFF(a, b) -> a::date - b::date, a whole number of days
+func rewriteDateDiff(c *funcCall) (string, error) {
+	if err := c.argCount(2, 2); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s - %s)", castExpr(c.Args[0], "date"), castExpr(c.Args[1], "date")), nil
+}
+
+// timestampDiffSeconds are the TIMESTAMPDIFF units measured in elapsed time
+var timestampDiffSeconds = map[string]string{
+	"MICROSECOND": " * 1000000", "SECOND": "", "MINUTE": " / 60", "HOUR": " / 3600",
+	"DAY": " / 86400", "WEEK": " / 604800",
+}
+
+// TIMESTAMPDIFF(unit, a, b) -> the number of whole units from a to b.
+// Months, quarters and years are calendar based, like MySQL, via age().
+func rewriteTimestampDiff(c *funcCall) (string, error) {
+	if err := c.argCount(3, 3); err != nil {
+		return "", err
+	}
+	unit := strings.TrimPrefix(strings.ToUpper(c.arg(0)), "SQL_TSI_")
+	from, to := castExpr(c.Args[1], "timestamp"), castExpr(c.Args[2], "timestamp")
+
+	if scale, ok := timestampDiffSeconds[unit]; ok {
+		return fmt.Sprintf("trunc(extract(epoch FROM %s - %s)%s)::bigint", to, from, scale), nil
+	}
+	age := fmt.Sprintf("age(%s, %s)", to, from)
+	months := fmt.Sprintf("(extract(year FROM %s) * 12 + extract(month FROM %s))", age, age)
+	switch unit {
+	case "MONTH":
+		return months + "::bigint", nil
+	case "QUARTER":
+		return fmt.Sprintf("trunc(%s / 3)::bigint", months), nil
+	case "YEAR":
+		return fmt.Sprintf("extract(year FROM %s)::bigint", age), nil
+	}
+	return "", fmt.Errorf("TIMESTAMPDIFF unit %s is not supported", c.arg(0))
+}
+
+// LAST_DAY(d) -> the last day of d's month as a DATE
+func rewriteLastDay(c *funcCall) (string, error) {
+	if err := c.argCount(1, 1); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(date_trunc('month', %s) + INTERVAL '1 month - 1 day')::date", castExpr(c.Args[0], "date")), nil
+}
+
+// extractFunction maps YEAR(d), MONTH(d) and friends to extract() as an integer
+func extractFunction(field, adjust string) funcRewrite {
+	return func(c *funcCall) (string, error) {
+		if err := c.argCount(1, 1); err != nil {
+			return "", err
+		}
+		expr := fmt.Sprintf("extract(%s FROM %s)", field, temporal(c.Args[0]))
+		if field == "second" {
+			// Fractional seconds are cut off, not rounded
+			expr = "floor(" + expr + ")"
+		}
+		expr += "::integer" + adjust
+		if adjust != "" {
+			expr = "(" + expr + ")"
+		}
+		return expr, nil
+	}
+}
+
+// replaceFunction maps a call without arguments to a fixed expression
+func replaceFunction(expr string) funcRewrite {
+	return func(c *funcCall) (string, error) {
+		if err := c.argCount(0, 0); err != nil {
+			return "", err
+		}
+		return expr, nil
+	}
+}
@@ -0,0 +1,81 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateDateFunctions(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT DATE_FORMAT(created_at, '%Y-%m-%d %H:%i:%s') FROM t", "SELECT to_char(created_at, 'YYYY-MM-DD HH24:MI:SS') FROM t"},
+		{"SELECT DATE_FORMAT(d, '%W, %M %e at %l%p')", `SELECT to_char(d, 'FMDay, FMMonth FMDD" at "FMHH12AM')`},
+		{"SELECT DATE_FORMAT(d, '%d/%m/%y 100%%')", "SELECT to_char(d, 'DD/MM/YY 100%')"},
+		{"SELECT STR_TO_DATE('01/02/2024', '%d/%m/%Y')", "SELECT to_date('01/02/2024', 'DD/MM/YYYY')"},
+		{"SELECT STR_TO_DATE(s, '%Y-%m-%d %H:%i')", "SELECT to_timestamp(s, 'YYYY-MM-DD HH24:MI')::timestamp"},
+		{"SELECT STR_TO_DATE(s, '%H:%i')", "SELECT to_timestamp(s, 'HH24:MI')::time"},
+		{"SELECT UNIX_TIMESTAMP()", "SELECT floor(extract(epoch FROM now()))::bigint"},
+		{"SELECT UNIX_TIMESTAMP(created_at) FROM t", "SELECT floor(extract(epoch FROM created_at::timestamptz))::bigint FROM t"},
+		{"SELECT FROM_UNIXTIME(ts) FROM t", "SELECT to_timestamp(ts)::timestamp FROM t"},
+		{"SELECT FROM_UNIXTIME(ts, '%Y') FROM t", "SELECT to_char(to_timestamp(ts), 'YYYY') FROM t"},
+		{"SELECT DATE_ADD(created_at, INTERVAL 3 DAY) FROM t", "SELECT (created_at + INTERVAL '3 days') FROM t"},
+		{"SELECT DATE_SUB(CURDATE(), INTERVAL 1 MONTH)", "SELECT (CURRENT_DATE - INTERVAL '1 months')::date"},
+		{"SELECT DATE_ADD('2024-01-31', INTERVAL 1 QUARTER)", "SELECT ('2024-01-31'::date + INTERVAL '3 months')::date"},
+		{"SELECT DATE_ADD(d, INTERVAL n HOUR) FROM t", "SELECT (d + n * INTERVAL '1 hours') FROM t"},
+		{"SELECT DATE_ADD(d, INTERVAL '1:30' HOUR_MINUTE) FROM t", "SELECT (d + INTERVAL '1:30' HOUR TO MINUTE) FROM t"},
+		{"SELECT ADDDATE(d, 7) FROM t", "SELECT (d + INTERVAL '7 days') FROM t"},
+		{"SELECT * FROM t WHERE created_at > NOW() - INTERVAL 1 DAY", "SELECT * FROM t WHERE created_at > NOW() - INTERVAL '1 days'"},
+		{"SELECT * FROM t WHERE d > now() - INTERVAL '7 days'", "SELECT * FROM t WHERE d > now() - INTERVAL '7 days'"},
+		{"SELECT DATEDIFF(end_date, start_date) FROM t", "SELECT (end_date::date - start_date::date) FROM t"},
+		{"SELECT DATEDIFF(NOW(), '2024-01-01')", "SELECT ((NOW())::date - '2024-01-01'::date)"},
+		{
+			"SELECT TIMESTAMPDIFF(MINUTE, started, finished) FROM jobs",
+			"SELECT trunc(extract(epoch FROM finished::timestamp - started::timestamp) / 60)::bigint FROM jobs",
+		},
+		{
+			"SELECT TIMESTAMPDIFF(MONTH, a, b) FROM t",
+			"SELECT (extract(year FROM age(b::timestamp, a::timestamp)) * 12 + extract(month FROM age(b::timestamp, a::timestamp)))::bigint FROM t",
+		},
+		{"SELECT LAST_DAY(d) FROM t", "SELECT (date_trunc('month', d::date) + INTERVAL '1 month - 1 day')::date FROM t"},
+		{"SELECT YEAR(d), DAYOFWEEK(d) FROM t", "SELECT extract(year FROM d)::integer, (extract(dow FROM d)::integer + 1) FROM t"},
+		{
+			"SELECT DATE_FORMAT(DATE_ADD(CURDATE(), INTERVAL -1 DAY), '%Y%m%d')",
+			"SELECT to_char((CURRENT_DATE + INTERVAL '-1 days')::date, 'YYYYMMDD')",
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.want)
+		}
+	}
+}
+
+func TestTranslateDateFormatErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT DATE_FORMAT(d, '%U') FROM t", "%U has no PostgreSQL equivalent"},
+		{"SELECT DATE_FORMAT(d, fmt) FROM t", "needs a literal format string"},
+	}
+	for _, tt := range tests {
+		_, err := tr.Translate(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("for %s: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,870 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CreateTableStmt is MySQL's CREATE TABLE with its column definitions, inline
+// keys and table options taken apart
+type CreateTableStmt struct {
+	Temporary   bool
+	IfNotExists bool
+	Table       []Token
+	Columns     []*ColumnDef
+	Constraints [][]Token   // PRIMARY KEY, FOREIGN KEY, CHECK and UNIQUE kept in the table body
+	Indexes     []*IndexDef // Inline keys that become CREATE INDEX statements
+	Options     []*TableOpt // ENGINE=..., AUTO_INCREMENT=..., COMMENT=...
+	Like        []Token     // CREATE TABLE t LIKE other
+	Select      []Token     // CREATE TABLE t [AS] SELECT ...
+}
+
+// ColumnDef is one column of CREATE TABLE or ALTER TABLE
+type ColumnDef struct {
+	Name          Token
+	Type          string  // Upper-cased base type, e.g. "INT"
+	TypeArgs      []Token // Tokens inside the type's parentheses
+	Unsigned      bool
+	NotNull       bool
+	Null          bool
+	Default       []Token
+	AutoIncrement bool
+	PrimaryKey    bool
+	Unique        bool
+	Comment       *Token
+	Generated     []Token // Expression of a generated column, with parentheses
+	OnUpdate      []Token // ON UPDATE CURRENT_TIMESTAMP
+	Checks        [][]Token
+	References    []Token
+}
+
+// IndexDef is an index declared inside CREATE TABLE or by ALTER TABLE ADD INDEX
+type IndexDef struct {
+	Name       string // Catalog name; empty when MySQL would pick the name
+	Constraint string // Catalog name of the CONSTRAINT given before UNIQUE
+	Unique     bool
+	Fulltext   bool
+	Spatial    bool
+	Using      string
+	Columns    []IndexColumn
+}
+
+// IndexColumn is a key part: a column with an optional prefix length, or an expression
+type IndexColumn struct {
+	Name   Token
+	Length string
+	Expr   []Token // Functional key part, without the outer parentheses
+	Order  string  // ASC or DESC
+}
+
+// TableOpt is a table option such as ENGINE=InnoDB
+type TableOpt struct {
+	Name  string // Upper-cased, multi-word names joined by a space
+	Value Token
+}
+
+func (*CreateTableStmt) statementNode() {}
+
+// isCreateTable reports whether the statement starts with CREATE [TEMPORARY] TABLE
+func (p *parser) isCreateTable() bool {
+	next := p.peekN(1)
+	return next.Is("TABLE") || (next.Is("TEMPORARY") && p.peekN(2).Is("TABLE"))
+}
+
+func (p *parser) parseCreateTable() (Statement, error) {
+	p.next() // CREATE
+	stmt := &CreateTableStmt{Temporary: p.accept("TEMPORARY")}
+	if err := p.expect("TABLE"); err != nil {
+		return nil, err
+	}
+	stmt.IfNotExists = p.acceptSeq("IF", "NOT", "EXISTS")
+
+	start := p.mark()
+	if _, err := p.qualifiedName(); err != nil {
+		return nil, err
+	}
+	stmt.Table = p.since(start)
+
+	if p.accept("LIKE") {
+		start := p.mark()
+		if _, err := p.qualifiedName(); err != nil {
+			return nil, err
+		}
+		stmt.Like = p.since(start)
+		return stmt, p.expectEnd()
+	}
+
+	if p.peek().IsOp("(") {
+		if p.peekN(1).Is("LIKE") {
+			p.next()
+			p.next()
+			start := p.mark()
+			if _, err := p.qualifiedName(); err != nil {
+				return nil, err
+			}
+			stmt.Like = p.since(start)
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return stmt, p.expectEnd()
+		}
+		if !p.peekN(1).Is("SELECT") {
+			open := p.mark()
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+			for _, elem := range splitTopLevel(p.tokens[open+1:p.pos-1], ",") {
+				if err := stmt.addElement(trimTrivia(elem)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := p.parseTableOptions(&stmt.Options); err != nil {
+		return nil, err
+	}
+	if p.peek().Is("PARTITION") {
+		return nil, fmt.Errorf("partitioned tables cannot be translated to PostgreSQL, create the partitions with PostgreSQL syntax")
+	}
+	p.accept("IGNORE", "REPLACE")
+	p.accept("AS")
+	if !p.atEnd() {
+		if !p.peek().Is("SELECT") && !p.peek().IsOp("(") && !p.peek().Is("WITH") {
+			return nil, p.errorf("unexpected input")
+		}
+		stmt.Select = trimTrivia(p.rest())
+		p.pos = len(p.tokens)
+	}
+	if stmt.Columns == nil && stmt.Select == nil {
+		return nil, fmt.Errorf("syntax error: CREATE TABLE needs column definitions, LIKE or SELECT")
+	}
+	return stmt, nil
+}
+
+// addElement parses one comma-separated element of the table body
+func (s *CreateTableStmt) addElement(tokens []Token) error {
+	if len(tokens) == 0 {
+		return fmt.Errorf("syntax error: empty element in CREATE TABLE")
+	}
+	p := newParser(tokens)
+	switch first := p.peek(); {
+	case first.Kind == TokQuotedIdent:
+	case first.Is("PRIMARY"), first.Is("FOREIGN"), first.Is("CHECK"):
+		s.Constraints = append(s.Constraints, tokens)
+		return nil
+	case first.Is("CONSTRAINT"):
+		p.next()
+		name := ""
+		if !p.peek().Is("PRIMARY") && !p.peek().Is("UNIQUE") && !p.peek().Is("FOREIGN") && !p.peek().Is("CHECK") {
+			name = catalogName(p.next())
+		}
+		if p.peek().Is("UNIQUE") {
+			index, err := p.parseIndexDef()
+			if err != nil {
+				return err
+			}
+			index.Constraint = name
+			s.Indexes = append(s.Indexes, index)
+			return nil
+		}
+		s.Constraints = append(s.Constraints, tokens)
+		return nil
+	case first.Is("KEY"), first.Is("INDEX"), first.Is("UNIQUE"), first.Is("FULLTEXT"), first.Is("SPATIAL"):
+		index, err := p.parseIndexDef()
+		if err != nil {
+			return err
+		}
+		s.Indexes = append(s.Indexes, index)
+		return nil
+	}
+
+	col, err := p.parseColumnDef()
+	if err != nil {
+		return err
+	}
+	s.Columns = append(s.Columns, col)
+	return nil
+}
+
+// parseIndexDef parses [UNIQUE|FULLTEXT|SPATIAL] {KEY|INDEX} [name] [USING type] (key_part, ...) [options]
+func (p *parser) parseIndexDef() (*IndexDef, error) {
+	index := &IndexDef{}
+	switch {
+	case p.accept("UNIQUE"):
+		index.Unique = true
+	case p.accept("FULLTEXT"):
+		index.Fulltext = true
+	case p.accept("SPATIAL"):
+		index.Spatial = true
+	}
+	p.accept("KEY", "INDEX")
+	if tok := p.peek(); tok.IsIdent() && !tok.Is("USING") {
+		p.next()
+		index.Name = catalogName(tok)
+	}
+	if p.accept("USING") {
+		index.Using = strings.ToLower(p.next().Text)
+	}
+
+	open := p.mark()
+	if !p.peek().IsOp("(") {
+		return nil, p.errorf("expected '('")
+	}
+	if err := p.skipGroup(); err != nil {
+		return nil, err
+	}
+	for _, part := range splitTopLevel(p.tokens[open+1:p.pos-1], ",") {
+		column, err := parseKeyPart(trimTrivia(part))
+		if err != nil {
+			return nil, err
+		}
+		index.Columns = append(index.Columns, column)
+	}
+
+	// Index options
+	for !p.atEnd() {
+		switch {
+		case p.accept("USING"):
+			index.Using = strings.ToLower(p.next().Text)
+		case p.accept("COMMENT"), p.accept("KEY_BLOCK_SIZE"), p.accept("WITH"):
+			p.acceptOp("=")
+			p.accept("PARSER")
+			p.next()
+		case p.accept("VISIBLE", "INVISIBLE"):
+		case p.accept("ALGORITHM", "LOCK"):
+			// CREATE INDEX online DDL hints
+			p.acceptOp("=")
+			p.next()
+		default:
+			return nil, p.errorf("unexpected index option")
+		}
+	}
+	return index, nil
+}
+
+// parseKeyPart parses col [(length)] [ASC|DESC] or (expr) [ASC|DESC]
+func parseKeyPart(tokens []Token) (IndexColumn, error) {
+	var part IndexColumn
+	if n := len(tokens); n > 0 && (tokens[n-1].Is("ASC") || tokens[n-1].Is("DESC")) {
+		part.Order = tokens[n-1].Upper()
+		tokens = trimTrivia(tokens[:n-1])
+	}
+	if len(tokens) == 0 {
+		return part, fmt.Errorf("syntax error: empty key part")
+	}
+	if tokens[0].IsOp("(") {
+		if matchParen(tokens, 0) != len(tokens)-1 {
+			return part, fmt.Errorf("syntax error: bad key part '%s'", render(tokens))
+		}
+		part.Expr = trimTrivia(tokens[1 : len(tokens)-1])
+		return part, nil
+	}
+	if !tokens[0].IsIdent() {
+		return part, fmt.Errorf("syntax error: bad key part '%s'", render(tokens))
+	}
+	part.Name = tokens[0]
+	rest := trimTrivia(tokens[1:])
+	if len(rest) > 0 {
+		if !rest[0].IsOp("(") || matchParen(rest, 0) != len(rest)-1 {
+			return part, fmt.Errorf("syntax error: bad key part '%s'", render(tokens))
+		}
+		part.Length = renderTrimmed(rest[1 : len(rest)-1])
+	}
+	return part, nil
+}
+
+// parseColumnDef parses name type [attributes]
+func (p *parser) parseColumnDef() (*ColumnDef, error) {
+	col := &ColumnDef{Name: p.next()}
+	if !col.Name.IsIdent() {
+		return nil, fmt.Errorf("syntax error: expected column name near '%s'", col.Name.Text)
+	}
+	if err := p.parseDataType(col); err != nil {
+		return nil, err
+	}
+
+	for !p.atEnd() {
+		switch {
+		case p.accept("UNSIGNED"), p.accept("ZEROFILL"):
+			col.Unsigned = true
+		case p.accept("SIGNED"), p.accept("BINARY"), p.accept("VISIBLE", "INVISIBLE"):
+		case p.acceptSeq("CHARACTER", "SET"), p.accept("CHARSET"), p.accept("COLLATE"),
+			p.accept("COLUMN_FORMAT"), p.accept("STORAGE"), p.accept("SRID"):
+			p.next()
+		case p.acceptSeq("NOT", "NULL"):
+			col.NotNull = true
+		case p.accept("NULL"):
+			col.Null = true
+		case p.accept("DEFAULT"):
+			col.Default = p.parseOperand()
+		case p.accept("AUTO_INCREMENT"):
+			col.AutoIncrement = true
+		case p.acceptSeq("PRIMARY", "KEY"), p.accept("KEY"):
+			col.PrimaryKey = true
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			col.Unique = true
+		case p.accept("COMMENT"):
+			tok := p.next()
+			col.Comment = &tok
+		case p.acceptSeq("ON", "UPDATE"):
+			col.OnUpdate = p.parseOperand()
+		case p.acceptSeq("GENERATED", "ALWAYS"), p.peek().Is("AS"):
+			if err := p.expect("AS"); err != nil {
+				return nil, err
+			}
+			open := p.mark()
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+			col.Generated = p.tokens[open:p.pos]
+			p.accept("VIRTUAL", "STORED")
+		case p.peek().Is("CHECK") || p.peek().Is("CONSTRAINT"):
+			start := p.mark()
+			if p.accept("CONSTRAINT") {
+				p.next()
+			}
+			if err := p.expect("CHECK"); err != nil {
+				return nil, err
+			}
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+			p.accept("ENFORCED")
+			col.Checks = append(col.Checks, p.since(start))
+		case p.peek().Is("REFERENCES"):
+			col.References = trimTrivia(p.rest())
+			p.pos = len(p.tokens)
+		default:
+			return nil, p.errorf("unexpected column attribute")
+		}
+	}
+	return col, nil
+}
+
+// parseDataType parses the type of a column, routine parameter or variable
+// into col: the base type, its parenthesized arguments and the attributes
+// that qualify it, such as UNSIGNED or CHARACTER SET
+func (p *parser) parseDataType(col *ColumnDef) error {
+	typeTok := p.next()
+	if typeTok.Kind != TokIdent {
+		return fmt.Errorf("syntax error: expected data type for %s near '%s'", col.Name.Text, typeTok.Text)
+	}
+	col.Type = typeTok.Upper()
+	switch {
+	case col.Type == "DOUBLE" && p.accept("PRECISION"):
+	case col.Type == "LONG" && (p.peek().Is("VARCHAR") || p.peek().Is("VARBINARY")),
+		col.Type == "CHARACTER" && p.peek().Is("VARYING"),
+		col.Type == "NATIONAL" && (p.peek().Is("CHAR") || p.peek().Is("VARCHAR")):
+		col.Type += " " + p.next().Upper()
+	}
+	if p.peek().IsOp("(") {
+		open := p.mark()
+		if err := p.skipGroup(); err != nil {
+			return err
+		}
+		col.TypeArgs = trimTrivia(p.tokens[open+1 : p.pos-1])
+	}
+	for {
+		switch {
+		case p.accept("UNSIGNED"), p.accept("ZEROFILL"):
+			col.Unsigned = true
+		case p.accept("SIGNED"), p.accept("BINARY"):
+		case p.acceptSeq("CHARACTER", "SET"), p.accept("CHARSET"), p.accept("COLLATE"):
+			p.next()
+		default:
+			return nil
+		}
+	}
+}
+
+// parseOperand consumes a DEFAULT or ON UPDATE value: a literal, a signed
+// number, a name, a function call or a parenthesized expression
+func (p *parser) parseOperand() []Token {
+	start := p.mark()
+	tok := p.next()
+	switch {
+	case tok.IsOp("-") || tok.IsOp("+"):
+		p.next()
+	case tok.IsOp("("):
+		p.pos = start
+		if p.skipGroup() != nil {
+			p.pos = len(p.tokens)
+		}
+	case tok.Kind == TokIdent && p.peek().IsOp("("):
+		if p.skipGroup() != nil {
+			p.pos = len(p.tokens)
+		}
+	}
+	return p.since(start)
+}
+
+// parseTableOptions consumes table options such as ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+func (p *parser) parseTableOptions(options *[]*TableOpt) error {
+	for {
+		p.acceptOp(",")
+		tok := p.peek()
+		if tok.Kind != TokIdent || tok.Is("AS") || tok.Is("SELECT") || tok.Is("PARTITION") ||
+			tok.Is("IGNORE") || tok.Is("REPLACE") || tok.Is("WITH") {
+			return nil
+		}
+		p.accept("DEFAULT")
+		var words []string
+		for tok := p.peek(); tok.Kind == TokIdent && !p.peekN(1).IsOp("=") && len(words) < 2; tok = p.peek() {
+			if words == nil && !(tok.Is("CHARACTER") || tok.Is("DATA") || tok.Is("INDEX")) {
+				break
+			}
+			words = append(words, p.next().Upper())
+		}
+		name := p.next()
+		if name.Kind != TokIdent {
+			return fmt.Errorf("syntax error: expected table option near '%s'", name.Text)
+		}
+		words = append(words, name.Upper())
+		p.acceptOp("=")
+		value := p.next()
+		if value.Kind == TokEOF {
+			return p.errorf("expected value for table option %s", strings.Join(words, " "))
+		}
+		*options = append(*options, &TableOpt{Name: strings.Join(words, " "), Value: value})
+	}
+}
+
+// translateCreateTable turns a MySQL CREATE TABLE into PostgreSQL DDL: the
+// table itself, then CREATE INDEX for inline keys, triggers for ON UPDATE
+// CURRENT_TIMESTAMP columns and COMMENT ON statements
+func (t *Translator) translateCreateTable(s *CreateTableStmt) (*TranslationResult, error) {
+	table := render(s.Table)
+	create := "CREATE "
+	if s.Temporary {
+		create += "TEMPORARY "
+	}
+	create += "TABLE "
+	if s.IfNotExists {
+		create += "IF NOT EXISTS "
+	}
+	create += table
+
+	if s.Like != nil {
+		return &TranslationResult{Query: fmt.Sprintf("%s (LIKE %s INCLUDING ALL)", create, render(s.Like))}, nil
+	}
+	if s.Columns == nil {
+		query, err := t.rewriteTokens(s.Select)
+		if err != nil {
+			return nil, err
+		}
+		return &TranslationResult{Query: create + " AS " + render(query)}, nil
+	}
+	if s.Select != nil {
+		return nil, fmt.Errorf("CREATE TABLE ... SELECT with column definitions is not supported on PostgreSQL")
+	}
+
+	var start string
+	var after []string
+	for _, opt := range s.Options {
+		switch opt.Name {
+		case "AUTO_INCREMENT":
+			start = opt.Value.Text
+		case "COMMENT":
+			after = append(after, fmt.Sprintf("COMMENT ON TABLE %s IS %s", table, opt.Value.Text))
+		}
+		// ENGINE, CHARSET, COLLATE, ROW_FORMAT and the like have no PostgreSQL counterpart
+	}
+
+	var body []string
+	for _, col := range s.Columns {
+		def, extra, err := t.columnDefinition(table, col, start)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, def)
+		after = append(after, extra...)
+	}
+	for _, constraint := range s.Constraints {
+		def, err := t.tableConstraint(constraint)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, def)
+	}
+
+	var indexes []string
+	for _, index := range s.Indexes {
+		if index.Unique && index.isPlain() {
+			// A plain unique key stays a table constraint
+			def := "UNIQUE (" + strings.Join(index.columnNames(), ", ") + ")"
+			if name := index.constraintName(s.Table); name != "" {
+				def = "CONSTRAINT " + name + " " + def
+			}
+			body = append(body, def)
+			continue
+		}
+		stmt, err := t.createIndex(s.Table, index, s.IfNotExists)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, stmt)
+	}
+
+	statements := []string{create + " (\n  " + strings.Join(body, ",\n  ") + "\n)"}
+	statements = append(statements, indexes...)
+	statements = append(statements, onUpdateTriggers(s.Table, s.Columns, s.IfNotExists)...)
+	statements = append(statements, after...)
+	return statementsResult(statements), nil
+}
+
+// statementsResult wraps one or more PostgreSQL statements
+func statementsResult(statements []string) *TranslationResult {
+	if len(statements) == 1 {
+		return &TranslationResult{Query: statements[0]}
+	}
+	return &TranslationResult{Statements: statements}
+}
+
+// columnTypes maps MySQL column types to PostgreSQL types. Display widths
+// such as INT(11) are dropped. TINYINT(1) stays an integer: MySQL clients
+// write 0 and 1 to it, which a boolean column would reject.
+var columnTypes = map[string]string{
+	"TINYINT": "smallint", "SMALLINT": "smallint", "MEDIUMINT": "integer",
+	"INT": "integer", "INTEGER": "integer", "BIGINT": "bigint",
+	"FLOAT": "real", "DOUBLE": "double precision", "REAL": "double precision",
+	"DATETIME": "timestamp", "TIMESTAMP": "timestamp", "YEAR": "smallint",
+	"TINYTEXT": "text", "TEXT": "text", "MEDIUMTEXT": "text", "LONGTEXT": "text", "LONG": "text", "LONG VARCHAR": "text",
+	"TINYBLOB": "bytea", "BLOB": "bytea", "MEDIUMBLOB": "bytea", "LONGBLOB": "bytea",
+	"BINARY": "bytea", "VARBINARY": "bytea", "LONG VARBINARY": "bytea",
+	"JSON": "jsonb", "BOOL": "boolean", "BOOLEAN": "boolean",
+}
+
+// unsignedTypes widen unsigned integers so the whole MySQL range fits
+var unsignedTypes = map[string]string{
+	"TINYINT": "smallint", "SMALLINT": "integer", "MEDIUMINT": "integer",
+	"INT": "bigint", "INTEGER": "bigint", "BIGINT": "bigint",
+}
+
+// typesWithArgs keep their parenthesized arguments
+var typesWithArgs = map[string]bool{
+	"DECIMAL": true, "NUMERIC": true, "DEC": true, "FIXED": true, "CHAR": true, "VARCHAR": true,
+	"CHARACTER": true, "CHARACTER VARYING": true, "NATIONAL CHAR": true, "NATIONAL VARCHAR": true,
+	"NCHAR": true, "NVARCHAR": true, "BIT": true, "DATETIME": true, "TIMESTAMP": true, "TIME": true,
+}
+
+// columnType converts a column's type. It also returns a CHECK constraint
+// for MySQL range rules PostgreSQL types do not express, such as UNSIGNED.
+func columnType(col *ColumnDef) (string, string, error) {
+	name := col.Name.Text
+	args := render(col.TypeArgs)
+	switch col.Type {
+	case "ENUM":
+		labels, longest := enumLabels(col.TypeArgs)
+		return fmt.Sprintf("varchar(%d)", longest), fmt.Sprintf("%s IN (%s)", name, strings.Join(labels, ", ")), nil
+	case "SET":
+		labels, _ := enumLabels(col.TypeArgs)
+		return "text", fmt.Sprintf("string_to_array(%s, ',') <@ ARRAY[%s]::text[]", name, strings.Join(labels, ", ")), nil
+	case "SERIAL":
+		return "bigint", "", nil
+	case "GEOMETRY", "POINT", "LINESTRING", "POLYGON", "MULTIPOINT", "MULTILINESTRING", "MULTIPOLYGON", "GEOMETRYCOLLECTION":
+		return "", "", fmt.Errorf("spatial column type %s is not supported on PostgreSQL without PostGIS", col.Type)
+	}
+
+	pgType, known := columnTypes[col.Type]
+	if col.Unsigned {
+		if wide, ok := unsignedTypes[col.Type]; ok {
+			pgType, known = wide, true
+		}
+	}
+	if !known {
+		pgType = strings.ToLower(col.Type)
+		switch col.Type {
+		case "DEC", "FIXED":
+			pgType = "numeric"
+		case "NCHAR", "NATIONAL CHAR":
+			pgType = "char"
+		case "NVARCHAR", "NATIONAL VARCHAR":
+			pgType = "varchar"
+		}
+	}
+	if typesWithArgs[col.Type] && args != "" {
+		pgType += "(" + args + ")"
+	}
+
+	check := ""
+	if col.Unsigned {
+		check = name + " >= 0"
+	}
+	return pgType, check, nil
+}
+
+// enumLabels returns the quoted labels of ENUM/SET and the longest label's length
+func enumLabels(args []Token) ([]string, int) {
+	var labels []string
+	longest := 1
+	for _, tok := range args {
+		if tok.Kind == TokString {
+			labels = append(labels, tok.Text)
+			if n := len([]rune(tok.Value)); n > longest {
+				longest = n
+			}
+		}
+	}
+	return labels, longest
+}
+
+// columnDefinition renders a column for CREATE TABLE or ALTER TABLE ADD
+// COLUMN. Column comments come back as separate statements. start is the
+// table's AUTO_INCREMENT option, if any.
+func (t *Translator) columnDefinition(table string, col *ColumnDef, start string) (string, []string, error) {
+	if col.OnUpdate != nil {
+		if err := checkOnUpdate(col); err != nil {
+			return "", nil, err
+		}
+	}
+	pgType, check, err := columnType(col)
+	if err != nil {
+		return "", nil, err
+	}
+
+	def := []string{col.Name.Text, pgType}
+	if col.Generated != nil {
+		expr, err := t.rewriteTokens(col.Generated)
+		if err != nil {
+			return "", nil, err
+		}
+		// PostgreSQL only has stored generated columns
+		def = append(def, "GENERATED ALWAYS AS "+render(expr)+" STORED")
+	}
+	if col.AutoIncrement || col.Type == "SERIAL" {
+		identity := "GENERATED BY DEFAULT AS IDENTITY"
+		if start != "" {
+			identity += " (START WITH " + start + ")"
+		}
+		def = append(def, identity)
+	}
+	if col.NotNull || col.Type == "SERIAL" {
+		def = append(def, "NOT NULL")
+	}
+
+	switch {
+	case col.Default != nil:
+		value, err := t.rewriteTokens(col.Default)
+		if err != nil {
+			return "", nil, err
+		}
+		def = append(def, "DEFAULT "+render(value))
+	case col.Type == "ENUM" && col.NotNull && col.Generated == nil:
+		// MySQL fills a NOT NULL enum with its first value
+		if labels, _ := enumLabels(col.TypeArgs); len(labels) > 0 {
+			def = append(def, "DEFAULT "+labels[0])
+		}
+	}
+
+	if col.PrimaryKey {
+		def = append(def, "PRIMARY KEY")
+	}
+	if col.Unique || col.Type == "SERIAL" {
+		def = append(def, "UNIQUE")
+	}
+	if check != "" {
+		def = append(def, "CHECK ("+check+")")
+	}
+	for _, c := range col.Checks {
+		expr, err := t.rewriteTokens(c)
+		if err != nil {
+			return "", nil, err
+		}
+		def = append(def, strings.TrimSuffix(strings.TrimSpace(render(expr)), " ENFORCED"))
+	}
+	if col.References != nil {
+		def = append(def, render(col.References))
+	}
+
+	var extra []string
+	if col.Comment != nil {
+		extra = append(extra, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", table, col.Name.Text, col.Comment.Text))
+	}
+	return strings.Join(def, " "), extra, nil
+}
+
+// tableConstraint renders PRIMARY KEY, FOREIGN KEY and CHECK table constraints
+func (t *Translator) tableConstraint(tokens []Token) (string, error) {
+	p := newParser(tokens)
+	prefix := ""
+	if p.accept("CONSTRAINT") {
+		if tok := p.peek(); !tok.Is("PRIMARY") && !tok.Is("FOREIGN") && !tok.Is("CHECK") {
+			prefix = "CONSTRAINT " + p.next().Text + " "
+		}
+	}
+
+	switch {
+	case p.acceptSeq("PRIMARY", "KEY"):
+		index, err := p.parseIndexDef()
+		if err != nil {
+			return "", err
+		}
+		// MySQL names every primary key PRIMARY, so the name is dropped
+		return "PRIMARY KEY (" + strings.Join(index.columnNames(), ", ") + ")", nil
+
+	case p.acceptSeq("FOREIGN", "KEY"):
+		if tok := p.peek(); tok.IsIdent() {
+			p.next() // index name, PostgreSQL does not create one
+		}
+		rest, err := t.rewriteTokens(p.rest())
+		if err != nil {
+			return "", err
+		}
+		return prefix + "FOREIGN KEY " + renderTrimmed(rest), nil
+
+	case p.peek().Is("CHECK"):
+		rest, err := t.rewriteTokens(trimTrivia(p.rest()))
+		if err != nil {
+			return "", err
+		}
+		return prefix + strings.TrimSuffix(render(rest), " ENFORCED"), nil
+	}
+	return "", fmt.Errorf("syntax error: unsupported table constraint '%s'", render(tokens))
+}
+
+// isPlain reports whether an index only lists whole columns with default
+// options, so it can be written as a table constraint
+func (index *IndexDef) isPlain() bool {
+	if index.Using != "" && index.Using != "btree" {
+		return false
+	}
+	for _, col := range index.Columns {
+		if col.Expr != nil || col.Length != "" || col.Order == "DESC" {
+			return false
+		}
+	}
+	return true
+}
+
+// columnNames returns the index columns as rendered identifiers
+func (index *IndexDef) columnNames() []string {
+	var names []string
+	for _, col := range index.Columns {
+		names = append(names, col.Name.Text)
+	}
+	return names
+}
+
+// constraintName returns the name the index or unique key's constraint
+// should get, or "" for PostgreSQL to pick one
+func (index *IndexDef) constraintName(table []Token) string {
+	switch {
+	case index.Constraint != "":
+		return indexName(table, index.Constraint)
+	case index.Name != "":
+		return indexName(table, index.Name)
+	}
+	return ""
+}
+
+// indexName names a MySQL index in PostgreSQL. MySQL index names belong to
+// the table, PostgreSQL's to the schema, where keys such as idx_status of
+// different tables would clash, so they are prefixed with the table's name.
+func indexName(table []Token, name string) string {
+	return pgIdent(catalogName(table[len(table)-1]) + "_" + name)
+}
+
+// existingIndex finds the PostgreSQL index of a MySQL index name: an index of
+// the name itself, as created outside mygo or printed by SHOW INDEX, or else
+// the one indexName gave it. It returns "" if the table has neither.
+func (t *Translator) existingIndex(table []Token, name Token) (string, bool, error) {
+	catalog, err := t.requireCatalog("a reference to an index")
+	if err != nil {
+		return "", false, err
+	}
+	for _, candidate := range []string{catalogName(name), catalogName(table[len(table)-1]) + "_" + catalogName(name)} {
+		index, constraint, err := catalog.Index(render(table), candidate)
+		if err != nil || index != "" {
+			return pgIdent(index), constraint, err
+		}
+	}
+	return "", false, nil
+}
+
+// createIndex renders a MySQL index as CREATE INDEX. A FULLTEXT index
+// becomes a GIN index over the document MATCH searches.
+func (t *Translator) createIndex(table []Token, index *IndexDef, ifNotExists bool) (string, error) {
+	if index.Spatial {
+		return "", fmt.Errorf("SPATIAL indexes are not supported on PostgreSQL without PostGIS")
+	}
+
+	var parts []string
+	using := index.Using
+	if index.Fulltext {
+		document, err := fulltextKey(index.Columns)
+		if err != nil {
+			return "", err
+		}
+		parts, using = []string{"(" + document + ")"}, "gin"
+	} else {
+		var err error
+		if parts, err = t.keyParts(index.Columns); err != nil {
+			return "", err
+		}
+	}
+
+	stmt := "CREATE "
+	if index.Unique {
+		stmt += "UNIQUE "
+	}
+	stmt += "INDEX "
+	name := index.constraintName(table)
+	if ifNotExists {
+		if name == "" {
+			name = defaultIndexName(table, index)
+		}
+		stmt += "IF NOT EXISTS "
+	}
+	if name != "" {
+		stmt += name + " "
+	}
+	stmt += "ON " + render(table)
+	if using != "" {
+		stmt += " USING " + using
+	}
+	return stmt + " (" + strings.Join(parts, ", ") + ")", nil
+}
+
+// keyParts renders the key parts of an index. Prefix key parts become
+// left(col, n) expressions, which index exactly the same values.
+func (t *Translator) keyParts(columns []IndexColumn) ([]string, error) {
+	var parts []string
+	for _, col := range columns {
+		var part string
+		switch {
+		case col.Expr != nil:
+			expr, err := t.rewriteTokens(col.Expr)
+			if err != nil {
+				return nil, err
+			}
+			part = "(" + render(expr) + ")"
+		case col.Length != "":
+			part = fmt.Sprintf("(left(%s, %s))", col.Name.Text, col.Length)
+		default:
+			part = col.Name.Text
+		}
+		if col.Order != "" {
+			part += " " + col.Order
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// defaultIndexName builds the name PostgreSQL would pick, table_col_idx,
+// for statements that need to name the index themselves
+func defaultIndexName(table []Token, index *IndexDef) string {
+	words := []string{table[len(table)-1].Value}
+	for _, col := range index.Columns {
+		if col.Expr == nil {
+			words = append(words, col.Name.Value)
+		}
+	}
+	suffix := "idx"
+	if index.Unique {
+		suffix = "key"
+	}
+	return pgIdent(strings.ToLower(strings.Join(append(words, suffix), "_")))
+}
@@ -0,0 +1,148 @@
+package translator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateCreateTable(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{
+			"CREATE TABLE `users` (\n" +
+				"  `id` INT(11) UNSIGNED NOT NULL AUTO_INCREMENT,\n" +
+				"  `email` VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,\n" +
+				"  `active` TINYINT(1) NOT NULL DEFAULT 1,\n" +
+				"  `score` DOUBLE DEFAULT NULL,\n" +
+				"  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  UNIQUE KEY `uk_email` (`email`),\n" +
+				"  KEY `idx_created` (`created_at`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=100 DEFAULT CHARSET=utf8mb4 COMMENT='people'",
+			[]string{
+				"CREATE TABLE \"users\" (\n" +
+					"  \"id\" bigint GENERATED BY DEFAULT AS IDENTITY (START WITH 100) NOT NULL CHECK (\"id\" >= 0),\n" +
+					"  \"email\" varchar(255) NOT NULL,\n" +
+					"  \"active\" smallint NOT NULL DEFAULT 1,\n" +
+					"  \"score\" double precision DEFAULT NULL,\n" +
+					"  \"created_at\" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+					"  PRIMARY KEY (\"id\"),\n" +
+					"  CONSTRAINT users_uk_email UNIQUE (\"email\")\n" +
+					")",
+				`CREATE INDEX users_idx_created ON "users" ("created_at")`,
+				`COMMENT ON TABLE "users" IS 'people'`,
+			},
+		},
+		{
+			"CREATE TABLE orders (id BIGINT PRIMARY KEY AUTO_INCREMENT, status ENUM('new','paid','shipped') NOT NULL, note TEXT COMMENT 'free text')",
+			[]string{
+				"CREATE TABLE orders (\n" +
+					"  id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,\n" +
+					"  status varchar(7) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'paid', 'shipped')),\n" +
+					"  note text\n" +
+					")",
+				"COMMENT ON COLUMN orders.note IS 'free text'",
+			},
+		},
+		{
+			"CREATE TABLE t (a INT, b INT, total INT AS (a + b) VIRTUAL, tags SET('x','y'), INDEX (a DESC), KEY name_prefix (b(10)))",
+			[]string{
+				"CREATE TABLE t (\n" +
+					"  a integer,\n" +
+					"  b integer,\n" +
+					"  total integer GENERATED ALWAYS AS (a + b) STORED,\n" +
+					"  tags text CHECK (string_to_array(tags, ',') <@ ARRAY['x', 'y']::text[])\n" +
+					")",
+				"CREATE INDEX ON t (a DESC)",
+				"CREATE INDEX t_name_prefix ON t ((left(b, 10)))",
+			},
+		},
+		{
+			"CREATE TABLE IF NOT EXISTS items (id INT NOT NULL, order_id INT, KEY (order_id), CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE)",
+			[]string{
+				"CREATE TABLE IF NOT EXISTS items (\n" +
+					"  id integer NOT NULL,\n" +
+					"  order_id integer,\n" +
+					"  CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE\n" +
+					")",
+				"CREATE INDEX IF NOT EXISTS items_order_id_idx ON items (order_id)",
+			},
+		},
+		{
+			"CREATE TABLE products (id INT, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'touched')",
+			[]string{
+				"CREATE TABLE products (\n" +
+					"  id integer,\n" +
+					"  updated_at timestamp DEFAULT CURRENT_TIMESTAMP\n" +
+					")",
+				onUpdateFunctionSQL,
+				"CREATE TRIGGER products_updated_at_on_update BEFORE UPDATE ON products FOR EACH ROW EXECUTE FUNCTION mygo_on_update_current_timestamp('updated_at')",
+				"COMMENT ON COLUMN products.updated_at IS 'touched'",
+			},
+		},
+		{
+			"CREATE TABLE IF NOT EXISTS `Log` (`Changed` DATETIME(3) ON UPDATE NOW(3))",
+			[]string{
+				"CREATE TABLE IF NOT EXISTS \"Log\" (\n" +
+					"  \"Changed\" timestamp(3)\n" +
+					")",
+				onUpdateFunctionSQL,
+				`DROP TRIGGER IF EXISTS "Log_Changed_on_update" ON "Log"`,
+				`CREATE TRIGGER "Log_Changed_on_update" BEFORE UPDATE ON "Log" FOR EACH ROW EXECUTE FUNCTION mygo_on_update_current_timestamp('Changed')`,
+			},
+		},
+		// MySQL index names belong to the table, so two tables may share one
+		{
+			"CREATE TABLE a (id INT, status INT, KEY idx_status (status), UNIQUE KEY uk_id (id))",
+			[]string{"CREATE TABLE a (\n  id integer,\n  status integer,\n  CONSTRAINT a_uk_id UNIQUE (id)\n)", "CREATE INDEX a_idx_status ON a (status)"},
+		},
+		{
+			"CREATE TABLE b (id INT, status INT, KEY idx_status (status), UNIQUE KEY uk_id (id))",
+			[]string{"CREATE TABLE b (\n  id integer,\n  status integer,\n  CONSTRAINT b_uk_id UNIQUE (id)\n)", "CREATE INDEX b_idx_status ON b (status)"},
+		},
+		{"CREATE TABLE copy LIKE users", []string{"CREATE TABLE copy (LIKE users INCLUDING ALL)"}},
+		{"CREATE TABLE recent SELECT * FROM users LIMIT 5, 10", []string{"CREATE TABLE recent AS SELECT * FROM users LIMIT 10 OFFSET 5"}},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		got := result.Statements
+		if got == nil {
+			got = []string{result.Query}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("for %s:\n got: %q\nwant: %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateCreateTableErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"CREATE TABLE t (g GEOMETRY)", "spatial column type"},
+		{"CREATE TABLE t (id INT) PARTITION BY HASH(id)", "partitioned tables"},
+		{"CREATE TABLE t (id INT FOO)", "unexpected column attribute"},
+		{"CREATE TABLE t (n INT ON UPDATE 1)", "ON UPDATE 1"},
+	}
+	for _, tt := range tests {
+		_, err := tr.Translate(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("for %s: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// fulltextConfig is the text search configuration standing in for MySQL's
+// built-in full-text parser, which splits words without stemming them
+const fulltextConfig = "'simple'"
+
+// fulltextDocument is the text MATCH (a, b) searches: the columns joined with
+// spaces as one tsvector. FULLTEXT indexes are built over the same expression,
+// which only uses immutable functions, so that the planner can use them.
+func fulltextDocument(columns []string) string {
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		parts[i] = "coalesce(" + col + ", '')"
+	}
+	return "to_tsvector(" + fulltextConfig + ", " + strings.Join(parts, " || ' ' || ") + ")"
+}
+
+// fulltextKey returns the indexed expression of a FULLTEXT index
+func fulltextKey(columns []IndexColumn) (string, error) {
+	var names []string
+	for _, col := range columns {
+		if col.Expr != nil || col.Length != "" {
+			return "", fmt.Errorf("FULLTEXT index key parts must be plain columns")
+		}
+		names = append(names, col.Name.Text)
+	}
+	return fulltextDocument(names), nil
+}
+
+// conditionWords precede a MATCH that is used as a search condition rather
+// than for its relevance
+var conditionWords = map[string]bool{
+	"WHERE": true, "AND": true, "OR": true, "NOT": true, "ON": true, "HAVING": true, "WHEN": true,
+}
+
+// comparisonOps follow a MATCH whose relevance is compared, as in MATCH ... > 0.5
+var comparisonOps = map[string]bool{
+	"=": true, "<": true, ">": true, "<=": true, ">=": true, "<>": true, "!=": true,
+}
+
+// rewriteFulltext translates MATCH (cols) AGAINST (search [modifier]). As a
+// search condition it becomes document @@ query; elsewhere, as in the select
+// list or ORDER BY, it is the relevance, ts_rank(document, query). Natural
+// language mode matches rows with any of the words; boolean mode maps
+// MySQL's + - * and "phrase" operators onto a tsquery. It also returns the
+// warnings for what the search loses.
+func rewriteFulltext(tokens []Token) ([]Token, []string, error) {
+	var out []Token
+	var warnings []string
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		open := nextSignificant(tokens, i+1)
+		if !tok.Is("MATCH") || open == len(tokens) || !tokens[open].IsOp("(") {
+			out = append(out, tok)
+			continue
+		}
+		closing := matchParen(tokens, open)
+		if closing < 0 {
+			out = append(out, tok)
+			continue
+		}
+		against := nextSignificant(tokens, closing+1)
+		if against == len(tokens) || !tokens[against].Is("AGAINST") {
+			out = append(out, tok)
+			continue
+		}
+		searchOpen := nextSignificant(tokens, against+1)
+		searchClose := -1
+		if searchOpen < len(tokens) && tokens[searchOpen].IsOp("(") {
+			searchClose = matchParen(tokens, searchOpen)
+		}
+		if searchClose < 0 {
+			return nil, nil, fmt.Errorf("syntax error: expected AGAINST (search string)")
+		}
+
+		var columns []string
+		for _, col := range splitTopLevel(tokens[open+1:closing], ",") {
+			if col = trimTrivia(col); len(col) == 0 {
+				return nil, nil, fmt.Errorf("syntax error: empty column in MATCH")
+			}
+			columns = append(columns, render(col))
+		}
+		query, warning, err := fulltextQuery(tokens[searchOpen+1 : searchClose])
+		if err != nil {
+			return nil, nil, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		document := fulltextDocument(columns)
+
+		replacement := "ts_rank(" + document + ", " + query + ")"
+		if isSearchCondition(out, tokens, searchClose) {
+			replacement = "(" + document + " @@ " + query + ")"
+		}
+		out = append(out, pgTokens(replacement)...)
+		i = searchClose
+	}
+	return out, warnings, nil
+}
+
+// isSearchCondition reports whether the MATCH whose AGAINST closes at end
+// is a condition: it follows WHERE, AND and the like, possibly inside
+// parentheses, and its relevance is not compared with anything
+func isSearchCondition(out, tokens []Token, end int) bool {
+	if next := nextSignificant(tokens, end+1); next < len(tokens) &&
+		(tokens[next].Kind == TokOperator && comparisonOps[tokens[next].Text] || tokens[next].Is("BETWEEN")) {
+		return false
+	}
+	prev := prevSignificant(out, len(out))
+	for prev >= 0 && out[prev].IsOp("(") {
+		prev = prevSignificant(out, prev)
+	}
+	return prev >= 0 && conditionWords[out[prev].Upper()]
+}
+
+// fulltextQuery converts the contents of AGAINST (...) to a tsquery. Literal
+// search strings are converted here; any other expression is only known
+// when the query runs and is read with websearch_to_tsquery, which accepts
+// any input and understands "phrase", -word and or. That differs from
+// MySQL's boolean mode too much to stand in for it. Query expansion has no
+// counterpart and is searched without, with a warning.
+func fulltextQuery(inner []Token) (string, string, error) {
+	search := trimTrivia(inner)
+	boolean := false
+	warning := ""
+	if mode := findTopLevel(search, 0, "IN", "WITH"); mode >= 0 {
+		var words []string
+		for _, tok := range search[mode:] {
+			if !tok.IsTrivia() {
+				words = append(words, tok.Upper())
+			}
+		}
+		switch strings.Join(words, " ") {
+		case "IN BOOLEAN MODE":
+			boolean = true
+		case "IN NATURAL LANGUAGE MODE":
+		case "IN NATURAL LANGUAGE MODE WITH QUERY EXPANSION", "WITH QUERY EXPANSION":
+			warning = "WITH QUERY EXPANSION was ignored: PostgreSQL searches for the given words only"
+		default:
+			return "", "", fmt.Errorf("syntax error: unknown search modifier '%s'", renderTrimmed(search[mode:]))
+		}
+		search = trimTrivia(search[:mode])
+	}
+	if len(search) == 0 {
+		return "", "", fmt.Errorf("syntax error: AGAINST needs a search string")
+	}
+
+	if len(search) != 1 || search[0].Kind != TokString {
+		if boolean {
+			return "", "", fmt.Errorf("IN BOOLEAN MODE needs a literal search string on PostgreSQL, '%s' is only known when the query runs", renderTrimmed(search))
+		}
+		return "websearch_to_tsquery(" + fulltextConfig + ", " + render(search) + ")", warning, nil
+	}
+	var query string
+	if boolean {
+		query = (&booleanSearch{text: []rune(search[0].Value)}).group()
+	} else {
+		query = naturalSearch(search[0].Value)
+	}
+	return "to_tsquery(" + fulltextConfig + ", " + quoteLiteral(query) + ")", warning, nil
+}
+
+// isWordRune reports whether r belongs to a word, as MySQL's parser splits them
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// phrase renders a run of text as its words in sequence, or "" if it has none
+func phrase(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) })
+	if len(words) > 1 {
+		return "(" + strings.Join(words, " <-> ") + ")"
+	}
+	return strings.Join(words, "")
+}
+
+// naturalSearch converts a natural language search string, in which rows
+// match if they contain any of the words or "quoted phrases"
+func naturalSearch(text string) string {
+	var terms []string
+	for i, part := range strings.Split(text, `"`) {
+		if i%2 == 1 {
+			if term := phrase(part); term != "" {
+				terms = append(terms, term)
+			}
+			continue
+		}
+		terms = append(terms, strings.FieldsFunc(part, func(r rune) bool { return !isWordRune(r) })...)
+	}
+	return strings.Join(terms, " | ")
+}
+
+// booleanSearch parses a MySQL boolean mode search string
+type booleanSearch struct {
+	text []rune
+	pos  int
+}
+
+// group parses terms up to a closing parenthesis or the end. A row matches
+// when it has every +term, or, without any, at least one plain term, and
+// none of the -terms. ~, < and > only change the relevance in MySQL and are
+// treated as plain terms.
+func (b *booleanSearch) group() string {
+	var required, optional, excluded []string
+	for b.pos < len(b.text) {
+		r := b.text[b.pos]
+		switch {
+		case r == ')':
+			b.pos++
+			return combineTerms(required, optional, excluded)
+		case r == '+' || r == '-' || r == '~' || r == '<' || r == '>':
+			b.pos++
+			term := b.operand()
+			if term == "" {
+				continue
+			}
+			switch r {
+			case '+':
+				required = append(required, term)
+			case '-':
+				excluded = append(excluded, "!"+term)
+			default:
+				optional = append(optional, term)
+			}
+		default:
+			start := b.pos
+			if term := b.operand(); term != "" {
+				optional = append(optional, term)
+			} else if b.pos == start {
+				b.pos++ // Punctuation between terms
+			}
+		}
+	}
+	return combineTerms(required, optional, excluded)
+}
+
+// operand parses a word with an optional trailing *, a "phrase" or a
+// parenthesized group at the current position
+func (b *booleanSearch) operand() string {
+	if b.pos >= len(b.text) {
+		return ""
+	}
+	switch r := b.text[b.pos]; {
+	case r == '(':
+		b.pos++
+		if group := b.group(); group != "" {
+			return "(" + group + ")"
+		}
+		return ""
+	case r == '"':
+		end := b.pos + 1
+		for end < len(b.text) && b.text[end] != '"' {
+			end++
+		}
+		text := string(b.text[b.pos+1 : end])
+		b.pos = min(end+1, len(b.text))
+		return phrase(text)
+	case isWordRune(r):
+		start := b.pos
+		for b.pos < len(b.text) && isWordRune(b.text[b.pos]) {
+			b.pos++
+		}
+		word := string(b.text[start:b.pos])
+		if b.pos < len(b.text) && b.text[b.pos] == '*' {
+			b.pos++
+			word += ":*"
+		}
+		return word
+	}
+	return ""
+}
+
+// combineTerms joins the terms of one group into tsquery syntax. A group
+// with nothing to match, such as one made only of -terms, is empty, as
+// MySQL returns no rows for it.
+func combineTerms(required, optional, excluded []string) string {
+	terms := required
+	if len(terms) == 0 && len(optional) > 0 {
+		either := strings.Join(optional, " | ")
+		if len(optional) > 1 && len(excluded) > 0 {
+			either = "(" + either + ")"
+		}
+		terms = []string{either}
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return strings.Join(append(terms, excluded...), " & ")
+}
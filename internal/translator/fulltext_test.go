@@ -0,0 +1,120 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateFulltext(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"SELECT id FROM posts WHERE MATCH(title, body) AGAINST('foo' IN BOOLEAN MODE)",
+			"SELECT id FROM posts WHERE (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, '')) @@ to_tsquery('simple', 'foo'))",
+		},
+		{
+			"SELECT id FROM posts WHERE MATCH (body) AGAINST ('+mysql -oracle \"full text\" data*' IN BOOLEAN MODE)",
+			"SELECT id FROM posts WHERE (to_tsvector('simple', coalesce(body, '')) @@ to_tsquery('simple', 'mysql & !oracle'))",
+		},
+		{
+			"SELECT id FROM posts WHERE MATCH(body) AGAINST('apple banana -pie' IN BOOLEAN MODE)",
+			"SELECT id FROM posts WHERE (to_tsvector('simple', coalesce(body, '')) @@ to_tsquery('simple', '(apple | banana) & !pie'))",
+		},
+		{
+			"SELECT id FROM posts WHERE MATCH(body) AGAINST('+apple +(>turnover <strudel) -\"apple pie\"' IN BOOLEAN MODE)",
+			"SELECT id FROM posts WHERE (to_tsvector('simple', coalesce(body, '')) @@ to_tsquery('simple', 'apple & (turnover | strudel) & !(apple <-> pie)'))",
+		},
+		{
+			"SELECT id FROM posts WHERE MATCH(body) AGAINST('data* ~noise' IN BOOLEAN MODE)",
+			"SELECT id FROM posts WHERE (to_tsvector('simple', coalesce(body, '')) @@ to_tsquery('simple', 'data:* | noise'))",
+		},
+		{
+			"SELECT id, MATCH(title, body) AGAINST('database tuning') AS score FROM posts WHERE MATCH(title, body) AGAINST('database tuning') ORDER BY score DESC",
+			"SELECT id, ts_rank(to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, '')), to_tsquery('simple', 'database | tuning')) AS score " +
+				"FROM posts WHERE (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, '')) @@ to_tsquery('simple', 'database | tuning')) ORDER BY score DESC",
+		},
+		{
+			"SELECT id FROM posts p WHERE p.id > 1 AND MATCH(p.body) AGAINST('it''s \"well done\"' IN NATURAL LANGUAGE MODE) > 0.1",
+			"SELECT id FROM posts p WHERE p.id > 1 AND ts_rank(to_tsvector('simple', coalesce(p.body, '')), to_tsquery('simple', 'it | s | (well <-> done)')) > 0.1",
+		},
+		{
+			"SELECT id FROM posts WHERE MATCH(body) AGAINST(?)",
+			"SELECT id FROM posts WHERE (to_tsvector('simple', coalesce(body, '')) @@ websearch_to_tsquery('simple', $1))",
+		},
+		{
+			"SELECT id FROM posts ORDER BY MATCH(body) AGAINST('x' WITH QUERY EXPANSION) DESC",
+			"SELECT id FROM posts ORDER BY ts_rank(to_tsvector('simple', coalesce(body, '')), to_tsquery('simple', 'x')) DESC",
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.expected {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.expected)
+		}
+	}
+
+	result, err := tr.Translate("SELECT id FROM posts WHERE MATCH(body) AGAINST('x' IN NATURAL LANGUAGE MODE WITH QUERY EXPANSION)")
+	if err != nil || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "WITH QUERY EXPANSION") {
+		t.Errorf("query expansion: got %+v, %v", result, err)
+	}
+	// Boolean operators are translated from the search string, which must be known up front
+	if _, err := tr.Translate("SELECT id FROM posts WHERE MATCH(body) AGAINST(? IN BOOLEAN MODE)"); err == nil {
+		t.Errorf("expected an error for a boolean search with a parameter")
+	}
+}
+
+func TestTranslateFulltextIndex(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{
+			"CREATE TABLE posts (id INT PRIMARY KEY, title VARCHAR(200), body TEXT, FULLTEXT KEY ft (title, body))",
+			[]string{
+				"CREATE TABLE posts (\n  id integer PRIMARY KEY,\n  title varchar(200),\n  body text\n)",
+				"CREATE INDEX posts_ft ON posts USING gin ((to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, ''))))",
+			},
+		},
+		{
+			"CREATE FULLTEXT INDEX ft_body ON posts (body) WITH PARSER ngram",
+			[]string{"CREATE INDEX posts_ft_body ON posts USING gin ((to_tsvector('simple', coalesce(body, ''))))"},
+		},
+		{
+			"ALTER TABLE posts ADD FULLTEXT (title)",
+			[]string{"CREATE INDEX ON posts USING gin ((to_tsvector('simple', coalesce(title, ''))))"},
+		},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		got := result.Statements
+		if got == nil {
+			got = []string{result.Query}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("for %s: got %q", tt.input, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package translator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// funcCall is a MySQL function call found in a token stream. Its arguments
+// have already been rewritten, so nested calls are translated inside out.
+type funcCall struct {
+	Name  string    // Upper-cased function name
+	Inner []Token   // Tokens between the parentheses
+	Args  [][]Token // Inner split at top-level commas, trimmed
+}
+
+// arg renders the i-th argument
+func (c *funcCall) arg(i int) string {
+	return render(c.Args[i])
+}
+
+// argCount fails unless the call has between min and max arguments
+func (c *funcCall) argCount(min, max int) error {
+	if n := len(c.Args); n < min || n > max {
+		return paramCountError(c.Name)
+	}
+	return nil
+}
+
+func paramCountError(name string) error {
+	return fmt.Errorf("incorrect parameter count in the call to native function '%s'", name)
+}
+
+// funcRewrite produces the PostgreSQL text replacing a call
+type funcRewrite func(c *funcCall) (string, error)
+
+// functionRewrites maps MySQL functions without a same-named PostgreSQL
+// equivalent to their translation. Functions both databases share, such as
+// CONCAT_WS, COALESCE or REPLACE, are left alone.
+var functionRewrites = map[string]funcRewrite{
+	"IFNULL":          rewriteIfNull,
+	"IF":              rewriteIf,
+	"GROUP_CONCAT":    rewriteGroupConcat,
+	"LOCATE":          rewriteLocate,
+	"INSTR":           rewriteInstr,
+	"SUBSTRING_INDEX": rewriteSubstringIndex,
+	"FIND_IN_SET":     rewriteFindInSet,
+	"FIELD":           rewriteField,
+	"LCASE":           renameFunction("lower", 1, 1),
+	"UCASE":           renameFunction("upper", 1, 1),
+	"MID":             renameFunction("substr", 2, 3),
+
+	"DATE_FORMAT":    rewriteDateFormat,
+	"STR_TO_DATE":    rewriteStrToDate,
+	"UNIX_TIMESTAMP": rewriteUnixTimestamp,
+	"FROM_UNIXTIME":  rewriteFromUnixtime,
+	"DATE_ADD":       dateArithmetic("+"),
+	"ADDDATE":        dateArithmetic("+"),
+	"DATE_SUB":       dateArithmetic("-"),
+	"SUBDATE":        dateArithmetic("-"),
+	"DATEDIFF":       rewriteDateDiff,
+	"TIMESTAMPDIFF":  rewriteTimestampDiff,
+	"LAST_DAY":       rewriteLastDay,
+	"CURDATE":        replaceFunction("CURRENT_DATE"),
+	"CURRENT_DATE":   replaceFunction("CURRENT_DATE"),
+	"CURTIME":        replaceFunction("LOCALTIME(0)"),
+	"CURRENT_TIME":   replaceFunction("LOCALTIME(0)"),
+	"SYSDATE":        replaceFunction("clock_timestamp()::timestamp(0)"),
+	"UTC_DATE":       replaceFunction("(now() AT TIME ZONE 'UTC')::date"),
+	"UTC_TIMESTAMP":  replaceFunction("(now() AT TIME ZONE 'UTC')::timestamp(0)"),
+	"YEAR":           extractFunction("year", ""),
+	"QUARTER":        extractFunction("quarter", ""),
+	"MONTH":          extractFunction("month", ""),
+	"DAY":            extractFunction("day", ""),
+	"DAYOFMONTH":     extractFunction("day", ""),
+	"DAYOFYEAR":      extractFunction("doy", ""),
+	"DAYOFWEEK":      extractFunction("dow", " + 1"),
+	"WEEKDAY":        extractFunction("isodow", " - 1"),
+	"HOUR":           extractFunction("hour", ""),
+	"MINUTE":         extractFunction("minute", ""),
+	"SECOND":         extractFunction("second", ""),
+
+	"JSON_EXTRACT":       rewriteJSONExtract,
+	"JSON_UNQUOTE":       rewriteJSONUnquote,
+	"JSON_CONTAINS":      rewriteJSONContains,
+	"JSON_CONTAINS_PATH": rewriteJSONContainsPath,
+	"JSON_OBJECT":        renameFunction("jsonb_build_object", 0, -1),
+	"JSON_ARRAY":         renameFunction("jsonb_build_array", 0, -1),
+	"JSON_ARRAYAGG":      renameFunction("jsonb_agg", 1, 1),
+	"JSON_OBJECTAGG":     renameFunction("jsonb_object_agg", 2, 2),
+
+	"GET_LOCK":     rewriteGetLock,
+	"RELEASE_LOCK": rewriteReleaseLock,
+	"IS_FREE_LOCK": rewriteIsFreeLock,
+	"IS_USED_LOCK": rewriteIsUsedLock,
+
+	"CAST":        rewriteCast,
+	"CONVERT":     rewriteConvert,
+	"REGEXP_LIKE": rewriteRegexpLike,
+}
+
+// definitionKeywords precede names that are declared rather than called,
+// e.g. CREATE FUNCTION field(...) or INSERT INTO field(a)
+var definitionKeywords = map[string]bool{
+	"FUNCTION": true, "PROCEDURE": true, "TABLE": true, "INTO": true, "TRIGGER": true,
+	"REFERENCES": true, "INDEX": true, "KEY": true, "VIEW": true, "EXISTS": true,
+}
+
+// rewriteFunctions translates MySQL function calls at any nesting depth.
+// Like MySQL itself, a name only counts as a built-in call when the
+// parenthesis follows it directly.
+func rewriteFunctions(tokens []Token) ([]Token, error) {
+	var out []Token
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		rewrite, ok := functionRewrites[tok.Upper()]
+		if !ok || !isFunctionCall(tokens, i) {
+			out = append(out, tok)
+			continue
+		}
+		end := matchParen(tokens, i+1)
+		if end < 0 {
+			// Leave unbalanced input for PostgreSQL to report
+			out = append(out, tok)
+			continue
+		}
+		if tok.Is("IF") {
+			// IF (cond) THEN inside a routine body is a statement, not a call
+			if then := nextSignificant(tokens, end+1); then < len(tokens) && tokens[then].Is("THEN") {
+				out = append(out, tok)
+				continue
+			}
+		}
+
+		inner, err := rewriteFunctions(tokens[i+2 : end])
+		if err != nil {
+			return nil, err
+		}
+		call := &funcCall{Name: tok.Upper(), Inner: inner}
+		if len(trimTrivia(inner)) > 0 {
+			for _, arg := range splitTopLevel(inner, ",") {
+				call.Args = append(call.Args, trimTrivia(arg))
+			}
+		}
+		replacement, err := rewrite(call)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pgTokens(replacement)...)
+		i = end
+	}
+	return out, nil
+}
+
+// isFunctionCall reports whether the identifier at i starts a function call
+func isFunctionCall(tokens []Token, i int) bool {
+	if tokens[i].Kind != TokIdent || i+1 >= len(tokens) || !tokens[i+1].IsOp("(") {
+		return false
+	}
+	if prev := prevSignificant(tokens, i); prev >= 0 {
+		if tokens[prev].IsOp(".") || definitionKeywords[tokens[prev].Upper()] {
+			return false
+		}
+	}
+	return true
+}
+
+// renameFunction maps a function onto a PostgreSQL function with the same
+// arguments. A negative max allows any number of arguments.
+func renameFunction(name string, min, max int) funcRewrite {
+	return func(c *funcCall) (string, error) {
+		limit := max
+		if limit < 0 {
+			limit = len(c.Args)
+		}
+		if err := c.argCount(min, limit); err != nil {
+			return "", err
+		}
+		return name + "(" + render(c.Inner) + ")", nil
+	}
+}
+
+// IFNULL(a, b) -> COALESCE(a, b)
+func rewriteIfNull(c *funcCall) (string, error) {
+	if err := c.argCount(2, 2); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("COALESCE(%s, %s)", c.arg(0), c.arg(1)), nil
+}
+
+// IF(cond, a, b) -> CASE WHEN cond THEN a ELSE b END
+func rewriteIf(c *funcCall) (string, error) {
+	if err := c.argCount(3, 3); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("CASE WHEN %s THEN %s ELSE %s END", c.arg(0), c.arg(1), c.arg(2)), nil
+}
+
+// GROUP_CONCAT([DISTINCT] expr [, expr] [ORDER BY ...] [SEPARATOR 'sep'])
+//
+//	-> string_agg([DISTINCT] expr::text, 'sep' [ORDER BY ...])
+//
+// Several expressions are joined with ||, so rows with a NULL part are
+// skipped as in MySQL.
+func rewriteGroupConcat(c *funcCall) (string, error) {
+	tokens := trimTrivia(c.Inner)
+	distinct := false
+	if len(tokens) > 0 && tokens[0].Is("DISTINCT") {
+		distinct = true
+		tokens = trimTrivia(tokens[1:])
+	}
+
+	separator := "','"
+	if sep := findTopLevel(tokens, 0, "SEPARATOR"); sep >= 0 {
+		separator = renderTrimmed(tokens[sep+1:])
+		tokens = trimTrivia(tokens[:sep])
+	}
+	var orderBy []Token
+	if order := findTopLevel(tokens, 0, "ORDER"); order >= 0 {
+		orderBy = trimTrivia(tokens[order:])
+		tokens = trimTrivia(tokens[:order])
+	}
+	if len(tokens) == 0 {
+		return "", paramCountError(c.Name)
+	}
+
+	var parts []string
+	for _, expr := range splitTopLevel(tokens, ",") {
+		parts = append(parts, castExpr(trimTrivia(expr), "text"))
+	}
+	value := strings.Join(parts, " || ")
+
+	query := "string_agg("
+	if distinct {
+		query += "DISTINCT "
+	}
+	query += value + ", " + separator
+	if orderBy != nil {
+		query += " " + groupConcatOrder(orderBy, tokens, value, distinct)
+	}
+	return query + ")", nil
+}
+
+// groupConcatOrder renders the ORDER BY of a GROUP_CONCAT. With DISTINCT,
+// PostgreSQL requires the sort key to be the aggregated expression itself,
+// so ordering by the concatenated column is ordered by its text cast.
+func groupConcatOrder(orderBy, exprs []Token, value string, distinct bool) string {
+	if !distinct {
+		return render(orderBy)
+	}
+	by := nextSignificant(orderBy, 1)
+	key := trimTrivia(orderBy[by+1:])
+	direction := ""
+	if n := len(key); n > 0 && (key[n-1].Is("ASC") || key[n-1].Is("DESC")) {
+		direction = " " + key[n-1].Upper()
+		key = trimTrivia(key[:n-1])
+	}
+	if render(key) == renderTrimmed(exprs) {
+		return "ORDER BY " + value + direction
+	}
+	return render(orderBy)
+}
+
+// castExpr casts an expression to a PostgreSQL type
+func castExpr(expr []Token, typ string) string {
+	if len(expr) == 1 {
+		return render(expr) + "::" + typ
+	}
+	return "(" + render(expr) + ")::" + typ
+}
+
+// LOCATE(substr, str [, pos]) -> strpos(str, substr)
+func rewriteLocate(c *funcCall) (string, error) {
+	if err := c.argCount(2, 3); err != nil {
+		return "", err
+	}
+	if len(c.Args) == 2 {
+		return fmt.Sprintf("strpos(%s, %s)", c.arg(1), c.arg(0)), nil
+	}
+	found := fmt.Sprintf("strpos(substr(%s, %s), %s)", c.arg(1), c.arg(2), c.arg(0))
+	return fmt.Sprintf("CASE WHEN %s = 0 THEN 0 ELSE %s + %s - 1 END", found, found, c.arg(2)), nil
+}
+
+// INSTR(str, substr) -> strpos(str, substr)
+func rewriteInstr(c *funcCall) (string, error) {
+	if err := c.argCount(2, 2); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("strpos(%s, %s)", c.arg(0), c.arg(1)), nil
+}
+
+// SUBSTRING_INDEX(str, delim, count) returns the part before the count-th
+// delimiter, or after the count-th delimiter from the right when count is
+// negative:
+//
+//	SUBSTRING_INDEX(s, d, 1)  -> split_part(s, d, 1)
+//	SUBSTRING_INDEX(s, d, n)  -> array_to_string((string_to_array(s, d))[1:n], d)
+//	SUBSTRING_INDEX(s, d, -n) -> the same slice counted from the end
+func rewriteSubstringIndex(c *funcCall) (string, error) {
+	if err := c.argCount(3, 3); err != nil {
+		return "", err
+	}
+	str, delim, count := c.arg(0), c.arg(1), c.arg(2)
+	parts := fmt.Sprintf("string_to_array(%s, %s)", str, delim)
+	head := func(n string) string {
+		return fmt.Sprintf("array_to_string((%s)[1:%s], %s)", parts, n, delim)
+	}
+	tail := func(n string) string {
+		return fmt.Sprintf("array_to_string((%s)[cardinality(%s) - %s + 1:], %s)", parts, parts, n, delim)
+	}
+
+	n, err := strconv.Atoi(strings.ReplaceAll(count, " ", ""))
+	switch {
+	case err != nil:
+		// Count known only at run time
+		return fmt.Sprintf("CASE WHEN %s >= 0 THEN %s ELSE %s END", count, head(count), tail("-("+count+")")), nil
+	case n == 1:
+		return fmt.Sprintf("split_part(%s, %s, 1)", str, delim), nil
+	case n >= 0:
+		return head(strconv.Itoa(n)), nil
+	default:
+		return tail(strconv.Itoa(-n)), nil
+	}
+}
+
+// FIND_IN_SET(str, list) -> CASE WHEN str IS NULL OR list IS NULL THEN NULL
+// ELSE COALESCE(array_position(string_to_array(list, ','), str), 0) END
+func rewriteFindInSet(c *funcCall) (string, error) {
+	if err := c.argCount(2, 2); err != nil {
+		return "", err
+	}
+	str, list := c.arg(0), c.arg(1)
+	return fmt.Sprintf("CASE WHEN %s IS NULL OR %s IS NULL THEN NULL ELSE COALESCE(array_position(string_to_array(%s, ','), %s), 0) END",
+		str, list, list, str), nil
+}
+
+// FIELD(str, a, b, ...) -> CASE str WHEN a THEN 1 WHEN b THEN 2 ... ELSE 0 END
+func rewriteField(c *funcCall) (string, error) {
+	if len(c.Args) < 2 {
+		return "", paramCountError(c.Name)
+	}
+	var b strings.Builder
+	b.WriteString("CASE " + c.arg(0))
+	for i := 1; i < len(c.Args); i++ {
+		fmt.Fprintf(&b, " WHEN %s THEN %d", c.arg(i), i)
+	}
+	b.WriteString(" ELSE 0 END")
+	return b.String(), nil
+}
@@ -0,0 +1,91 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateStringFunctions(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT IFNULL(nickname, name) FROM users", "SELECT COALESCE(nickname, name) FROM users"},
+		{"SELECT LCASE(name), UCASE(code) FROM t", "SELECT lower(name), upper(code) FROM t"},
+		{"SELECT IF(qty > 0, 'in stock', 'sold out') FROM items", "SELECT CASE WHEN qty > 0 THEN 'in stock' ELSE 'sold out' END FROM items"},
+		{"SELECT LOCATE('@', email) FROM users", "SELECT strpos(email, '@') FROM users"},
+		{
+			"SELECT LOCATE('b', s, 3) FROM t",
+			"SELECT CASE WHEN strpos(substr(s, 3), 'b') = 0 THEN 0 ELSE strpos(substr(s, 3), 'b') + 3 - 1 END FROM t",
+		},
+		{"SELECT SUBSTRING_INDEX(email, '@', 1) FROM users", "SELECT split_part(email, '@', 1) FROM users"},
+		{
+			"SELECT SUBSTRING_INDEX(host, '.', 2) FROM t",
+			"SELECT array_to_string((string_to_array(host, '.'))[1:2], '.') FROM t",
+		},
+		{
+			"SELECT SUBSTRING_INDEX(host, '.', -1) FROM t",
+			"SELECT array_to_string((string_to_array(host, '.'))[cardinality(string_to_array(host, '.')) - 1 + 1:], '.') FROM t",
+		},
+		{
+			"SELECT * FROM t WHERE FIND_IN_SET('red', tags)",
+			"SELECT * FROM t WHERE CASE WHEN 'red' IS NULL OR tags IS NULL THEN NULL ELSE COALESCE(array_position(string_to_array(tags, ','), 'red'), 0) END",
+		},
+		{
+			"SELECT FIND_IN_SET(NULL, 'a,b')",
+			"SELECT CASE WHEN NULL IS NULL OR 'a,b' IS NULL THEN NULL ELSE COALESCE(array_position(string_to_array('a,b', ','), NULL), 0) END",
+		},
+		{
+			"SELECT * FROM t ORDER BY FIELD(status, 'new', 'open', 'closed')",
+			"SELECT * FROM t ORDER BY CASE status WHEN 'new' THEN 1 WHEN 'open' THEN 2 WHEN 'closed' THEN 3 ELSE 0 END",
+		},
+		{"SELECT CONCAT_WS('-', a, b) FROM t", "SELECT CONCAT_WS('-', a, b) FROM t"},
+		{
+			"SELECT dept, GROUP_CONCAT(name) FROM emp GROUP BY dept",
+			"SELECT dept, string_agg(name::text, ',') FROM emp GROUP BY dept",
+		},
+		{
+			"SELECT GROUP_CONCAT(DISTINCT name ORDER BY name DESC SEPARATOR '; ') FROM emp",
+			"SELECT string_agg(DISTINCT name::text, '; ' ORDER BY name::text DESC) FROM emp",
+		},
+		{
+			"SELECT GROUP_CONCAT(first, ' ', last ORDER BY id SEPARATOR \"\\n\") FROM emp",
+			"SELECT string_agg(first::text || ' '::text || last::text, E'\\n' ORDER BY id) FROM emp",
+		},
+		{
+			"SELECT UCASE(IFNULL(SUBSTRING_INDEX(IF(a, b, c), ',', 1), 'x')) FROM t",
+			"SELECT upper(COALESCE(split_part(CASE WHEN a THEN b ELSE c END, ',', 1), 'x')) FROM t",
+		},
+		{
+			"UPDATE t SET name = IFNULL(name, '') WHERE id IN (SELECT id FROM u WHERE LCASE(x) = 'y')",
+			"UPDATE t SET name = COALESCE(name, '') WHERE id IN (SELECT id FROM u WHERE lower(x) = 'y')",
+		},
+		{"SELECT s.field(1), `if`(2), lcase (x) FROM t", "SELECT s.field(1), \"if\"(2), lcase (x) FROM t"},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.want)
+		}
+	}
+}
+
+func TestTranslateFunctionArgumentCount(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	for _, input := range []string{"SELECT IFNULL(a)", "SELECT IF(a, b)", "SELECT FIELD(a)", "SELECT GROUP_CONCAT()"} {
+		_, err := tr.Translate(input)
+		if err == nil || !strings.Contains(err.Error(), "incorrect parameter count") {
+			t.Errorf("for %s: expected parameter count error, got %v", input, err)
+		}
+	}
+}
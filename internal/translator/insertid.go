@@ -0,0 +1,102 @@
+package translator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// insertIDColumn returns the table's auto-increment column if the INSERT
+// lets a sequence fill it in, for the INSERT to return the generated keys,
+// from which the client takes the value of LAST_INSERT_ID(). The sequence
+// fills it in when the column is left out, or given as DEFAULT or NULL in
+// every row; NULL is turned into DEFAULT, which PostgreSQL needs for that.
+// Inserts that give the column a value leave LAST_INSERT_ID() unchanged in
+// MySQL and return nothing, as do upserts, which may update existing rows
+// instead. Inside a stored routine nothing can receive the keys.
+func (t *Translator) insertIDColumn(s *InsertStmt) (string, error) {
+	if t.catalog == nil || findTopLevel(s.Source, 0, "RETURNING") >= 0 {
+		return "", nil
+	}
+	table := render(s.Table)
+	key, err := t.catalog.AutoIncrementColumn(table)
+	if err != nil || key == "" {
+		return "", err
+	}
+	var columns []string
+	for _, col := range s.Columns {
+		columns = append(columns, catalogName(col))
+	}
+	if s.Columns == nil {
+		if columns, err = t.catalog.Columns(table); err != nil {
+			return "", err
+		}
+	}
+
+	generated := true
+	for position, col := range columns {
+		if col == key {
+			generated = defaultKeys(s.Source, position)
+		}
+	}
+	if !generated || t.routine || s.OnDuplicate != nil {
+		return "", nil
+	}
+	return key, nil
+}
+
+// defaultKeys turns NULL into DEFAULT at the position of each row of a
+// VALUES list and reports whether every row leaves the value to its default
+func defaultKeys(source []Token, position int) bool {
+	if len(source) == 0 || !source[0].Is("VALUES") {
+		return false
+	}
+	all := true
+	for _, row := range splitTopLevel(source[1:], ",") {
+		row = trimTrivia(row)
+		if len(row) == 0 || !row[0].IsOp("(") || matchParen(row, 0) != len(row)-1 {
+			return false
+		}
+		values := splitTopLevel(row[1:len(row)-1], ",")
+		if position >= len(values) {
+			return false
+		}
+		switch value := trimTrivia(values[position]); {
+		case len(value) == 1 && value[0].Is("NULL"):
+			value[0] = pgTokens("DEFAULT")[0]
+		case len(value) == 1 && value[0].Is("DEFAULT"):
+		default:
+			all = false
+		}
+	}
+	return all
+}
+
+// rewriteLastInsertID replaces LAST_INSERT_ID() with the first key the
+// session's latest INSERT generated, or 0 before any. Routine bodies use
+// lastval() instead.
+func (t *Translator) rewriteLastInsertID(tokens []Token) ([]Token, error) {
+	var out []Token
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if !tok.Is("LAST_INSERT_ID") || !isFunctionCall(tokens, i) {
+			out = append(out, tok)
+			continue
+		}
+		end := matchParen(tokens, i+1)
+		if end < 0 {
+			out = append(out, tok)
+			continue
+		}
+		if len(trimTrivia(tokens[i+2:end])) > 0 {
+			return nil, fmt.Errorf("LAST_INSERT_ID(expr) is not supported on PostgreSQL, use a sequence and nextval() instead")
+		}
+		if t.routine {
+			// A routine runs on the server, where lastval() sees its inserts
+			out = append(out, pgTokens("lastval()")...)
+		} else {
+			out = append(out, pgTokens(strconv.FormatInt(t.lastInsertID, 10))...)
+		}
+		i = end
+	}
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateInsertID(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{
+		columns:       map[string][]string{"posts": {"id", "title"}, "tags": {"name"}},
+		autoIncrement: map[string]string{"posts": "id"},
+	})
+
+	tests := []struct {
+		input      string
+		want       string
+		returnsIDs bool
+	}{
+		{"INSERT INTO posts (title) VALUES ('a'), ('b')", "INSERT INTO posts (title) VALUES ('a'), ('b') RETURNING id", true},
+		{"INSERT INTO posts SET title = 'a'", "INSERT INTO posts (title) VALUES ('a') RETURNING id", true},
+		{"INSERT IGNORE INTO posts (title) SELECT name FROM tags", "INSERT INTO posts (title) SELECT name FROM tags ON CONFLICT DO NOTHING RETURNING id", true},
+		// Without a column list the key is filled in when given as DEFAULT or NULL
+		{"INSERT INTO posts VALUES (DEFAULT, 'a')", "INSERT INTO posts VALUES (DEFAULT, 'a') RETURNING id", true},
+		{"INSERT INTO posts VALUES (NULL, 'a'), (NULL, 'b')", "INSERT INTO posts VALUES (DEFAULT, 'a'), (DEFAULT, 'b') RETURNING id", true},
+		{"INSERT INTO posts (id, title) VALUES (null, 'a')", "INSERT INTO posts (id, title) VALUES (DEFAULT, 'a') RETURNING id", true},
+		{"INSERT INTO posts SELECT * FROM posts", "INSERT INTO posts SELECT * FROM posts", false},
+		// The key is given, so MySQL would not change LAST_INSERT_ID()
+		{"INSERT INTO posts (id, title) VALUES (7, 'a')", "INSERT INTO posts (id, title) VALUES (7, 'a')", false},
+		{"INSERT INTO posts VALUES (7, 'a'), (NULL, 'b')", "INSERT INTO posts VALUES (7, 'a'), (DEFAULT, 'b')", false},
+		{"INSERT INTO tags (name) VALUES ('x')", "INSERT INTO tags (name) VALUES ('x')", false},
+		{"SELECT LAST_INSERT_ID()", "SELECT 0", false},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want || result.ReturnsInsertID != tt.returnsIDs {
+			t.Errorf("for %s:\n got: %s (returns ids %v)\nwant: %s (returns ids %v)", tt.input, result.Query, result.ReturnsInsertID, tt.want, tt.returnsIDs)
+		}
+	}
+
+	tr.SetLastInsertID(41)
+	result, err := tr.Translate("SELECT * FROM posts WHERE id = last_insert_id()")
+	if err != nil || result.Query != "SELECT * FROM posts WHERE id = 41" {
+		t.Errorf("got %v, %v", result, err)
+	}
+	if _, err := tr.Translate("UPDATE seq SET id = LAST_INSERT_ID(id + 1)"); err == nil || !strings.Contains(err.Error(), "LAST_INSERT_ID(expr)") {
+		t.Errorf("LAST_INSERT_ID(expr): got error %v", err)
+	}
+}
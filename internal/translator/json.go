@@ -0,0 +1,251 @@
+package translator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPath converts a MySQL JSON path such as $.a.b[0] to the element list
+// used by the jsonb #> and #>> operators. [last] maps to PostgreSQL's
+// negative array index.
+func jsonPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSON path '%s': it must start with $", path)
+	}
+	var elems []string
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "**"), strings.HasPrefix(rest, ".*"), strings.HasPrefix(rest, "[*]"):
+			return nil, fmt.Errorf("JSON path wildcards are not supported on PostgreSQL: '%s'", path)
+
+		case strings.HasPrefix(rest, `."`):
+			end := strings.IndexByte(rest[2:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path '%s'", path)
+			}
+			elems = append(elems, rest[2:2+end])
+			rest = rest[3+end:]
+
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := strings.TrimSpace(rest[1 : 1+end])
+			if key == "" {
+				return nil, fmt.Errorf("invalid JSON path '%s'", path)
+			}
+			elems = append(elems, key)
+			rest = rest[1+end:]
+
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path '%s'", path)
+			}
+			index, err := jsonArrayIndex(strings.TrimSpace(rest[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON path '%s': %v", path, err)
+			}
+			elems = append(elems, index)
+			rest = rest[end+1:]
+
+		case rest[0] == ' ':
+			rest = rest[1:]
+
+		default:
+			return nil, fmt.Errorf("invalid JSON path '%s'", path)
+		}
+	}
+	return elems, nil
+}
+
+// jsonArrayIndex converts n, last or last-n
+func jsonArrayIndex(index string) (string, error) {
+	if n, err := strconv.Atoi(index); err == nil && n >= 0 {
+		return index, nil
+	}
+	if index == "last" {
+		return "-1", nil
+	}
+	if offset, ok := strings.CutPrefix(index, "last"); ok {
+		offset = strings.TrimSpace(offset)
+		if n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(offset, "-"))); err == nil && strings.HasPrefix(offset, "-") {
+			return strconv.Itoa(-1 - n), nil
+		}
+	}
+	if strings.Contains(index, " to ") {
+		return "", fmt.Errorf("array ranges are not supported on PostgreSQL")
+	}
+	return "", fmt.Errorf("bad array index '%s'", index)
+}
+
+// pgTextArray renders a PostgreSQL text[] literal such as '{a,b,0}'
+func pgTextArray(elems []string) string {
+	quoted := make([]string, len(elems))
+	for i, elem := range elems {
+		if elem == "" || strings.EqualFold(elem, "null") || strings.ContainsAny(elem, ",{}\"\\ \t\n") {
+			elem = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(elem) + `"`
+		}
+		quoted[i] = elem
+	}
+	return quoteLiteral("{" + strings.Join(quoted, ",") + "}")
+}
+
+// pathArg converts a literal JSON path argument to a text[] literal
+func pathArg(c *funcCall, arg []Token) (string, error) {
+	if len(arg) != 1 || arg[0].Kind != TokString {
+		return "", fmt.Errorf("%s needs a literal JSON path to be translated to PostgreSQL", c.Name)
+	}
+	elems, err := jsonPath(arg[0].Value)
+	if err != nil {
+		return "", err
+	}
+	return pgTextArray(elems), nil
+}
+
+// jsonDoc renders a JSON document argument, casting string literals to jsonb
+func jsonDoc(arg []Token) string {
+	if len(arg) == 1 && arg[0].Kind == TokString {
+		return arg[0].Text + "::jsonb"
+	}
+	if len(arg) == 1 {
+		return render(arg)
+	}
+	return "(" + render(arg) + ")"
+}
+
+// rewriteJSONOperators converts MySQL's column path operators:
+//
+//	col->'$.a.b'  -> col #> '{a,b}'
+//	col->>'$.a'   -> col #>> '{a}'
+//
+// PostgreSQL's own col->'key' form, with no leading $, is left alone.
+func rewriteJSONOperators(tokens []Token) ([]Token, error) {
+	out := make([]Token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Kind != TokOperator || (tok.Text != "->" && tok.Text != "->>") {
+			out = append(out, tok)
+			continue
+		}
+		path := nextSignificant(tokens, i+1)
+		if path >= len(tokens) || tokens[path].Kind != TokString || !strings.HasPrefix(tokens[path].Value, "$") {
+			out = append(out, tok)
+			continue
+		}
+		elems, err := jsonPath(tokens[path].Value)
+		if err != nil {
+			return nil, err
+		}
+		tok.Text = "#>"
+		if tokens[i].Text == "->>" {
+			tok.Text = "#>>"
+		}
+		out = append(out, tok)
+		out = append(out, tokens[i+1:path]...)
+		out = append(out, pgTokens(pgTextArray(elems))...)
+		i = path
+	}
+	return out, nil
+}
+
+// JSON_EXTRACT(doc, path) -> doc #> '{...}'. Several paths collect their
+// matches into an array, as MySQL does.
+func rewriteJSONExtract(c *funcCall) (string, error) {
+	if len(c.Args) < 2 {
+		return "", paramCountError(c.Name)
+	}
+	doc := jsonDoc(c.Args[0])
+	var parts []string
+	for _, arg := range c.Args[1:] {
+		path, err := pathArg(c, arg)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, doc+" #> "+path)
+	}
+	if len(parts) == 1 {
+		return "(" + parts[0] + ")", nil
+	}
+	return "jsonb_build_array(" + strings.Join(parts, ", ") + ")", nil
+}
+
+// JSON_UNQUOTE(JSON_EXTRACT(doc, path)) -> doc #>> '{...}'; anything else is
+// unquoted by extracting the empty path as text
+func rewriteJSONUnquote(c *funcCall) (string, error) {
+	if err := c.argCount(1, 1); err != nil {
+		return "", err
+	}
+	arg := c.Args[0]
+	if len(arg) > 0 && arg[0].IsOp("(") && matchParen(arg, 0) == len(arg)-1 {
+		arg = trimTrivia(arg[1 : len(arg)-1])
+	}
+	var ops []int
+	depth := 0
+	for i, tok := range arg {
+		switch {
+		case tok.IsOp("("):
+			depth++
+		case tok.IsOp(")"):
+			depth--
+		case depth == 0 && tok.Kind == TokOperator && tok.Text != ".":
+			ops = append(ops, i)
+		}
+	}
+	if len(ops) == 1 && arg[ops[0]].Text == "#>" {
+		return "(" + render(arg[:ops[0]]) + "#>>" + render(arg[ops[0]+1:]) + ")", nil
+	}
+	if len(ops) == 1 && arg[ops[0]].Text == "#>>" {
+		return "(" + render(arg) + ")", nil
+	}
+	return fmt.Sprintf("(%s #>> '{}')", castExpr(c.Args[0], "jsonb")), nil
+}
+
+// JSON_CONTAINS(target, candidate [, path]) -> target @> candidate
+func rewriteJSONContains(c *funcCall) (string, error) {
+	if err := c.argCount(2, 3); err != nil {
+		return "", err
+	}
+	target := jsonDoc(c.Args[0])
+	if len(c.Args) == 3 {
+		path, err := pathArg(c, c.Args[2])
+		if err != nil {
+			return "", err
+		}
+		target = "(" + target + " #> " + path + ")"
+	}
+	return fmt.Sprintf("(%s @> %s)", target, jsonDoc(c.Args[1])), nil
+}
+
+// JSON_CONTAINS_PATH(doc, 'one'|'all', path...) -> doc #> path IS NOT NULL joined by OR/AND
+func rewriteJSONContainsPath(c *funcCall) (string, error) {
+	if len(c.Args) < 3 {
+		return "", paramCountError(c.Name)
+	}
+	mode := c.Args[1]
+	if len(mode) != 1 || mode[0].Kind != TokString {
+		return "", fmt.Errorf("%s needs a literal 'one' or 'all' to be translated to PostgreSQL", c.Name)
+	}
+	join := " OR "
+	switch strings.ToLower(mode[0].Value) {
+	case "one":
+	case "all":
+		join = " AND "
+	default:
+		return "", fmt.Errorf("the oneOrAll argument to %s may take these values: 'one' or 'all'", c.Name)
+	}
+
+	doc := jsonDoc(c.Args[0])
+	var checks []string
+	for _, arg := range c.Args[2:] {
+		path, err := pathArg(c, arg)
+		if err != nil {
+			return "", err
+		}
+		checks = append(checks, fmt.Sprintf("%s #> %s IS NOT NULL", doc, path))
+	}
+	return "(" + strings.Join(checks, join) + ")", nil
+}
@@ -0,0 +1,71 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateJSON(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT profile->'$.address.city' FROM users", "SELECT profile#>'{address,city}' FROM users"},
+		{"SELECT profile->>'$.tags[0]' FROM users", "SELECT profile#>>'{tags,0}' FROM users"},
+		{"SELECT profile -> '$.tags[last]' FROM users", "SELECT profile #> '{tags,-1}' FROM users"},
+		{`SELECT attributes->>'$."screen size"' FROM products`, `SELECT attributes#>>'{"screen size"}' FROM products`},
+		{"SELECT profile->'name' FROM users", "SELECT profile->'name' FROM users"},
+		{"SELECT JSON_EXTRACT(profile, '$.age') FROM users", "SELECT (profile #> '{age}') FROM users"},
+		{"SELECT JSON_EXTRACT(profile, '$.a', '$.b') FROM users", "SELECT jsonb_build_array(profile #> '{a}', profile #> '{b}') FROM users"},
+		{"SELECT JSON_UNQUOTE(JSON_EXTRACT(profile, '$.name')) FROM users", "SELECT (profile #>> '{name}') FROM users"},
+		{"SELECT JSON_UNQUOTE(profile->'$.name') FROM users", "SELECT (profile#>>'{name}') FROM users"},
+		{
+			`SELECT * FROM products WHERE JSON_CONTAINS(attributes, '{"color": "red"}')`,
+			`SELECT * FROM products WHERE (attributes @> '{"color": "red"}'::jsonb)`,
+		},
+		{
+			`SELECT * FROM products WHERE JSON_CONTAINS(attributes, '"red"', '$.colors')`,
+			`SELECT * FROM products WHERE ((attributes #> '{colors}') @> '"red"'::jsonb)`,
+		},
+		{
+			"SELECT JSON_CONTAINS_PATH(profile, 'all', '$.a', '$.b') FROM users",
+			"SELECT (profile #> '{a}' IS NOT NULL AND profile #> '{b}' IS NOT NULL) FROM users",
+		},
+		{"SELECT JSON_OBJECT('id', id, 'name', name) FROM users", "SELECT jsonb_build_object('id', id, 'name', name) FROM users"},
+		{"SELECT JSON_ARRAYAGG(JSON_OBJECT('id', id)) FROM users", "SELECT jsonb_agg(jsonb_build_object('id', id)) FROM users"},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.want)
+		}
+	}
+}
+
+func TestTranslateJSONPathErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT profile->'$[*].a' FROM users", "wildcards are not supported"},
+		{"SELECT JSON_EXTRACT(profile, p) FROM users", "needs a literal JSON path"},
+		{"SELECT JSON_EXTRACT(profile, 'a.b') FROM users", "must start with $"},
+	}
+	for _, tt := range tests {
+		_, err := tr.Translate(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("for %s: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+}
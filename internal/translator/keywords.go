@@ -0,0 +1,64 @@
+package translator
+
+import "strings"
+
+// pgReserved lists the PostgreSQL keywords that cannot be used as bare
+// column or table names
+var pgReserved = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "asymmetric": true, "authorization": true, "binary": true,
+	"both": true, "case": true, "cast": true, "check": true, "collate": true, "collation": true,
+	"column": true, "concurrently": true, "constraint": true, "create": true, "cross": true,
+	"current_catalog": true, "current_date": true, "current_role": true, "current_schema": true,
+	"current_time": true, "current_timestamp": true, "current_user": true, "default": true,
+	"deferrable": true, "desc": true, "distinct": true, "do": true, "else": true, "end": true,
+	"except": true, "false": true, "fetch": true, "for": true, "foreign": true, "freeze": true,
+	"from": true, "full": true, "grant": true, "group": true, "having": true, "ilike": true,
+	"in": true, "initially": true, "inner": true, "intersect": true, "into": true, "is": true,
+	"isnull": true, "join": true, "lateral": true, "leading": true, "left": true, "like": true,
+	"limit": true, "localtime": true, "localtimestamp": true, "natural": true, "not": true,
+	"notnull": true, "null": true, "offset": true, "on": true, "only": true, "or": true,
+	"order": true, "outer": true, "overlaps": true, "placing": true, "primary": true,
+	"references": true, "returning": true, "right": true, "select": true, "session_user": true,
+	"similar": true, "some": true, "symmetric": true, "system_user": true, "table": true,
+	"tablesample": true, "then": true, "to": true, "trailing": true, "true": true, "union": true,
+	"unique": true, "user": true, "using": true, "variadic": true, "verbose": true, "when": true,
+	"where": true, "window": true, "with": true,
+}
+
+// exprKeywords are words that can appear inside an expression without
+// naming a column
+var exprKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "XOR": true, "NULL": true, "TRUE": true, "FALSE": true,
+	"UNKNOWN": true, "IS": true, "IN": true, "LIKE": true, "ILIKE": true, "ESCAPE": true,
+	"BETWEEN": true, "CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+	"INTERVAL": true, "DIV": true, "MOD": true, "REGEXP": true, "RLIKE": true, "BINARY": true,
+	"COLLATE": true, "DISTINCT": true, "EXISTS": true, "ANY": true, "ALL": true, "SOME": true,
+	"AS": true, "DEFAULT": true, "SELECT": true, "FROM": true, "WHERE": true,
+	"CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true, "CURRENT_USER": true,
+	"LOCALTIME": true, "LOCALTIMESTAMP": true, "EXCLUDED": true,
+}
+
+// pgIdent renders a bare or catalog name as a PostgreSQL identifier,
+// quoting it only when PostgreSQL would otherwise fold or reject it
+func pgIdent(name string) string {
+	if name == "" || pgReserved[name] {
+		return quoteIdent(name)
+	}
+	for i, r := range name {
+		isLower := r >= 'a' && r <= 'z'
+		if !(isLower || r == '_' || (i > 0 && (r >= '0' && r <= '9' || r == '$'))) {
+			return quoteIdent(name)
+		}
+	}
+	return name
+}
+
+// catalogName returns the name PostgreSQL stores for an identifier token:
+// quoted names keep their case, bare names are folded to lower case
+func catalogName(tok Token) string {
+	if tok.Kind == TokQuotedIdent {
+		return tok.Value
+	}
+	return strings.ToLower(tok.Value)
+}
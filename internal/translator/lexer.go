@@ -0,0 +1,415 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind classifies a lexical token
+type TokenKind int
+
+const (
+	TokSpace          TokenKind = iota // Whitespace
+	TokComment                         // -- comment, # comment or /* comment */
+	TokIdent                           // Bare identifier or keyword
+	TokQuotedIdent                     // `identifier` (MySQL) or "identifier" (PostgreSQL)
+	TokString                          // 'string' literal
+	TokDoubleString                    // "string" literal (MySQL only)
+	TokNumber                          // Numeric literal
+	TokHex                             // X'0A' or 0x0A literal
+	TokBit                             // B'01' or 0b01 literal
+	TokVariable                        // @user_variable
+	TokSystemVariable                  // @@system_variable
+	TokParam                           // ? placeholder (MySQL) or $1 (PostgreSQL)
+	TokOperator                        // Punctuation and operators
+	TokEOF                             // End of input, never produced by the lexer
+)
+
+// Token is a single lexical unit of a statement
+type Token struct {
+	Kind  TokenKind
+	Text  string // Source text exactly as written
+	Value string // Decoded value: identifier name, string contents or literal digits
+	Pos   int    // Byte offset in the input
+}
+
+// Is reports whether the token is the given bare keyword (case-insensitive)
+func (t Token) Is(keyword string) bool {
+	return t.Kind == TokIdent && strings.EqualFold(t.Text, keyword)
+}
+
+// IsOp reports whether the token is the given operator or punctuation
+func (t Token) IsOp(op string) bool {
+	return t.Kind == TokOperator && t.Text == op
+}
+
+// IsTrivia reports whether the token is whitespace or a comment
+func (t Token) IsTrivia() bool {
+	return t.Kind == TokSpace || t.Kind == TokComment
+}
+
+// IsIdent reports whether the token names an identifier, quoted or not
+func (t Token) IsIdent() bool {
+	return t.Kind == TokIdent || t.Kind == TokQuotedIdent
+}
+
+// Upper returns the upper-cased text of a bare word, or "" for other tokens
+func (t Token) Upper() string {
+	if t.Kind != TokIdent {
+		return ""
+	}
+	return strings.ToUpper(t.Text)
+}
+
+// dialect selects the lexical rules used when tokenizing
+type dialect int
+
+const (
+	dialectMySQL dialect = iota
+	dialectPostgres
+)
+
+// Multi-character operators, longest first so that the first match wins
+var mysqlOperators = []string{"<=>", "->>", "<<", ">>", "<=", ">=", "<>", "!=", "||", "&&", ":=", "->", "::"}
+
+var postgresOperators = []string{
+	"#>>", "!~*", "->>", "#>", "#-", "@>", "<@", "?|", "?&", "!~", "~*",
+	"<<", ">>", "<=", ">=", "<>", "!=", "||", "&&", ":=", "->", "::",
+}
+
+// Lex splits a MySQL statement into tokens. Whitespace and comments are kept
+// so that the token stream renders back to the original text.
+func Lex(input string) ([]Token, error) {
+	return lex(input, dialectMySQL)
+}
+
+// lexPostgres tokenizes PostgreSQL text, as produced by the rewriters
+func lexPostgres(input string) ([]Token, error) {
+	return lex(input, dialectPostgres)
+}
+
+func lex(input string, d dialect) ([]Token, error) {
+	l := &lexer{input: input, dialect: d}
+	for l.pos < len(l.input) {
+		if err := l.next(); err != nil {
+			return nil, err
+		}
+	}
+	return l.tokens, nil
+}
+
+type lexer struct {
+	input   string
+	pos     int
+	dialect dialect
+	tokens  []Token
+}
+
+func (l *lexer) emit(kind TokenKind, start int, value string) {
+	l.tokens = append(l.tokens, Token{Kind: kind, Text: l.input[start:l.pos], Value: value, Pos: start})
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.input) {
+		return l.input[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *lexer) next() error {
+	start := l.pos
+	c := l.input[l.pos]
+
+	switch {
+	case isSpace(c):
+		for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+			l.pos++
+		}
+		l.emit(TokSpace, start, "")
+		return nil
+
+	case c == '-' && l.peek(1) == '-' && (l.dialect == dialectPostgres || l.pos+2 >= len(l.input) || isSpace(l.peek(2))):
+		l.skipLine()
+		l.emit(TokComment, start, "")
+		return nil
+
+	case c == '#' && l.dialect == dialectMySQL:
+		l.skipLine()
+		l.emit(TokComment, start, "")
+		return nil
+
+	case c == '/' && l.peek(1) == '*':
+		end := strings.Index(l.input[l.pos+2:], "*/")
+		if end < 0 {
+			return fmt.Errorf("unterminated comment at position %d", start)
+		}
+		l.pos += end + 4
+		l.emit(TokComment, start, "")
+		return nil
+
+	case c == '\'':
+		return l.lexString(start, TokString, '\'', l.dialect == dialectMySQL)
+
+	case c == '"':
+		if l.dialect == dialectMySQL {
+			return l.lexString(start, TokDoubleString, '"', true)
+		}
+		return l.lexQuotedIdent(start, '"')
+
+	case c == '`' && l.dialect == dialectMySQL:
+		return l.lexQuotedIdent(start, '`')
+
+	case (c == 'x' || c == 'X') && l.peek(1) == '\'':
+		l.pos++
+		return l.lexBinary(start, TokHex)
+
+	case (c == 'b' || c == 'B') && l.peek(1) == '\'':
+		l.pos++
+		return l.lexBinary(start, TokBit)
+
+	case (c == 'n' || c == 'N') && l.peek(1) == '\'' && l.dialect == dialectMySQL:
+		l.pos++
+		return l.lexString(start, TokString, '\'', true)
+
+	case (c == 'e' || c == 'E') && l.peek(1) == '\'' && l.dialect == dialectPostgres:
+		l.pos++
+		return l.lexString(start, TokString, '\'', true)
+
+	case c == '0' && (l.peek(1) == 'x' || l.peek(1) == 'X') && isHexDigit(l.peek(2)):
+		l.pos += 2
+		for l.pos < len(l.input) && isHexDigit(l.input[l.pos]) {
+			l.pos++
+		}
+		l.emit(TokHex, start, l.input[start+2:l.pos])
+		return nil
+
+	case c == '0' && (l.peek(1) == 'b' || l.peek(1) == 'B') && (l.peek(2) == '0' || l.peek(2) == '1'):
+		l.pos += 2
+		for l.pos < len(l.input) && (l.input[l.pos] == '0' || l.input[l.pos] == '1') {
+			l.pos++
+		}
+		l.emit(TokBit, start, l.input[start+2:l.pos])
+		return nil
+
+	case isDigit(c) || (c == '.' && isDigit(l.peek(1))):
+		l.lexNumber(start)
+		return nil
+
+	case c == '@' && l.peek(1) == '@':
+		l.pos += 2
+		for l.pos < len(l.input) && (isIdentByte(l.input[l.pos]) || l.input[l.pos] == '.') {
+			l.pos++
+		}
+		l.emit(TokSystemVariable, start, l.input[start+2:l.pos])
+		return nil
+
+	case c == '@' && l.dialect == dialectMySQL && (l.peek(1) == '\'' || l.peek(1) == '"' || l.peek(1) == '`'):
+		l.pos++
+		quote := l.input[l.pos]
+		if err := l.lexString(l.pos, TokString, quote, quote != '`'); err != nil {
+			return err
+		}
+		quoted := l.tokens[len(l.tokens)-1]
+		l.tokens = l.tokens[:len(l.tokens)-1]
+		l.emit(TokVariable, start, quoted.Value)
+		return nil
+
+	case c == '@' && l.pos+1 < len(l.input) && isIdentStart(l.input[l.pos+1:]):
+		l.pos++
+		for l.pos < len(l.input) && (isIdentByte(l.input[l.pos]) || l.input[l.pos] == '.') {
+			l.pos++
+		}
+		l.emit(TokVariable, start, l.input[start+1:l.pos])
+		return nil
+
+	case c == '?' && l.dialect == dialectMySQL:
+		l.pos++
+		l.emit(TokParam, start, "")
+		return nil
+
+	case c == '$' && l.dialect == dialectPostgres && isDigit(l.peek(1)):
+		l.pos++
+		for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+			l.pos++
+		}
+		l.emit(TokParam, start, l.input[start+1:l.pos])
+		return nil
+
+	case c == '$' && l.dialect == dialectPostgres:
+		if tag, ok := l.dollarTag(); ok {
+			end := strings.Index(l.input[l.pos+len(tag):], tag)
+			if end < 0 {
+				return fmt.Errorf("unterminated dollar-quoted string at position %d", start)
+			}
+			body := l.input[l.pos+len(tag) : l.pos+len(tag)+end]
+			l.pos += len(tag)*2 + end
+			l.emit(TokString, start, body)
+			return nil
+		}
+
+	case isIdentStart(l.input[l.pos:]):
+		for l.pos < len(l.input) {
+			r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+			if !(r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				break
+			}
+			l.pos += size
+		}
+		word := l.input[start:l.pos]
+		l.emit(TokIdent, start, word)
+		return nil
+	}
+
+	operators := mysqlOperators
+	if l.dialect == dialectPostgres {
+		operators = postgresOperators
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(l.input[l.pos:], op) {
+			l.pos += len(op)
+			l.emit(TokOperator, start, op)
+			return nil
+		}
+	}
+	_, size := utf8.DecodeRuneInString(l.input[l.pos:])
+	l.pos += size
+	l.emit(TokOperator, start, l.input[start:l.pos])
+	return nil
+}
+
+func (l *lexer) skipLine() {
+	for l.pos < len(l.input) && l.input[l.pos] != '\n' {
+		l.pos++
+	}
+}
+
+// lexString reads a quoted string starting at the opening quote under l.pos.
+// MySQL strings (and PostgreSQL E'...' strings) honor backslash escapes.
+func (l *lexer) lexString(start int, kind TokenKind, quote byte, backslash bool) error {
+	l.pos++
+	var value strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '\\' && backslash && l.pos+1 < len(l.input):
+			value.WriteString(unescapeMySQL(l.input[l.pos+1]))
+			l.pos += 2
+		case c == quote && l.peek(1) == quote:
+			value.WriteByte(quote)
+			l.pos += 2
+		case c == quote:
+			l.pos++
+			l.emit(kind, start, value.String())
+			return nil
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+	return fmt.Errorf("unterminated string starting at position %d", start)
+}
+
+func (l *lexer) lexQuotedIdent(start int, quote byte) error {
+	if err := l.lexString(start, TokQuotedIdent, quote, false); err != nil {
+		return fmt.Errorf("unterminated quoted identifier starting at position %d", start)
+	}
+	return nil
+}
+
+// lexBinary reads the quoted part of an X'..' or B'..' literal
+func (l *lexer) lexBinary(start int, kind TokenKind) error {
+	end := strings.IndexByte(l.input[l.pos+1:], '\'')
+	if end < 0 {
+		return fmt.Errorf("unterminated literal starting at position %d", start)
+	}
+	digits := l.input[l.pos+1 : l.pos+1+end]
+	l.pos += end + 2
+	l.emit(kind, start, digits)
+	return nil
+}
+
+func (l *lexer) lexNumber(start int) {
+	for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+		l.pos++
+	}
+	if l.peek(0) == '.' {
+		l.pos++
+		for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+			l.pos++
+		}
+	}
+	if c := l.peek(0); (c == 'e' || c == 'E') && (isDigit(l.peek(1)) || ((l.peek(1) == '+' || l.peek(1) == '-') && isDigit(l.peek(2)))) {
+		l.pos += 2
+		for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+			l.pos++
+		}
+	}
+	l.emit(TokNumber, start, l.input[start:l.pos])
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string at l.pos
+func (l *lexer) dollarTag() (string, bool) {
+	end := l.pos + 1
+	for end < len(l.input) && isIdentByte(l.input[end]) && l.input[end] != '$' {
+		end++
+	}
+	if end < len(l.input) && l.input[end] == '$' {
+		return l.input[l.pos : end+1], true
+	}
+	return "", false
+}
+
+// unescapeMySQL decodes the character following a backslash in a MySQL string.
+// \% and \_ keep their backslash so that LIKE patterns survive unchanged.
+func unescapeMySQL(c byte) string {
+	switch c {
+	case '0':
+		return "\x00"
+	case 'b':
+		return "\b"
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case 'Z':
+		return "\x1a"
+	case '%', '_':
+		return "\\" + string(c)
+	default:
+		return string(c)
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// render joins tokens back into statement text
+func render(tokens []Token) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteString(tok.Text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,134 @@
+package translator
+
+// rewriteLimit converts MySQL pagination to PostgreSQL:
+//
+//	LIMIT 20, 10                       -> LIMIT 10 OFFSET 20 (at any depth)
+//	DELETE FROM t WHERE w ORDER BY o LIMIT n
+//	    -> DELETE FROM t WHERE (tableoid, ctid) IN (SELECT tableoid, ctid FROM t WHERE w ORDER BY o LIMIT n)
+//	UPDATE t SET ... WHERE w LIMIT n   -> the same row-locator subquery
+//
+// PostgreSQL has no LIMIT on UPDATE/DELETE, so the affected rows are picked by
+// (tableoid, ctid), which stays unique on partitioned tables too.
+func rewriteLimit(tokens []Token) []Token {
+	tokens = rewriteLimitComma(tokens)
+
+	first := nextSignificant(tokens, 0)
+	if first == len(tokens) {
+		return tokens
+	}
+	switch tokens[first].Upper() {
+	case "DELETE":
+		return rewriteDMLLimit(tokens, first, "FROM")
+	case "UPDATE":
+		return rewriteDMLLimit(tokens, first, "SET")
+	}
+	return tokens
+}
+
+// rewriteLimitComma turns every "LIMIT offset, count" into "LIMIT count OFFSET offset"
+func rewriteLimitComma(tokens []Token) []Token {
+	var out []Token
+	for i := 0; i < len(tokens); i++ {
+		out = append(out, tokens[i])
+		if !tokens[i].Is("LIMIT") {
+			continue
+		}
+		offset := nextSignificant(tokens, i+1)
+		comma := nextSignificant(tokens, offset+1)
+		count := nextSignificant(tokens, comma+1)
+		if count >= len(tokens) || !isLimitOperand(tokens[offset]) || !tokens[comma].IsOp(",") || !isLimitOperand(tokens[count]) {
+			continue
+		}
+		out = append(out, tokens[i+1:offset]...) // whitespace after LIMIT
+		out = append(out, tokens[count], Token{Kind: TokSpace, Text: " "},
+			Token{Kind: TokIdent, Text: "OFFSET"}, Token{Kind: TokSpace, Text: " "}, tokens[offset])
+		i = count
+	}
+	return out
+}
+
+// isLimitOperand reports whether tok can be a LIMIT argument: a number, a
+// placeholder, or a variable inside a stored routine
+func isLimitOperand(tok Token) bool {
+	switch tok.Kind {
+	case TokNumber, TokParam, TokVariable, TokIdent:
+		return true
+	}
+	return false
+}
+
+// rewriteDMLLimit moves ORDER BY/LIMIT of a single-table UPDATE or DELETE
+// into a row-locator subquery. clause is the keyword that ends the table
+// reference: FROM for DELETE (which precedes it) or SET for UPDATE.
+func rewriteDMLLimit(tokens []Token, verb int, clause string) []Token {
+	orderIdx := findTopLevel(tokens, verb, "ORDER")
+	limitIdx := findTopLevel(tokens, verb, "LIMIT")
+	if orderIdx < 0 && limitIdx < 0 {
+		return tokens
+	}
+
+	// Locate the single target table
+	var tableStart, tableEnd int
+	if clause == "FROM" {
+		from := findTopLevel(tokens, verb, "FROM")
+		if from < 0 {
+			return tokens
+		}
+		tableStart = nextSignificant(tokens, from+1)
+		tableEnd = findTopLevel(tokens, tableStart, "WHERE", "ORDER", "LIMIT")
+	} else {
+		tableStart = nextSignificant(tokens, verb+1)
+		for tableStart < len(tokens) && isModifier(tokens[tableStart]) {
+			tableStart = nextSignificant(tokens, tableStart+1)
+		}
+		tableEnd = findTopLevel(tokens, tableStart, "SET")
+	}
+	if tableEnd < 0 || findTopLevel(tokens[tableStart:tableEnd], 0, "JOIN", "USING") >= 0 ||
+		splitCount(tokens[tableStart:tableEnd]) > 1 {
+		// Multi-table statements cannot carry ORDER BY/LIMIT in MySQL
+		return tokens
+	}
+	table := trimTrivia(tokens[tableStart:tableEnd])
+
+	if limitIdx < 0 {
+		// ORDER BY without LIMIT does not change which rows are affected
+		return trimTrivia(tokens[:orderIdx])
+	}
+
+	filterStart := findTopLevel(tokens, tableEnd, "WHERE", "ORDER", "LIMIT")
+	filter := trimTrivia(tokens[filterStart:])
+
+	return concatTokens(
+		trimTrivia(tokens[:filterStart]),
+		pgTokens(" WHERE (tableoid, ctid) IN (SELECT tableoid, ctid FROM "),
+		table,
+		pgTokens(" "),
+		filter,
+		pgTokens(")"),
+	)
+}
+
+// isModifier reports whether tok is a MySQL DML priority/ignore modifier
+func isModifier(tok Token) bool {
+	switch tok.Upper() {
+	case "LOW_PRIORITY", "QUICK", "IGNORE":
+		return true
+	}
+	return false
+}
+
+// splitCount returns the number of comma-separated items outside parentheses
+func splitCount(tokens []Token) int {
+	count, depth := 1, 0
+	for _, tok := range tokens {
+		switch {
+		case tok.IsOp("("):
+			depth++
+		case tok.IsOp(")"):
+			depth--
+		case tok.IsOp(",") && depth == 0:
+			count++
+		}
+	}
+	return count
+}
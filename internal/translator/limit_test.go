@@ -0,0 +1,44 @@
+package translator
+
+import (
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateLimit(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT * FROM t LIMIT 20, 10", "SELECT * FROM t LIMIT 10 OFFSET 20"},
+		{"SELECT * FROM t LIMIT 10 OFFSET 20", "SELECT * FROM t LIMIT 10 OFFSET 20"},
+		{
+			"SELECT id FROM (SELECT id FROM a LIMIT 5,5) x UNION (SELECT id FROM b LIMIT 0, 3)",
+			"SELECT id FROM (SELECT id FROM a LIMIT 5 OFFSET 5) x UNION (SELECT id FROM b LIMIT 3 OFFSET 0)",
+		},
+		{"SELECT * FROM t LIMIT ?, ?", "SELECT * FROM t LIMIT $2 OFFSET $1"},
+		{
+			"DELETE FROM logs WHERE level = 'DEBUG' ORDER BY log_time LIMIT 100",
+			"DELETE FROM logs WHERE (tableoid, ctid) IN (SELECT tableoid, ctid FROM logs WHERE level = 'DEBUG' ORDER BY log_time LIMIT 100)",
+		},
+		{
+			"UPDATE `orders` o SET status = 'expired' WHERE status = 'pending' LIMIT 10",
+			`UPDATE "orders" o SET status = 'expired' WHERE (tableoid, ctid) IN (SELECT tableoid, ctid FROM "orders" o WHERE status = 'pending' LIMIT 10)`,
+		},
+		{"UPDATE users SET status = 'active' ORDER BY id", "UPDATE users SET status = 'active'"},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.want)
+		}
+	}
+}
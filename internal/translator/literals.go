@@ -0,0 +1,147 @@
+package translator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// charsetIntroducers are the _charset prefixes MySQL allows before a string literal
+var charsetIntroducers = map[string]bool{
+	"_ARMSCII8": true, "_ASCII": true, "_BINARY": true, "_LATIN1": true,
+	"_UTF8": true, "_UTF8MB3": true, "_UTF8MB4": true, "_UTF16": true,
+	"_UTF32": true, "_UCS2": true, "_GBK": true, "_GB18030": true, "_BIG5": true,
+}
+
+// rewriteLiterals converts MySQL lexical forms to PostgreSQL ones:
+//
+//	`ident`      -> "ident"
+//	"string"     -> 'string'
+//	'a\nb'       -> E'a\nb'
+//	X'4142'      -> '\x4142'::bytea
+//	b'101'       -> B'101'::integer
+//	?            -> $1, $2, ... numbered in source order
+//	# comment    -> -- comment
+//
+// A string used as a column alias (AS 'Total') becomes a quoted identifier.
+func rewriteLiterals(tokens []Token) ([]Token, error) {
+	out := make([]Token, 0, len(tokens))
+	params := 0
+	afterIntroducer := false
+	for i, tok := range tokens {
+		if afterIntroducer && tok.Kind == TokSpace {
+			continue
+		}
+		afterIntroducer = false
+		switch tok.Kind {
+		case TokQuotedIdent:
+			tok.Text = quoteIdent(tok.Value)
+
+		case TokString, TokDoubleString:
+			if prev := prevSignificant(tokens, i); prev >= 0 && tokens[prev].Is("AS") {
+				tok.Kind = TokQuotedIdent
+				tok.Text = quoteIdent(tok.Value)
+				break
+			}
+			text, err := pgString(tok.Value)
+			if err != nil {
+				return nil, err
+			}
+			tok.Kind = TokString
+			tok.Text = text
+
+		case TokHex:
+			digits := tok.Value
+			if len(digits)%2 == 1 {
+				digits = "0" + digits
+			}
+			tok.Text = `'\x` + strings.ToLower(digits) + `'::bytea`
+
+		case TokBit:
+			digits := tok.Value
+			if digits == "" {
+				digits = "0"
+			}
+			tok.Text = "B'" + digits + "'::integer"
+
+		case TokIdent:
+			// Drop _utf8mb4 style introducers in front of a string, and the
+			// space after them
+			if j := nextSignificant(tokens, i+1); charsetIntroducers[tok.Upper()] && j < len(tokens) &&
+				(tokens[j].Kind == TokString || tokens[j].Kind == TokDoubleString) {
+				afterIntroducer = true
+				continue
+			}
+
+		case TokParam:
+			params++
+			tok.Value = strconv.Itoa(params)
+			tok.Text = "$" + tok.Value
+
+		case TokComment:
+			tok.Text = pgComment(tok.Text)
+		}
+		out = append(out, tok)
+	}
+	return out, nil
+}
+
+// pgString renders a decoded MySQL string as a PostgreSQL literal. Strings
+// containing backslashes or control characters use the E'...' escape syntax.
+func pgString(value string) (string, error) {
+	needsEscape := false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == 0 {
+			return "", fmt.Errorf("string literals containing NUL characters are not supported on PostgreSQL")
+		}
+		if c == '\\' || c < 0x20 || c == 0x7f {
+			needsEscape = true
+		}
+	}
+	if !needsEscape {
+		return quoteLiteral(value), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("E'")
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&sb, `\x%02x`, c)
+			} else {
+				sb.WriteByte(c)
+			}
+		}
+	}
+	sb.WriteString("'")
+	return sb.String(), nil
+}
+
+// pgComment converts a MySQL comment to a form PostgreSQL accepts
+func pgComment(text string) string {
+	if strings.HasPrefix(text, "#") {
+		return "--" + text[1:]
+	}
+	if strings.HasPrefix(text, "/*") {
+		// PostgreSQL block comments nest, MySQL ones do not
+		inner := text[2 : len(text)-2]
+		return "/*" + strings.ReplaceAll(inner, "/*", "/ *") + "*/"
+	}
+	return text
+}
@@ -0,0 +1,48 @@
+package translator
+
+import (
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateQuoting(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT `order` FROM `users` WHERE name = \"bob\"", `SELECT "order" FROM "users" WHERE name = 'bob'`},
+		{"SELECT `group`, `select` FROM `t`", `SELECT "group", "select" FROM "t"`},
+		{"SELECT `we\"ird` FROM t", `SELECT "we""ird" FROM t`},
+		{`SELECT 'it\'s', "say \"hi\""`, `SELECT 'it''s', 'say "hi"'`},
+		{`SELECT 'a\nb', 'C:\\temp'`, `SELECT E'a\nb', E'C:\\temp'`},
+		{`SELECT * FROM t WHERE name LIKE 'a\_b%'`, `SELECT * FROM t WHERE name LIKE E'a\\_b%'`},
+		{`SELECT total AS "Total", n AS 'Count' FROM t`, `SELECT total AS "Total", n AS "Count" FROM t`},
+		{"SELECT X'4142', 0x0A", `SELECT '\x4142'::bytea, '\x0a'::bytea`},
+		{"SELECT b'101', 0b11", "SELECT B'101'::integer, B'11'::integer"},
+		{"SELECT _utf8mb4'abc', N'xyz'", "SELECT 'abc', 'xyz'"},
+		{"SELECT _utf8mb4 'abc', _latin1 /* c */ 'x', _utf8mb4", "SELECT 'abc', /* c */ 'x', _utf8mb4"},
+		{"SELECT 1 # why\n, 2", "SELECT 1 -- why\n, 2"},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.want)
+		}
+	}
+}
+
+func TestTranslateNulInString(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	if _, err := tr.Translate(`SELECT 'a\0b'`); err == nil {
+		t.Error("expected error for NUL character in string literal")
+	}
+}
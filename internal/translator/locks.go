@@ -0,0 +1,175 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TableLock is one table of LOCK TABLES
+type TableLock struct {
+	Table []Token
+	Write bool // WRITE rather than READ
+}
+
+// LockTablesStmt is LOCK TABLES t [AS alias] READ [LOCAL] | [LOW_PRIORITY] WRITE, ...
+type LockTablesStmt struct {
+	Tables []TableLock
+}
+
+// UnlockTablesStmt is UNLOCK TABLES
+type UnlockTablesStmt struct{}
+
+func (*LockTablesStmt) statementNode()   {}
+func (*UnlockTablesStmt) statementNode() {}
+
+func (p *parser) parseLockTables() (Statement, error) {
+	p.next() // LOCK
+	p.next() // TABLE or TABLES
+	stmt := &LockTablesStmt{}
+	for {
+		table, err := p.tableName()
+		if err != nil {
+			return nil, err
+		}
+		// PostgreSQL locks tables, not aliases, so the alias is dropped
+		if p.accept("AS") {
+			if _, err := p.ident(); err != nil {
+				return nil, err
+			}
+		} else if tok := p.peek(); tok.IsIdent() && !tok.Is("READ") && !tok.Is("WRITE") && !tok.Is("LOW_PRIORITY") {
+			p.next()
+		}
+
+		lock := TableLock{Table: table}
+		switch {
+		case p.accept("READ"):
+			p.accept("LOCAL")
+		case p.accept("WRITE"), p.acceptSeq("LOW_PRIORITY", "WRITE"):
+			lock.Write = true
+		default:
+			return nil, p.errorf("expected READ or WRITE")
+		}
+		stmt.Tables = append(stmt.Tables, lock)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	return stmt, p.expectEnd()
+}
+
+func (p *parser) parseUnlockTables() (Statement, error) {
+	p.next() // UNLOCK
+	if !p.accept("TABLES", "TABLE") {
+		return nil, p.errorf("expected TABLES")
+	}
+	return &UnlockTablesStmt{}, p.expectEnd()
+}
+
+// translateLockTables takes the locks inside a transaction, the only place
+// PostgreSQL holds table locks, which the client opens again after each
+// statement it commits until UNLOCK TABLES. READ lets other sessions read but not write, as SHARE mode does;
+// WRITE shuts them out entirely, as ACCESS EXCLUSIVE does.
+func (t *Translator) translateLockTables(s *LockTablesStmt) *TranslationResult {
+	result := &TranslationResult{IsSpecial: true, SpecialType: "lock_tables"}
+	for _, lock := range s.Tables {
+		mode := "SHARE"
+		if lock.Write {
+			mode = "ACCESS EXCLUSIVE"
+		}
+		result.Statements = append(result.Statements, fmt.Sprintf("LOCK TABLE %s IN %s MODE", render(lock.Table), mode))
+	}
+	return result
+}
+
+// rewriteLockingReads turns SELECT ... LOCK IN SHARE MODE into FOR SHARE.
+// FOR UPDATE and FOR SHARE with NOWAIT or SKIP LOCKED are spelled the same
+// in both databases.
+func rewriteLockingReads(tokens []Token) []Token {
+	var out []Token
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].Is("LOCK") {
+			out = append(out, tokens[i])
+			continue
+		}
+		j, words := i, []string{"IN", "SHARE", "MODE"}
+		for len(words) > 0 {
+			if j = nextSignificant(tokens, j+1); j == len(tokens) || !tokens[j].Is(words[0]) {
+				break
+			}
+			words = words[1:]
+		}
+		if len(words) > 0 {
+			out = append(out, tokens[i])
+			continue
+		}
+		out = append(out, pgTokens("FOR SHARE")...)
+		i = j
+	}
+	return out
+}
+
+// getLockFunction waits a limited time for an advisory lock, which
+// pg_advisory_lock cannot do by itself. It is created in pg_temp, so it
+// needs no privileges beyond TEMP and goes away with the session.
+const getLockFunction = "pg_temp.mygo_get_lock"
+
+// getLockFunctionSQL bounds the wait with lock_timeout, restored afterwards.
+// A negative timeout waits forever, as in MySQL.
+const getLockFunctionSQL = "CREATE OR REPLACE FUNCTION " + getLockFunction + "(key bigint, timeout double precision) " +
+	"RETURNS integer LANGUAGE plpgsql AS $mygo$ DECLARE previous text := current_setting('lock_timeout'); " +
+	"BEGIN IF timeout < 0 THEN PERFORM pg_advisory_lock(key); RETURN 1; END IF; " +
+	"PERFORM set_config('lock_timeout', greatest(ceil(timeout * 1000), 1)::bigint::text, true); " +
+	"BEGIN PERFORM pg_advisory_lock(key); " +
+	"EXCEPTION WHEN lock_not_available THEN PERFORM set_config('lock_timeout', previous, true); RETURN 0; END; " +
+	"PERFORM set_config('lock_timeout', previous, true); RETURN 1; END $mygo$"
+
+// lockSetup returns the helper functions a translated query calls
+func lockSetup(query string) []string {
+	if strings.Contains(query, getLockFunction) {
+		return []string{getLockFunctionSQL}
+	}
+	return nil
+}
+
+// lockKey maps a MySQL lock name onto a PostgreSQL advisory lock key
+func lockKey(name string) string {
+	return "hashtextextended(" + name + ", 0)"
+}
+
+// heldLock selects the pg_locks rows of a granted advisory lock on key.
+// A bigint key is stored split into classid and objid.
+func heldLock(key string) string {
+	return "FROM pg_locks WHERE locktype = 'advisory' AND objsubid = 1 AND granted AND ((classid::bigint << 32) | objid::bigint) = " + key
+}
+
+// GET_LOCK(name, timeout) -> 1 once the lock is held, 0 on timeout
+func rewriteGetLock(c *funcCall) (string, error) {
+	if err := c.argCount(2, 2); err != nil {
+		return "", err
+	}
+	return getLockFunction + "(" + lockKey(c.arg(0)) + ", " + c.arg(1) + ")", nil
+}
+
+// RELEASE_LOCK(name) -> 1 if this session held the lock, else 0
+func rewriteReleaseLock(c *funcCall) (string, error) {
+	if err := c.argCount(1, 1); err != nil {
+		return "", err
+	}
+	return "pg_advisory_unlock(" + lockKey(c.arg(0)) + ")::int", nil
+}
+
+// IS_FREE_LOCK(name) -> 1 if no session holds the lock
+func rewriteIsFreeLock(c *funcCall) (string, error) {
+	if err := c.argCount(1, 1); err != nil {
+		return "", err
+	}
+	return "(NOT EXISTS (SELECT " + heldLock(lockKey(c.arg(0))) + "))::int", nil
+}
+
+// IS_USED_LOCK(name) -> the backend process holding the lock, or NULL
+func rewriteIsUsedLock(c *funcCall) (string, error) {
+	if err := c.argCount(1, 1); err != nil {
+		return "", err
+	}
+	return "(SELECT pid " + heldLock(lockKey(c.arg(0))) + " LIMIT 1)", nil
+}
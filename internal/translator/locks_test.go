@@ -0,0 +1,91 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateLockTables(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{
+			"LOCK TABLES orders READ, users AS u WRITE, `audit` READ LOCAL, logs LOW_PRIORITY WRITE",
+			[]string{
+				"LOCK TABLE orders IN SHARE MODE",
+				"LOCK TABLE users IN ACCESS EXCLUSIVE MODE",
+				`LOCK TABLE "audit" IN SHARE MODE`,
+				"LOCK TABLE logs IN ACCESS EXCLUSIVE MODE",
+			},
+		},
+		{"LOCK TABLE shop.orders o READ", []string{"LOCK TABLE shop.orders IN SHARE MODE"}},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.SpecialType != "lock_tables" || !reflect.DeepEqual(result.Statements, tt.want) {
+			t.Errorf("for %s: got %s %q", tt.input, result.SpecialType, result.Statements)
+		}
+	}
+
+	result, err := tr.Translate("UNLOCK TABLES")
+	if err != nil || result.SpecialType != "unlock_tables" {
+		t.Errorf("UNLOCK TABLES: got %+v, %v", result, err)
+	}
+	if _, err := tr.Translate("LOCK TABLES orders"); err == nil {
+		t.Errorf("LOCK TABLES without a lock type: expected an error")
+	}
+}
+
+func TestTranslateLockingReads(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	key := "hashtextextended('job', 0)"
+
+	tests := []struct {
+		input    string
+		expected string
+		setup    bool
+	}{
+		{"SELECT * FROM orders WHERE id = 1 LOCK IN SHARE MODE", "SELECT * FROM orders WHERE id = 1 FOR SHARE", false},
+		{"SELECT * FROM jobs LIMIT 1 FOR UPDATE SKIP LOCKED", "SELECT * FROM jobs LIMIT 1 FOR UPDATE SKIP LOCKED", false},
+		{"SELECT * FROM jobs FOR SHARE NOWAIT", "SELECT * FROM jobs FOR SHARE NOWAIT", false},
+		// PostgreSQL's own LOCK statement is passed through
+		{"LOCK TABLE orders IN SHARE MODE", "LOCK TABLE orders IN SHARE MODE", false},
+		{"SELECT GET_LOCK('job', 10)", "SELECT pg_temp.mygo_get_lock(" + key + ", 10)", true},
+		{"DO GET_LOCK('job', -1)", "SELECT pg_temp.mygo_get_lock(" + key + ", -1)", true},
+		{"SELECT RELEASE_LOCK('job')", "SELECT pg_advisory_unlock(" + key + ")::int", false},
+		{
+			"SELECT IS_FREE_LOCK('job')",
+			"SELECT (NOT EXISTS (SELECT FROM pg_locks WHERE locktype = 'advisory' AND objsubid = 1 AND granted AND " +
+				"((classid::bigint << 32) | objid::bigint) = " + key + "))::int",
+			false,
+		},
+		{
+			"SELECT IS_USED_LOCK('job')",
+			"SELECT (SELECT pid FROM pg_locks WHERE locktype = 'advisory' AND objsubid = 1 AND granted AND " +
+				"((classid::bigint << 32) | objid::bigint) = " + key + " LIMIT 1)",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.expected {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.expected)
+		}
+		if (len(result.Setup) > 0) != tt.setup {
+			t.Errorf("for %s: got setup %q", tt.input, result.Setup)
+		}
+	}
+}
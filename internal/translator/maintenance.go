@@ -0,0 +1,295 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenameTableStmt is RENAME TABLE a TO b [, c TO d ...]
+type RenameTableStmt struct {
+	Renames [][2][]Token // Old and new name of each pair, in order
+}
+
+// CreateIndexStmt is MySQL's CREATE [UNIQUE|FULLTEXT|SPATIAL] INDEX name ON table (...)
+type CreateIndexStmt struct {
+	IfNotExists bool
+	Table       []Token
+	Index       *IndexDef
+}
+
+// CreateViewStmt is CREATE [OR REPLACE] VIEW, or ALTER VIEW, with MySQL's
+// ALGORITHM, DEFINER and SQL SECURITY clauses taken apart
+type CreateViewStmt struct {
+	OrReplace bool
+	Invoker   bool // SQL SECURITY INVOKER
+	Name      []Token
+	Columns   []Token // Column list with parentheses, if given
+	Select    []Token // The query and any WITH CHECK OPTION
+}
+
+// TruncateStmt is TRUNCATE [TABLE] table
+type TruncateStmt struct {
+	Table []Token
+}
+
+func (*RenameTableStmt) statementNode() {}
+func (*CreateIndexStmt) statementNode() {}
+func (*CreateViewStmt) statementNode()  {}
+func (*TruncateStmt) statementNode()    {}
+
+// tableName consumes a possibly qualified table name and returns its tokens
+func (p *parser) tableName() ([]Token, error) {
+	start := p.mark()
+	if _, err := p.qualifiedName(); err != nil {
+		return nil, err
+	}
+	return p.since(start), nil
+}
+
+func (p *parser) parseRenameTable() (Statement, error) {
+	p.next() // RENAME
+	if err := p.expect("TABLE"); err != nil {
+		return nil, err
+	}
+	stmt := &RenameTableStmt{}
+	for {
+		from, err := p.tableName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("TO"); err != nil {
+			return nil, err
+		}
+		to, err := p.tableName()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Renames = append(stmt.Renames, [2][]Token{from, to})
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	return stmt, p.expectEnd()
+}
+
+// isDropIndexOn reports whether the statement is MySQL's DROP INDEX [IF EXISTS] name ON table
+func (p *parser) isDropIndexOn() bool {
+	n := 2
+	if p.peekN(2).Is("IF") && p.peekN(3).Is("EXISTS") {
+		n = 4
+	}
+	return p.peekN(1).Is("INDEX") && p.peekN(n).IsIdent() && p.peekN(n+1).Is("ON")
+}
+
+// parseDropIndexOn parses DROP INDEX [IF EXISTS] name ON table [ALGORITHM=...]
+// [LOCK=...], which is the same as ALTER TABLE table DROP INDEX [IF EXISTS] name
+func (p *parser) parseDropIndexOn() (Statement, error) {
+	p.next() // DROP
+	p.next() // INDEX
+	spec := []Token{{Kind: TokIdent, Text: "DROP"}, {Kind: TokIdent, Text: "INDEX"}}
+	if p.acceptSeq("IF", "EXISTS") {
+		spec = append(spec, Token{Kind: TokIdent, Text: "IF"}, Token{Kind: TokIdent, Text: "EXISTS"})
+	}
+	name := p.next()
+	p.next() // ON
+	table, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("ALGORITHM", "LOCK") {
+		p.acceptOp("=")
+		p.next()
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return &AlterTableStmt{Table: table, Specs: [][]Token{append(spec, name)}}, nil
+}
+
+// isCreateIndex reports whether the statement starts with CREATE [UNIQUE|FULLTEXT|SPATIAL] INDEX
+func (p *parser) isCreateIndex() bool {
+	next := p.peekN(1)
+	if next.Is("UNIQUE") || next.Is("FULLTEXT") || next.Is("SPATIAL") {
+		next = p.peekN(2)
+	}
+	return next.Is("INDEX")
+}
+
+// parseCreateIndex parses CREATE [UNIQUE|FULLTEXT|SPATIAL] INDEX [IF NOT EXISTS] name
+// [USING type] ON table [USING type] (key_part, ...) [USING type] [options]
+func (p *parser) parseCreateIndex() (Statement, error) {
+	p.next() // CREATE
+	index := &IndexDef{}
+	switch {
+	case p.accept("UNIQUE"):
+		index.Unique = true
+	case p.accept("FULLTEXT"):
+		index.Fulltext = true
+	case p.accept("SPATIAL"):
+		index.Spatial = true
+	}
+	if err := p.expect("INDEX"); err != nil {
+		return nil, err
+	}
+	stmt := &CreateIndexStmt{IfNotExists: p.acceptSeq("IF", "NOT", "EXISTS")}
+	name := p.next()
+	if !name.IsIdent() {
+		return nil, fmt.Errorf("syntax error: expected index name near '%s'", name.Text)
+	}
+	index.Name = catalogName(name)
+	if p.accept("USING") {
+		index.Using = strings.ToLower(p.next().Text)
+	}
+	if err := p.expect("ON"); err != nil {
+		return nil, err
+	}
+	var err error
+	if stmt.Table, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if p.accept("USING") {
+		index.Using = strings.ToLower(p.next().Text)
+	}
+
+	// The key parts and index options are laid out as in CREATE TABLE
+	keys, err := p.parseIndexDef()
+	if err != nil {
+		return nil, err
+	}
+	index.Columns = keys.Columns
+	if keys.Using != "" {
+		index.Using = keys.Using
+	}
+	stmt.Index = index
+	return stmt, nil
+}
+
+// isCreateView reports whether the statement is CREATE or ALTER of a view,
+// with any of MySQL's view clauses before VIEW
+func (p *parser) isCreateView() bool {
+	for n := 1; ; n++ {
+		tok := p.peekN(n)
+		switch {
+		case tok.Is("VIEW"):
+			return true
+		case tok.Kind == TokEOF:
+			return false
+		case tok.Is("OR"), tok.Is("REPLACE"), tok.Is("ALGORITHM"), tok.Is("DEFINER"), tok.Is("SQL"),
+			tok.Is("SECURITY"), tok.Is("INVOKER"), tok.Is("UNDEFINED"), tok.Is("MERGE"), tok.Is("TEMPTABLE"),
+			tok.Is("CURRENT_USER"), tok.IsOp("="), tok.IsOp("("), tok.IsOp(")"),
+			tok.Kind == TokString, tok.Kind == TokVariable, tok.IsIdent() && p.peekN(n-1).IsOp("="):
+		default:
+			return false
+		}
+	}
+}
+
+// parseCreateView parses [CREATE [OR REPLACE] | ALTER] [ALGORITHM = x]
+// [DEFINER = user] [SQL SECURITY {DEFINER|INVOKER}] VIEW name [(columns)] AS query
+func (p *parser) parseCreateView() (Statement, error) {
+	stmt := &CreateViewStmt{}
+	if p.next().Is("ALTER") {
+		// ALTER VIEW redefines the view's query
+		stmt.OrReplace = true
+	}
+	if p.acceptSeq("OR", "REPLACE") {
+		stmt.OrReplace = true
+	}
+	for !p.accept("VIEW") {
+		switch {
+		case p.accept("ALGORITHM"):
+			p.acceptOp("=")
+			p.next()
+		case p.accept("DEFINER"):
+			p.acceptOp("=")
+			if _, _, err := p.userSpec(); err != nil {
+				return nil, err
+			}
+		case p.acceptSeq("SQL", "SECURITY"):
+			stmt.Invoker = p.next().Is("INVOKER")
+		default:
+			return nil, p.errorf("unexpected input")
+		}
+	}
+
+	var err error
+	if stmt.Name, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if p.peek().IsOp("(") {
+		start := p.mark()
+		if err := p.skipGroup(); err != nil {
+			return nil, err
+		}
+		stmt.Columns = p.since(start)
+	}
+	if err := p.expect("AS"); err != nil {
+		return nil, err
+	}
+	stmt.Select = trimTrivia(p.rest())
+	p.pos = len(p.tokens)
+	if len(stmt.Select) == 0 {
+		return nil, p.errorf("expected view query")
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseTruncate() (Statement, error) {
+	p.next() // TRUNCATE
+	p.accept("TABLE")
+	table, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	return &TruncateStmt{Table: table}, p.expectEnd()
+}
+
+// translateRenameTable renames each table in turn; the client runs the
+// renames in one transaction, so a swap through a temporary name is atomic
+// as it is in MySQL
+func (t *Translator) translateRenameTable(s *RenameTableStmt) (*TranslationResult, error) {
+	var statements []string
+	for _, rename := range s.Renames {
+		from, to := rename[0], rename[1]
+		if len(to) > 1 && (len(from) == 1 || render(from[:len(from)-1]) != render(to[:len(to)-1])) {
+			return nil, fmt.Errorf("moving a table to another database is not supported on PostgreSQL")
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", render(from), to[len(to)-1].Text))
+	}
+	return statementsResult(statements), nil
+}
+
+func (t *Translator) translateCreateIndex(s *CreateIndexStmt) (*TranslationResult, error) {
+	query, err := t.createIndex(s.Table, s.Index, s.IfNotExists)
+	if err != nil {
+		return nil, err
+	}
+	return &TranslationResult{Query: query}, nil
+}
+
+// translateCreateView drops ALGORITHM and DEFINER: PostgreSQL has no view
+// algorithms, and a view belongs to the user who creates it. SQL SECURITY
+// DEFINER is PostgreSQL's default; INVOKER maps to security_invoker.
+func (t *Translator) translateCreateView(s *CreateViewStmt) (*TranslationResult, error) {
+	query, err := t.rewriteTokens(s.Select)
+	if err != nil {
+		return nil, err
+	}
+	sql := "CREATE "
+	if s.OrReplace {
+		sql += "OR REPLACE "
+	}
+	sql += "VIEW " + render(s.Name)
+	if s.Columns != nil {
+		sql += " " + render(s.Columns)
+	}
+	if s.Invoker {
+		sql += " WITH (security_invoker = true)"
+	}
+	return &TranslationResult{Query: sql + " AS " + render(query)}, nil
+}
+
+// translateTruncate restarts identity columns, as MySQL's TRUNCATE resets AUTO_INCREMENT
+func (t *Translator) translateTruncate(s *TruncateStmt) (*TranslationResult, error) {
+	return &TranslationResult{Query: "TRUNCATE TABLE " + render(s.Table) + " RESTART IDENTITY"}, nil
+}
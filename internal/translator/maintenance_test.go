@@ -0,0 +1,87 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateMaintenanceDDL(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{indexes: map[string]map[string]bool{"users": {"users_idx_email": true}}})
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"RENAME TABLE old_users TO users", []string{"ALTER TABLE old_users RENAME TO users"}},
+		{
+			"RENAME TABLE a TO tmp, b TO a, tmp TO b",
+			[]string{"ALTER TABLE a RENAME TO tmp", "ALTER TABLE b RENAME TO a", "ALTER TABLE tmp RENAME TO b"},
+		},
+		{"RENAME TABLE app.t1 TO app.t2", []string{"ALTER TABLE app.t1 RENAME TO t2"}},
+		{
+			"DROP INDEX idx_email ON users",
+			[]string{"ALTER TABLE users DROP CONSTRAINT users_idx_email"},
+		},
+		{"DROP INDEX IF EXISTS idx_email ON users", []string{"ALTER TABLE users DROP CONSTRAINT IF EXISTS users_idx_email"}},
+		{"DROP INDEX IF EXISTS idx_gone ON users", nil},
+		{"DROP INDEX `PRIMARY` ON users ALGORITHM=INPLACE", []string{"ALTER TABLE users DROP CONSTRAINT users_pkey"}},
+		{"DROP INDEX idx_email", []string{"DROP INDEX idx_email"}},
+		{"CREATE INDEX idx_name ON users (name) USING BTREE", []string{"CREATE INDEX users_idx_name ON users USING btree (name)"}},
+		{"CREATE INDEX idx_email USING HASH ON users (email(20))", []string{"CREATE INDEX users_idx_email ON users USING hash ((left(email, 20)))"}},
+		{"CREATE INDEX idx_a ON t (a DESC) ALGORITHM=INPLACE LOCK=NONE", []string{"CREATE INDEX t_idx_a ON t (a DESC)"}},
+		{"CREATE INDEX CONCURRENTLY idx_a ON t (a)", []string{"CREATE INDEX CONCURRENTLY idx_a ON t (a)"}},
+		{
+			"CREATE OR REPLACE ALGORITHM=MERGE DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `v_users` AS SELECT id, IFNULL(name, '') FROM users",
+			[]string{`CREATE OR REPLACE VIEW "v_users" AS SELECT id, COALESCE(name, '') FROM users`},
+		},
+		{
+			"CREATE DEFINER=CURRENT_USER SQL SECURITY INVOKER VIEW v (a, b) AS SELECT 1, 2 WITH CHECK OPTION",
+			[]string{"CREATE VIEW v (a, b) WITH (security_invoker = true) AS SELECT 1, 2 WITH CHECK OPTION"},
+		},
+		{"ALTER VIEW v AS SELECT * FROM t LIMIT 1, 2", []string{"CREATE OR REPLACE VIEW v AS SELECT * FROM t LIMIT 2 OFFSET 1"}},
+		{"TRUNCATE TABLE users", []string{"TRUNCATE TABLE users RESTART IDENTITY"}},
+		{"TRUNCATE logs", []string{"TRUNCATE TABLE logs RESTART IDENTITY"}},
+		{"TRUNCATE a, b CASCADE", []string{"TRUNCATE a, b CASCADE"}},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		got := result.Statements
+		if got == nil && result.Query != "" {
+			got = []string{result.Query}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("for %s:\n got: %q\nwant: %q", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := tr.Translate("RENAME TABLE t TO other.t"); err == nil {
+		t.Errorf("expected an error for renaming a table into another database")
+	}
+	if _, err := tr.Translate("DROP INDEX idx_gone ON users"); err == nil {
+		t.Errorf("expected an error for dropping a missing index")
+	}
+
+	// Statements PostgreSQL refuses inside a transaction block are marked
+	for input, want := range map[string]bool{
+		"CREATE INDEX CONCURRENTLY idx_a ON t (a)": true,
+		"VACUUM ANALYZE t":                         true,
+		"REINDEX (VERBOSE) TABLE CONCURRENTLY t":   true,
+		"CREATE DATABASE shop":                     true,
+		"DROP DATABASE IF EXISTS shop":             true,
+		"CREATE INDEX idx_a ON t (a)":              false,
+		"TRUNCATE logs":                            false,
+	} {
+		result, err := tr.Translate(input)
+		if err != nil || result.NoTransaction != want {
+			t.Errorf("for %s: got %+v, %v", input, result, err)
+		}
+	}
+}
@@ -0,0 +1,629 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tableRef is one table of the table references of a multi-table UPDATE or DELETE
+type tableRef struct {
+	Join  string  // How it joins the tables before it: "" for the first, "," or e.g. "LEFT JOIN"
+	Table []Token // Table name, or a parenthesized derived table or join
+	Alias *Token
+	On    []Token
+	Using []Token // USING column list with parentheses
+}
+
+// Assignment is one col = value of an UPDATE's SET clause
+type Assignment struct {
+	Column []Token // Possibly qualified column name
+	Value  []Token
+}
+
+// UpdateJoinStmt is MySQL's multi-table UPDATE: UPDATE table_references SET ... [WHERE ...]
+type UpdateJoinStmt struct {
+	Tables      []*tableRef
+	Assignments []Assignment
+	Where       []Token
+}
+
+// DeleteJoinStmt is MySQL's multi-table DELETE: DELETE t1, t2 FROM
+// table_references or DELETE FROM t1, t2 USING table_references
+type DeleteJoinStmt struct {
+	Targets []*tableRef // Tables rows are deleted from, among Tables
+	Tables  []*tableRef
+	Where   []Token
+}
+
+func (*UpdateJoinStmt) statementNode() {}
+func (*DeleteJoinStmt) statementNode() {}
+
+// name returns the token the rest of the statement refers to the table by:
+// its alias, or else the table name without its qualifier
+func (r *tableRef) name() Token {
+	if r.Alias != nil {
+		return *r.Alias
+	}
+	return r.Table[len(r.Table)-1]
+}
+
+// isBase reports whether the table reference names a table
+func (r *tableRef) isBase() bool {
+	return !r.Table[0].IsOp("(")
+}
+
+// refEndWords end the table references and the join conditions within them
+var refEndWords = map[string]bool{
+	"SET": true, "WHERE": true, "ORDER": true, "LIMIT": true, "ON": true, "USING": true,
+	"USE": true, "IGNORE": true, "FORCE": true, "PARTITION": true,
+}
+
+// joinWords start a join operator
+var joinWords = map[string]bool{
+	"JOIN": true, "INNER": true, "CROSS": true, "LEFT": true, "RIGHT": true, "NATURAL": true, "STRAIGHT_JOIN": true,
+}
+
+// isMultiUpdate reports whether an UPDATE names more than one table before SET
+func (p *parser) isMultiUpdate() bool {
+	set := findTopLevel(p.tokens, 0, "SET")
+	if set < 0 {
+		return false
+	}
+	return findTopLevel(p.tokens[:set], 0, "JOIN", "STRAIGHT_JOIN") >= 0 || len(splitTopLevel(p.tokens[:set], ",")) > 1
+}
+
+// isMultiDelete reports whether a DELETE has MySQL's multi-table form:
+// tables before FROM, or FROM ... USING
+func (p *parser) isMultiDelete() bool {
+	n := 1
+	for p.peekN(n).Is("LOW_PRIORITY") || p.peekN(n).Is("QUICK") || p.peekN(n).Is("IGNORE") {
+		n++
+	}
+	if !p.peekN(n).Is("FROM") {
+		return p.peekN(n).IsIdent()
+	}
+	end := len(p.tokens)
+	if where := findTopLevel(p.tokens, 0, "WHERE"); where >= 0 {
+		end = where
+	}
+	return findTopLevel(p.tokens[:end], 0, "USING") >= 0
+}
+
+// parseTableRefs parses MySQL table references: tables with optional
+// aliases and index hints, separated by commas or joins
+func (p *parser) parseTableRefs() ([]*tableRef, error) {
+	var refs []*tableRef
+	join := ""
+	for {
+		ref := &tableRef{Join: join}
+		if p.peek().IsOp("(") {
+			start := p.mark()
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+			ref.Table = p.since(start)
+		} else {
+			var err error
+			if ref.Table, err = p.tableName(); err != nil {
+				return nil, err
+			}
+		}
+		if p.peek().Is("PARTITION") {
+			return nil, fmt.Errorf("PARTITION in table references is not supported on PostgreSQL, use the partition table directly")
+		}
+		hasAs := p.accept("AS")
+		if tok := p.peek(); tok.IsIdent() && !refEndWords[tok.Upper()] && !joinWords[tok.Upper()] {
+			p.next()
+			ref.Alias = &tok
+		} else if hasAs {
+			return nil, p.errorf("expected alias")
+		}
+		// Index hints have no PostgreSQL counterpart
+		for p.accept("USE", "IGNORE", "FORCE") {
+			p.accept("INDEX", "KEY")
+			if p.accept("FOR") {
+				p.next()
+				p.accept("BY")
+			}
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+		}
+		if join != "" && join != "," {
+			switch {
+			case p.accept("ON"):
+				ref.On = p.joinCondition()
+			case p.accept("USING"):
+				start := p.mark()
+				if err := p.skipGroup(); err != nil {
+					return nil, err
+				}
+				ref.Using = p.since(start)
+			}
+		}
+		refs = append(refs, ref)
+
+		if p.acceptOp(",") {
+			join = ","
+			continue
+		}
+		var err error
+		if join, err = p.joinOperator(); err != nil || join == "" {
+			return refs, err
+		}
+	}
+}
+
+// joinOperator consumes a join operator and returns it in PostgreSQL's
+// spelling, or "" if there is none
+func (p *parser) joinOperator() (string, error) {
+	if p.accept("STRAIGHT_JOIN") {
+		return "JOIN", nil
+	}
+	var words []string
+	for p.peek().Is("INNER") || p.peek().Is("CROSS") || p.peek().Is("LEFT") || p.peek().Is("RIGHT") ||
+		p.peek().Is("OUTER") || p.peek().Is("NATURAL") {
+		words = append(words, p.next().Upper())
+	}
+	if !p.accept("JOIN") {
+		if len(words) > 0 {
+			return "", p.errorf("expected JOIN")
+		}
+		return "", nil
+	}
+	return strings.Join(append(words, "JOIN"), " "), nil
+}
+
+// joinCondition consumes an ON condition up to the next join, comma or clause
+func (p *parser) joinCondition() []Token {
+	start := p.mark()
+	for !p.atEnd() {
+		tok := p.peek()
+		word := tok.Upper()
+		if tok.IsOp(",") || refEndWords[word] || joinWords[word] && !p.peekN(1).IsOp("(") {
+			break
+		}
+		if tok.IsOp("(") {
+			if p.skipGroup() != nil {
+				break
+			}
+			continue
+		}
+		p.next()
+	}
+	return p.since(start)
+}
+
+// whereClause consumes an optional WHERE condition, which ends the statement.
+// MySQL rejects ORDER BY and LIMIT in multi-table statements.
+func (p *parser) whereClause() ([]Token, error) {
+	if p.atEnd() {
+		return nil, nil
+	}
+	if !p.accept("WHERE") {
+		return nil, p.errorf("ORDER BY and LIMIT cannot be used with multiple tables")
+	}
+	where := trimTrivia(p.rest())
+	if len(where) == 0 {
+		return nil, p.errorf("expected condition after WHERE")
+	}
+	if findTopLevel(where, 0, "ORDER", "LIMIT") >= 0 {
+		return nil, fmt.Errorf("ORDER BY and LIMIT cannot be used with multiple tables")
+	}
+	p.pos = len(p.tokens)
+	return where, nil
+}
+
+func (p *parser) parseUpdateJoin() (Statement, error) {
+	p.next() // UPDATE
+	p.accept("LOW_PRIORITY")
+	if p.accept("IGNORE") {
+		return nil, fmt.Errorf("UPDATE IGNORE is not supported on PostgreSQL")
+	}
+	stmt := &UpdateJoinStmt{}
+	var err error
+	if stmt.Tables, err = p.parseTableRefs(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("SET"); err != nil {
+		return nil, err
+	}
+
+	rest := p.rest()
+	end := findTopLevel(rest, 0, "WHERE", "ORDER", "LIMIT")
+	if end < 0 {
+		end = len(rest)
+	}
+	for _, part := range splitTopLevel(rest[:end], ",") {
+		eq := -1
+		for i, tok := range part {
+			if tok.IsOp("=") {
+				eq = i
+				break
+			}
+		}
+		if eq < 0 {
+			return nil, fmt.Errorf("syntax error: expected col = value in SET near '%s'", renderTrimmed(part))
+		}
+		column, value := trimTrivia(part[:eq]), trimTrivia(part[eq+1:])
+		if len(column) == 0 || len(value) == 0 {
+			return nil, fmt.Errorf("syntax error: expected col = value in SET near '%s'", renderTrimmed(part))
+		}
+		stmt.Assignments = append(stmt.Assignments, Assignment{Column: column, Value: value})
+	}
+	p.pos += end
+	if stmt.Where, err = p.whereClause(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseDeleteJoin() (Statement, error) {
+	p.next() // DELETE
+	for p.accept("LOW_PRIORITY", "QUICK") {
+	}
+	if p.accept("IGNORE") {
+		return nil, fmt.Errorf("DELETE IGNORE is not supported on PostgreSQL")
+	}
+	using := p.accept("FROM")
+
+	// Targets are written t or t.*, possibly qualified
+	var targets []Token
+	for {
+		tok := p.next()
+		if !tok.IsIdent() {
+			return nil, fmt.Errorf("syntax error: expected table name near '%s'", tok.Text)
+		}
+		for p.peek().IsOp(".") && p.peekN(1).IsIdent() {
+			p.next()
+			tok = p.next()
+		}
+		if p.peek().IsOp(".") && p.peekN(1).IsOp("*") {
+			p.next()
+			p.next()
+		}
+		targets = append(targets, tok)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	keyword := "FROM"
+	if using {
+		keyword = "USING"
+	}
+	if err := p.expect(keyword); err != nil {
+		return nil, err
+	}
+
+	stmt := &DeleteJoinStmt{}
+	var err error
+	if stmt.Tables, err = p.parseTableRefs(); err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		ref := findTableRef(stmt.Tables, target)
+		if ref == nil || !ref.isBase() {
+			return nil, fmt.Errorf("unknown table '%s' in MULTI DELETE", target.Value)
+		}
+		stmt.Targets = append(stmt.Targets, ref)
+	}
+	if stmt.Where, err = p.whereClause(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// findTableRef returns the table reference called name, or nil
+func findTableRef(refs []*tableRef, name Token) *tableRef {
+	for _, ref := range refs {
+		if strings.EqualFold(ref.name().Value, name.Value) {
+			return ref
+		}
+	}
+	return nil
+}
+
+// innerJoinsOnly reports whether the tables are only combined by commas and
+// inner joins with ON, whose conditions can all move to the WHERE clause
+func innerJoinsOnly(refs []*tableRef) bool {
+	for _, ref := range refs {
+		switch ref.Join {
+		case "", ",", "JOIN", "INNER JOIN", "CROSS JOIN":
+		default:
+			return false
+		}
+		if ref.Using != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// rewritten renders a MySQL expression as PostgreSQL
+func (t *Translator) rewritten(tokens []Token) ([]Token, error) {
+	return t.rewriteTokens(trimTrivia(tokens))
+}
+
+// refText renders one table reference without its join
+func (t *Translator) refText(ref *tableRef) (string, error) {
+	table := render(ref.Table)
+	if !ref.isBase() {
+		tokens, err := t.rewritten(ref.Table)
+		if err != nil {
+			return "", err
+		}
+		table = render(tokens)
+	}
+	if ref.Alias != nil {
+		table += " " + ref.Alias.Text
+	}
+	return table, nil
+}
+
+// refsText renders table references as a PostgreSQL FROM list
+func (t *Translator) refsText(refs []*tableRef) (string, error) {
+	var b strings.Builder
+	for _, ref := range refs {
+		text, err := t.refText(ref)
+		if err != nil {
+			return "", err
+		}
+		switch ref.Join {
+		case "":
+		case ",":
+			b.WriteString(", ")
+		default:
+			b.WriteString(" " + ref.Join + " ")
+		}
+		b.WriteString(text)
+		if ref.On != nil {
+			on, err := t.rewritten(ref.On)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(" ON " + render(on))
+		}
+		if ref.Using != nil {
+			b.WriteString(" USING " + render(ref.Using))
+		}
+	}
+	return b.String(), nil
+}
+
+// joinConditions returns the ON conditions of inner joins followed by the
+// WHERE condition, for a WHERE clause that has to do the joining
+func (t *Translator) joinConditions(refs []*tableRef, where []Token) ([][]Token, error) {
+	var conds [][]Token
+	for _, ref := range refs {
+		if ref.On != nil {
+			conds = append(conds, ref.On)
+		}
+	}
+	if where != nil {
+		conds = append(conds, where)
+	}
+	for i, cond := range conds {
+		var err error
+		if conds[i], err = t.rewritten(cond); err != nil {
+			return nil, err
+		}
+	}
+	return conds, nil
+}
+
+// conjunction joins conditions with AND, parenthesizing those with a top-level OR
+func conjunction(conds [][]Token) string {
+	parts := make([]string, len(conds))
+	for i, cond := range conds {
+		parts[i] = render(cond)
+		if len(conds) > 1 && findTopLevel(cond, 0, "OR") >= 0 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// whereText renders a WHERE clause, or "" without a condition
+func (t *Translator) whereText(where []Token) (string, error) {
+	if where == nil {
+		return "", nil
+	}
+	cond, err := t.rewritten(where)
+	if err != nil {
+		return "", err
+	}
+	return " WHERE " + render(cond), nil
+}
+
+// updatedTable finds the table a SET column belongs to: the table its
+// qualifier names, the only table, or else the one table whose definition
+// has the column
+func (t *Translator) updatedTable(refs []*tableRef, column []Token) (*tableRef, error) {
+	name := column[len(column)-1]
+	if len(column) > 1 {
+		qualifier := prevSignificant(column, len(column)-1)
+		qualifier = prevSignificant(column, qualifier)
+		ref := findTableRef(refs, column[qualifier])
+		if ref == nil || !ref.isBase() {
+			return nil, fmt.Errorf("unknown table '%s' in UPDATE", column[qualifier].Value)
+		}
+		return ref, nil
+	}
+	if len(refs) == 1 {
+		return refs[0], nil
+	}
+
+	catalog, err := t.requireCatalog("a multi-table UPDATE with unqualified columns in SET")
+	if err != nil {
+		return nil, err
+	}
+	var found *tableRef
+	for _, ref := range refs {
+		if !ref.isBase() {
+			continue
+		}
+		columns, err := catalog.Columns(render(ref.Table))
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range columns {
+			if col == catalogName(name) {
+				if found != nil && found != ref {
+					return nil, fmt.Errorf("column '%s' in SET is ambiguous, qualify it with its table", name.Value)
+				}
+				found = ref
+			}
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("unknown column '%s' in SET", name.Value)
+	}
+	return found, nil
+}
+
+// translateUpdateJoin turns a multi-table UPDATE into UPDATE ... FROM.
+// PostgreSQL updates a single table, named by the SET columns. Inner joins
+// become the FROM list with their conditions in WHERE. With outer joins the
+// target table cannot be taken out of the join, so the whole join runs in a
+// subquery that finds each row to update and its new values.
+func (t *Translator) translateUpdateJoin(s *UpdateJoinStmt) (*TranslationResult, error) {
+	var target *tableRef
+	for _, a := range s.Assignments {
+		ref, err := t.updatedTable(s.Tables, a.Column)
+		if err != nil {
+			return nil, err
+		}
+		if target != nil && ref != target {
+			return nil, fmt.Errorf("UPDATE of both %s and %s is not supported on PostgreSQL, which updates one table per statement; "+
+				"split it into one UPDATE per table", target.name().Text, ref.name().Text)
+		}
+		target = ref
+	}
+	targetText, err := t.refText(target)
+	if err != nil {
+		return nil, err
+	}
+	targetName := target.name().Text
+
+	inner := innerJoinsOnly(s.Tables)
+	var set, values []string
+	for i, a := range s.Assignments {
+		value, err := t.rewritten(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		column := a.Column[len(a.Column)-1].Text
+		if inner {
+			set = append(set, column+" = "+render(value))
+			continue
+		}
+		set = append(set, fmt.Sprintf("%s = mygo.mygo_%d", column, i+1))
+		values = append(values, fmt.Sprintf("%s AS mygo_%d", render(value), i+1))
+	}
+
+	if inner {
+		var from []string
+		for _, ref := range s.Tables {
+			if ref == target {
+				continue
+			}
+			text, err := t.refText(ref)
+			if err != nil {
+				return nil, err
+			}
+			from = append(from, text)
+		}
+		conds, err := t.joinConditions(s.Tables, s.Where)
+		if err != nil {
+			return nil, err
+		}
+		query := "UPDATE " + targetText + " SET " + strings.Join(set, ", ")
+		if len(from) > 0 {
+			query += " FROM " + strings.Join(from, ", ")
+		}
+		if len(conds) > 0 {
+			query += " WHERE " + conjunction(conds)
+		}
+		return &TranslationResult{Query: query}, nil
+	}
+
+	refs, err := t.refsText(s.Tables)
+	if err != nil {
+		return nil, err
+	}
+	where, err := t.whereText(s.Where)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s FROM (SELECT %s.tableoid AS mygo_table, %s.ctid AS mygo_row, %s FROM %s%s) AS mygo "+
+		"WHERE %s.tableoid = mygo.mygo_table AND %s.ctid = mygo.mygo_row",
+		targetText, strings.Join(set, ", "), targetName, targetName, strings.Join(values, ", "), refs, where, targetName, targetName)
+	return &TranslationResult{Query: query}, nil
+}
+
+// translateDeleteJoin turns a multi-table DELETE into DELETE ... USING. An
+// outer join, which USING cannot express for the target table, runs in a
+// subquery that finds the rows to delete. Deleting from several tables
+// takes one data-modifying WITH query per table, all working from the same
+// join result as MySQL does.
+func (t *Translator) translateDeleteJoin(s *DeleteJoinStmt) (*TranslationResult, error) {
+	refs, err := t.refsText(s.Tables)
+	if err != nil {
+		return nil, err
+	}
+	where, err := t.whereText(s.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.Targets) > 1 {
+		var rows, deletes []string
+		for i, target := range s.Targets {
+			name := target.name().Text
+			rows = append(rows, fmt.Sprintf("%s.tableoid AS mygo_table%d, %s.ctid AS mygo_row%d", name, i+1, name, i+1))
+			deletes = append(deletes, fmt.Sprintf("DELETE FROM %s WHERE (tableoid, ctid) IN (SELECT mygo_table%d, mygo_row%d FROM mygo)",
+				render(target.Table), i+1, i+1))
+		}
+		query := fmt.Sprintf("WITH mygo AS (SELECT %s FROM %s%s)", strings.Join(rows, ", "), refs, where)
+		for i, del := range deletes[:len(deletes)-1] {
+			query += fmt.Sprintf(", mygo_delete%d AS (%s)", i+1, del)
+		}
+		return &TranslationResult{Query: query + " " + deletes[len(deletes)-1]}, nil
+	}
+
+	target := s.Targets[0]
+	targetText, err := t.refText(target)
+	if err != nil {
+		return nil, err
+	}
+	if !innerJoinsOnly(s.Tables) {
+		name := target.name().Text
+		query := fmt.Sprintf("DELETE FROM %s WHERE (%s.tableoid, %s.ctid) IN (SELECT %s.tableoid, %s.ctid FROM %s%s)",
+			targetText, name, name, name, name, refs, where)
+		return &TranslationResult{Query: query}, nil
+	}
+
+	var using []string
+	for _, ref := range s.Tables {
+		if ref == target {
+			continue
+		}
+		text, err := t.refText(ref)
+		if err != nil {
+			return nil, err
+		}
+		using = append(using, text)
+	}
+	conds, err := t.joinConditions(s.Tables, s.Where)
+	if err != nil {
+		return nil, err
+	}
+	query := "DELETE FROM " + targetText
+	if len(using) > 0 {
+		query += " USING " + strings.Join(using, ", ")
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + conjunction(conds)
+	}
+	return &TranslationResult{Query: query}, nil
+}
@@ -0,0 +1,101 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateMultiTable(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{columns: map[string][]string{
+		"orders": {"id", "user_id", "status", "total"},
+		"users":  {"id", "name", "vip"},
+	}})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"UPDATE orders o JOIN users u ON o.user_id = u.id SET o.status = 'vip', o.total = o.total * 0.9 WHERE u.vip = 1 OR u.name = 'root'",
+			"UPDATE orders o SET status = 'vip', total = o.total * 0.9 FROM users u WHERE o.user_id = u.id AND (u.vip = 1 OR u.name = 'root')",
+		},
+		{
+			"UPDATE orders, users SET `status` = 'done' WHERE orders.user_id = users.id",
+			"UPDATE orders SET \"status\" = 'done' FROM users WHERE orders.user_id = users.id",
+		},
+		{
+			"UPDATE orders AS o LEFT JOIN users AS u ON u.id = o.user_id SET o.status = IF(u.id IS NULL, 'orphan', 'ok')",
+			"UPDATE orders o SET status = mygo.mygo_1 FROM (SELECT o.tableoid AS mygo_table, o.ctid AS mygo_row, " +
+				"CASE WHEN u.id IS NULL THEN 'orphan' ELSE 'ok' END AS mygo_1 FROM orders o LEFT JOIN users u ON u.id = o.user_id) AS mygo " +
+				"WHERE o.tableoid = mygo.mygo_table AND o.ctid = mygo.mygo_row",
+		},
+		{
+			"DELETE o FROM orders o STRAIGHT_JOIN users u ON o.user_id = u.id WHERE u.vip = 0",
+			"DELETE FROM orders o USING users u WHERE o.user_id = u.id AND u.vip = 0",
+		},
+		{
+			"DELETE QUICK FROM o USING orders AS o USE INDEX (idx_user) INNER JOIN users AS u ON o.user_id = u.id",
+			"DELETE FROM orders o USING users u WHERE o.user_id = u.id",
+		},
+		{
+			"DELETE o.* FROM orders o LEFT JOIN users u ON u.id = o.user_id WHERE u.id IS NULL",
+			"DELETE FROM orders o WHERE (o.tableoid, o.ctid) IN (SELECT o.tableoid, o.ctid FROM orders o LEFT JOIN users u ON u.id = o.user_id WHERE u.id IS NULL)",
+		},
+		{
+			"DELETE o, u FROM users u JOIN orders o USING (id) WHERE u.vip = 0",
+			"WITH mygo AS (SELECT o.tableoid AS mygo_table1, o.ctid AS mygo_row1, u.tableoid AS mygo_table2, u.ctid AS mygo_row2 " +
+				"FROM users u JOIN orders o USING (id) WHERE u.vip = 0), " +
+				"mygo_delete1 AS (DELETE FROM orders WHERE (tableoid, ctid) IN (SELECT mygo_table1, mygo_row1 FROM mygo)) " +
+				"DELETE FROM users WHERE (tableoid, ctid) IN (SELECT mygo_table2, mygo_row2 FROM mygo)",
+		},
+		// PostgreSQL's own DELETE ... USING is passed through
+		{
+			"DELETE FROM orders USING users WHERE orders.user_id = users.id",
+			"DELETE FROM orders USING users WHERE orders.user_id = users.id",
+		},
+		{
+			"UPDATE orders SET status = 'done' WHERE id = 1",
+			"UPDATE orders SET status = 'done' WHERE id = 1",
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.expected {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.expected)
+		}
+	}
+}
+
+func TestTranslateMultiTableErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{columns: map[string][]string{
+		"orders": {"id", "user_id", "status"},
+		"users":  {"id", "name", "status"},
+	}})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"UPDATE orders o JOIN users u ON o.user_id = u.id SET o.status = 1, u.status = 1", "one table per statement"},
+		{"UPDATE orders o JOIN users u ON o.user_id = u.id SET status = 1", "ambiguous"},
+		{"UPDATE orders o JOIN users u ON o.user_id = u.id SET x.status = 1", "unknown table 'x'"},
+		{"UPDATE orders o, users u SET o.status = 1 ORDER BY o.id LIMIT 1", "ORDER BY and LIMIT"},
+		{"DELETE x FROM orders o JOIN users u ON o.user_id = u.id", "unknown table 'x' in MULTI DELETE"},
+		{"DELETE IGNORE o FROM orders o JOIN users u ON o.user_id = u.id", "DELETE IGNORE"},
+	}
+	for _, tt := range tests {
+		_, err := tr.Translate(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("for %s: got error %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
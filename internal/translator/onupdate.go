@@ -0,0 +1,74 @@
+package translator
+
+import "fmt"
+
+// OnUpdateFunction is the trigger function behind MySQL's ON UPDATE
+// CURRENT_TIMESTAMP. SHOW CREATE TABLE looks for triggers calling it.
+const OnUpdateFunction = "mygo_on_update_current_timestamp"
+
+// onUpdateFunctionSQL sets the column named by the trigger argument when an
+// UPDATE changes some other column and leaves this one alone, as MySQL does.
+// The row goes through jsonb because PL/pgSQL cannot assign a column whose
+// name is only known at run time.
+const onUpdateFunctionSQL = "CREATE OR REPLACE FUNCTION " + OnUpdateFunction + "() RETURNS trigger LANGUAGE plpgsql AS $mygo$ " +
+	"BEGIN IF to_jsonb(NEW) -> TG_ARGV[0] IS NOT DISTINCT FROM to_jsonb(OLD) -> TG_ARGV[0] " +
+	"AND to_jsonb(NEW) - TG_ARGV[0] IS DISTINCT FROM to_jsonb(OLD) - TG_ARGV[0] " +
+	"THEN NEW := jsonb_populate_record(NEW, jsonb_build_object(TG_ARGV[0], CURRENT_TIMESTAMP)); END IF; " +
+	"RETURN NEW; END $mygo$"
+
+// onUpdateValues are the values MySQL accepts for ON UPDATE
+var onUpdateValues = map[string]bool{
+	"CURRENT_TIMESTAMP": true, "NOW": true, "LOCALTIME": true, "LOCALTIMESTAMP": true,
+}
+
+// checkOnUpdate fails for an ON UPDATE attribute other than CURRENT_TIMESTAMP and its synonyms
+func checkOnUpdate(col *ColumnDef) error {
+	if len(col.OnUpdate) == 0 || !onUpdateValues[col.OnUpdate[0].Upper()] {
+		return fmt.Errorf("ON UPDATE %s for column %s is not supported on PostgreSQL", render(col.OnUpdate), col.Name.Text)
+	}
+	return nil
+}
+
+// onUpdateTriggerName names the trigger for a column, table_col_on_update
+func onUpdateTriggerName(table []Token, column string) string {
+	return pgIdent(table[len(table)-1].Value + "_" + column + "_on_update")
+}
+
+// createOnUpdateTrigger creates the trigger that maintains one column
+func createOnUpdateTrigger(table []Token, column string) string {
+	return fmt.Sprintf("CREATE TRIGGER %s BEFORE UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION %s(%s)",
+		onUpdateTriggerName(table, column), render(table), OnUpdateFunction, quoteLiteral(column))
+}
+
+// dropOnUpdateTrigger drops the trigger of a column if it has one
+func dropOnUpdateTrigger(table []Token, column string) string {
+	return fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", onUpdateTriggerName(table, column), render(table))
+}
+
+// onUpdateTriggers installs the trigger function and a trigger for each
+// ON UPDATE CURRENT_TIMESTAMP column among columns, replacing any trigger
+// the column already has if replace is set
+func onUpdateTriggers(table []Token, columns []*ColumnDef, replace bool) []string {
+	var statements []string
+	for _, col := range columns {
+		if col.OnUpdate == nil {
+			continue
+		}
+		if replace {
+			statements = append(statements, dropOnUpdateTrigger(table, catalogName(col.Name)))
+		}
+		statements = append(statements, createOnUpdateTrigger(table, catalogName(col.Name)))
+	}
+	if statements == nil {
+		return nil
+	}
+	return append([]string{onUpdateFunctionSQL}, statements...)
+}
+
+// renameOnUpdateTrigger moves a column's trigger, if it has one, to the
+// column's new name when the column is renamed
+func renameOnUpdateTrigger(table []Token, from, to string) string {
+	return fmt.Sprintf("DO $mygo$ BEGIN IF EXISTS (SELECT FROM pg_trigger WHERE tgrelid = to_regclass(%s) AND tgname = %s) THEN %s; %s; END IF; END $mygo$",
+		quoteLiteral(render(table)), quoteLiteral(table[len(table)-1].Value+"_"+from+"_on_update"),
+		dropOnUpdateTrigger(table, from), createOnUpdateTrigger(table, to))
+}
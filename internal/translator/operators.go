@@ -0,0 +1,353 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rewriteOperators converts MySQL operators whose PostgreSQL spelling or
+// meaning differs:
+//
+//	a || b          -> a OR b (a || b when PIPES_AS_CONCAT is on)
+//	a && b          -> a AND b
+//	a <=> b         -> a IS NOT DISTINCT FROM b
+//	a REGEXP p      -> a ~* p (also RLIKE; NOT REGEXP -> !~*)
+//	a DIV b         -> div(a, b)::bigint
+//	a MOD b         -> a % b
+//	!a              -> (NOT a)
+//	a XOR b         -> (a)::boolean <> (b)::boolean
+func rewriteOperators(tokens []Token, pipesAsConcat bool) ([]Token, error) {
+	out := make([]Token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.IsOp("||") && !pipesAsConcat:
+			out = append(out, Token{Kind: TokIdent, Text: "OR", Value: "OR"})
+
+		case tok.IsOp("&&"):
+			out = append(out, Token{Kind: TokIdent, Text: "AND", Value: "AND"})
+
+		case tok.IsOp("<=>"):
+			out = append(out, pgTokens("IS NOT DISTINCT FROM")...)
+
+		case tok.Is("REGEXP") || tok.Is("RLIKE"):
+			op := "~*"
+			if prev := prevSignificant(out, len(out)); prev >= 0 && out[prev].Is("NOT") {
+				out = out[:prev]
+				op = "!~*"
+			}
+			if next := nextSignificant(tokens, i+1); next < len(tokens) && tokens[next].Is("BINARY") {
+				// Case-sensitive match
+				op = strings.TrimSuffix(op, "*")
+				i = next
+			}
+			out = append(out, Token{Kind: TokOperator, Text: op, Value: op})
+
+		case tok.Is("DIV") && tok.Kind == TokIdent:
+			start := operandStart(out)
+			end := operandEnd(tokens, i+1)
+			if start < 0 || end < 0 {
+				return nil, fmt.Errorf("syntax error: DIV needs two operands")
+			}
+			right := trimTrivia(tokens[i+1 : end+1])
+			div := pgTokens("div(" + renderTrimmed(out[start:]) + ", " + render(right) + ")::bigint")
+			out = append(out[:start], div...)
+			i = end
+
+		case tok.Is("MOD") && tok.Kind == TokIdent && !isFunctionCall(tokens, i) && endsOperand(out):
+			out = append(out, Token{Kind: TokOperator, Text: "%", Value: "%"})
+
+		case tok.IsOp("!"):
+			// ! binds as tightly as unary minus, NOT much more loosely
+			start := nextSignificant(tokens, i+1)
+			for start < len(tokens) && tokens[start].IsOp("!") {
+				start = nextSignificant(tokens, start+1)
+			}
+			end := operandEnd(tokens, start)
+			if end < 0 {
+				return nil, fmt.Errorf("syntax error: ! needs an operand")
+			}
+			operand, err := rewriteOperators(tokens[i+1:end+1], pipesAsConcat)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, pgTokens("(NOT "+renderTrimmed(operand)+")")...)
+			i = end
+
+		case tok.Is("XOR") && tok.Kind == TokIdent:
+			start := nextSignificant(out, booleanStart(out))
+			end := booleanEnd(tokens, i+1)
+			left, right := trimTrivia(out[start:]), trimTrivia(tokens[i+1:end])
+			if len(left) == 0 || len(right) == 0 {
+				return nil, fmt.Errorf("syntax error: XOR needs two operands")
+			}
+			// Cast the operands so that numbers XOR as truth values, 2 XOR 1 being false
+			xor := pgTokens("(" + render(left) + ")::boolean <> (" + render(right) + ")::boolean")
+			out = append(out[:start], xor...)
+			i = end - 1
+
+		default:
+			out = append(out, tok)
+		}
+	}
+	return out, nil
+}
+
+// primaryStart returns the index of the first token of the operand ending at
+// the last significant token: a literal, a possibly qualified name, a
+// parenthesized group or a function call. It returns -1 if there is none.
+func primaryStart(tokens []Token, end int) int {
+	i := prevSignificant(tokens, end+1)
+	if i < 0 {
+		return -1
+	}
+	if tokens[i].IsOp(")") {
+		depth := 0
+		for ; i >= 0; i-- {
+			if tokens[i].IsOp(")") {
+				depth++
+			} else if tokens[i].IsOp("(") {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if i < 0 {
+			return -1
+		}
+		if i > 0 && tokens[i-1].Kind == TokIdent && !exprKeywords[tokens[i-1].Upper()] {
+			i-- // function call
+		}
+		return i
+	}
+	switch tokens[i].Kind {
+	case TokIdent, TokQuotedIdent, TokNumber, TokString, TokParam, TokVariable, TokSystemVariable, TokHex, TokBit:
+	default:
+		return -1
+	}
+	for i >= 2 && tokens[i-1].IsOp(".") && tokens[i-2].IsIdent() {
+		i -= 2
+	}
+	return i
+}
+
+// endsOperand reports whether the last significant token ends an operand,
+// making a following word a binary operator
+func endsOperand(tokens []Token) bool {
+	prev := prevSignificant(tokens, len(tokens))
+	if prev < 0 {
+		return false
+	}
+	switch tok := tokens[prev]; tok.Kind {
+	case TokIdent:
+		return !exprKeywords[tok.Upper()]
+	case TokOperator:
+		return tok.IsOp(")")
+	}
+	return true
+}
+
+// operandStart returns where the left operand of a multiplicative operator
+// begins in tokens, which end right before the operator. Chains such as
+// a * b DIV c group to the left, as in MySQL.
+func operandStart(tokens []Token) int {
+	start := primaryStart(tokens, len(tokens)-1)
+	for start > 0 {
+		op := prevSignificant(tokens, start)
+		if op < 0 || !(tokens[op].IsOp("*") || tokens[op].IsOp("/") || tokens[op].IsOp("%") || tokens[op].Is("MOD") || tokens[op].Is("DIV")) {
+			break
+		}
+		prev := primaryStart(tokens, op-1)
+		if prev < 0 {
+			break
+		}
+		start = prev
+	}
+	return start
+}
+
+// operandEnd returns the index of the last token of the operand starting at
+// or after from, or -1 if there is none
+func operandEnd(tokens []Token, from int) int {
+	i := nextSignificant(tokens, from)
+	if i < len(tokens) && (tokens[i].IsOp("-") || tokens[i].IsOp("+")) {
+		i = nextSignificant(tokens, i+1)
+	}
+	if i >= len(tokens) {
+		return -1
+	}
+	if tokens[i].IsIdent() {
+		for i+2 < len(tokens) && tokens[i+1].IsOp(".") && tokens[i+2].IsIdent() {
+			i += 2
+		}
+		if i+1 < len(tokens) && tokens[i+1].IsOp("(") {
+			i++ // function call
+		}
+	}
+	if tokens[i].IsOp("(") {
+		return matchParen(tokens, i)
+	}
+	if tokens[i].IsOp(")") || tokens[i].IsOp(",") {
+		return -1
+	}
+	return i
+}
+
+// booleanStops end a boolean operand: operators binding looser than XOR
+// and the clause keywords around a condition
+var booleanStops = map[string]bool{
+	"OR": true, "XOR": true, "WHERE": true, "ON": true, "HAVING": true, "WHEN": true,
+	"THEN": true, "ELSE": true, "END": true, "SELECT": true, "SET": true, "FROM": true,
+	"GROUP": true, "ORDER": true, "LIMIT": true, "AS": true, "UNION": true, "RETURN": true,
+}
+
+// booleanStart returns where the left operand of XOR begins in tokens
+func booleanStart(tokens []Token) int {
+	depth := 0
+	for i := len(tokens) - 1; i >= 0; i-- {
+		tok := tokens[i]
+		switch {
+		case tok.IsOp(")"):
+			depth++
+		case tok.IsOp("("):
+			if depth == 0 {
+				return i + 1
+			}
+			depth--
+		case depth > 0:
+		case tok.IsOp(",") || tok.IsOp(";") || (tok.Kind == TokIdent && booleanStops[tok.Upper()]):
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// booleanEnd returns the index just past the right operand of XOR
+func booleanEnd(tokens []Token, from int) int {
+	depth := 0
+	for i := from; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.IsOp("("):
+			depth++
+		case tok.IsOp(")"):
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case depth > 0:
+		case tok.IsOp(",") || tok.IsOp(";") || (tok.Kind == TokIdent && booleanStops[tok.Upper()]):
+			return i
+		}
+	}
+	return len(tokens)
+}
+
+// castTypes maps MySQL CAST/CONVERT target types to PostgreSQL types
+var castTypes = map[string]string{
+	"SIGNED":   "bigint",
+	"UNSIGNED": "bigint",
+	"CHAR":     "text",
+	"NCHAR":    "text",
+	"DATETIME": "timestamp",
+	"BINARY":   "bytea",
+	"DOUBLE":   "double precision",
+	"FLOAT":    "real",
+	"REAL":     "double precision",
+	"JSON":     "jsonb",
+	"YEAR":     "integer",
+}
+
+// mysqlCastType converts the type of a CAST target
+func mysqlCastType(target []Token) (string, error) {
+	target = trimTrivia(target)
+	if len(target) == 0 {
+		return "", fmt.Errorf("syntax error: missing CAST target type")
+	}
+	name := target[0].Upper()
+	pgType, ok := castTypes[name]
+	if !ok {
+		// DATE, TIME, DECIMAL(p,s) and friends are spelled the same way
+		return render(target), nil
+	}
+
+	rest := trimTrivia(target[1:])
+	var length string
+	if len(rest) > 0 && rest[0].IsOp("(") {
+		end := matchParen(rest, 0)
+		if end < 0 {
+			return "", fmt.Errorf("syntax error: unbalanced parentheses near '%s'", render(target))
+		}
+		length = render(rest[:end+1])
+		rest = trimTrivia(rest[end+1:])
+	}
+	switch name {
+	case "SIGNED", "UNSIGNED":
+		// SIGNED INTEGER, UNSIGNED INT
+		length = ""
+		if len(rest) > 0 && (rest[0].Is("INTEGER") || rest[0].Is("INT")) {
+			rest = rest[1:]
+		}
+	case "CHAR", "NCHAR":
+		if length != "" {
+			pgType = "varchar"
+		}
+		// CHAR CHARACTER SET utf8mb4 only names an encoding
+		rest = nil
+	case "DATETIME":
+	default:
+		length = ""
+	}
+	if len(trimTrivia(rest)) > 0 {
+		return "", fmt.Errorf("CAST to %s is not supported on PostgreSQL", render(target))
+	}
+	return pgType + length, nil
+}
+
+// CAST(x AS type) with the target type converted
+func rewriteCast(c *funcCall) (string, error) {
+	as := findTopLevel(c.Inner, 0, "AS")
+	if as < 0 {
+		return "", fmt.Errorf("syntax error: expected CAST(expr AS type)")
+	}
+	pgType, err := mysqlCastType(c.Inner[as+1:])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("CAST(%s AS %s)", renderTrimmed(c.Inner[:as]), pgType), nil
+}
+
+// CONVERT(x, type) -> CAST(x AS type); CONVERT(x USING charset) -> x
+func rewriteConvert(c *funcCall) (string, error) {
+	if using := findTopLevel(c.Inner, 0, "USING"); using >= 0 {
+		return "(" + renderTrimmed(c.Inner[:using]) + ")", nil
+	}
+	if err := c.argCount(2, 2); err != nil {
+		return "", err
+	}
+	pgType, err := mysqlCastType(c.Args[1])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("CAST(%s AS %s)", c.arg(0), pgType), nil
+}
+
+// REGEXP_LIKE(s, pattern [, 'c']) -> s ~* pattern, or ~ for case-sensitive matching
+func rewriteRegexpLike(c *funcCall) (string, error) {
+	if err := c.argCount(2, 3); err != nil {
+		return "", err
+	}
+	op := "~*"
+	if len(c.Args) == 3 {
+		mode := c.Args[2]
+		if len(mode) != 1 || mode[0].Kind != TokString {
+			return "", fmt.Errorf("%s needs a literal match type to be translated to PostgreSQL", c.Name)
+		}
+		// The last of c (case-sensitive) and i (insensitive) wins
+		if strings.LastIndex(mode[0].Value, "c") > strings.LastIndex(mode[0].Value, "i") {
+			op = "~"
+		}
+	}
+	return fmt.Sprintf("(%s %s %s)", c.arg(0), op, c.arg(1)), nil
+}
@@ -0,0 +1,80 @@
+package translator
+
+import (
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateOperators(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT * FROM t WHERE a = 1 || b = 2", "SELECT * FROM t WHERE a = 1 OR b = 2"},
+		{"SELECT * FROM t WHERE a = 1 && b = 2", "SELECT * FROM t WHERE a = 1 AND b = 2"},
+		{"SELECT * FROM t WHERE a <=> NULL", "SELECT * FROM t WHERE a IS NOT DISTINCT FROM NULL"},
+		{"SELECT * FROM t WHERE name REGEXP '^ab'", "SELECT * FROM t WHERE name ~* '^ab'"},
+		{"SELECT * FROM t WHERE name NOT RLIKE '^ab'", "SELECT * FROM t WHERE name !~* '^ab'"},
+		{"SELECT * FROM t WHERE name REGEXP BINARY '^Ab'", "SELECT * FROM t WHERE name ~ '^Ab'"},
+		{"SELECT 7 DIV 2", "SELECT div(7, 2)::bigint"},
+		{"SELECT total DIV (n + 1) FROM t", "SELECT div(total, (n + 1))::bigint FROM t"},
+		{"SELECT a * b DIV c FROM t", "SELECT div(a * b, c)::bigint FROM t"},
+		{"SELECT t.a DIV 2 + 1 FROM t", "SELECT div(t.a, 2)::bigint + 1 FROM t"},
+		{"SELECT 7 MOD 2, a MOD (b + 1) FROM t", "SELECT 7 % 2, a % (b + 1) FROM t"},
+		{"SELECT MOD(7, 2), mod FROM t", "SELECT MOD(7, 2), mod FROM t"},
+		{"SELECT * FROM t WHERE !active", "SELECT * FROM t WHERE (NOT active)"},
+		{"SELECT !a = b, !!(a > 1), a != b FROM t", "SELECT (NOT a) = b, (NOT (NOT (a > 1))), a != b FROM t"},
+		{"SELECT * FROM t WHERE !(a && b)", "SELECT * FROM t WHERE (NOT (a AND b))"},
+		{"SELECT ! ! -x FROM t", "SELECT (NOT (NOT -x)) FROM t"},
+		{"SELECT * FROM t WHERE a > 1 XOR b > 1", "SELECT * FROM t WHERE (a > 1)::boolean <> (b > 1)::boolean"},
+		{"SELECT * FROM t WHERE x = 1 AND (a XOR b) OR c", "SELECT * FROM t WHERE x = 1 AND ((a)::boolean <> (b)::boolean) OR c"},
+		{"SELECT 2 XOR 1", "SELECT (2)::boolean <> (1)::boolean"},
+		{"SELECT 1 XOR 0 XOR 1", "SELECT ((1)::boolean <> (0)::boolean)::boolean <> (1)::boolean"},
+		{"SELECT CAST(price AS SIGNED) FROM t", "SELECT CAST(price AS bigint) FROM t"},
+		{"SELECT CAST(n AS UNSIGNED INTEGER) FROM t", "SELECT CAST(n AS bigint) FROM t"},
+		{"SELECT CAST(id AS CHAR) FROM t", "SELECT CAST(id AS text) FROM t"},
+		{"SELECT CAST(id AS CHAR(10)) FROM t", "SELECT CAST(id AS varchar(10)) FROM t"},
+		{"SELECT CAST('2024-01-01 10:00' AS DATETIME)", "SELECT CAST('2024-01-01 10:00' AS timestamp)"},
+		{"SELECT CAST(x AS DECIMAL(10,2)) FROM t", "SELECT CAST(x AS DECIMAL(10,2)) FROM t"},
+		{"SELECT CONVERT(x, SIGNED) FROM t", "SELECT CAST(x AS bigint) FROM t"},
+		{"SELECT CONVERT(name USING utf8mb4) FROM t", "SELECT (name) FROM t"},
+		{"SELECT IFNULL(a, 0) || b FROM t", "SELECT COALESCE(a, 0) OR b FROM t"},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.want)
+		}
+	}
+}
+
+func TestTranslatePipesAsConcat(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetPipesAsConcat(true)
+
+	result, err := tr.Translate("SELECT first || ' ' || last FROM t")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT first || ' ' || last FROM t"; result.Query != want {
+		t.Errorf("got: %s\nwant: %s", result.Query, want)
+	}
+
+	// GROUP_CONCAT joins several expressions with ||, which must survive either way
+	tr.SetPipesAsConcat(false)
+	result, err = tr.Translate("SELECT GROUP_CONCAT(a, b) FROM t")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT string_agg(a::text || b::text, ',') FROM t"; result.Query != want {
+		t.Errorf("got: %s\nwant: %s", result.Query, want)
+	}
+}
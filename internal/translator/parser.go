@@ -0,0 +1,546 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse lexes and parses a single MySQL statement. A trailing semicolon is ignored.
+func Parse(input string) (Statement, error) {
+	tokens, err := Lex(input)
+	if err != nil {
+		return nil, err
+	}
+	return parseTokens(tokens)
+}
+
+// parseTokens parses an already lexed statement
+func parseTokens(tokens []Token) (Statement, error) {
+	p := newParser(trimTerminator(tokens))
+	return p.parseStatement()
+}
+
+// trimTerminator drops trailing semicolons and the trivia around them
+func trimTerminator(tokens []Token) []Token {
+	end := len(tokens)
+	for end > 0 && (tokens[end-1].IsTrivia() || tokens[end-1].IsOp(";")) {
+		end--
+	}
+	start := 0
+	for start < end && tokens[start].IsTrivia() {
+		start++
+	}
+	return tokens[start:end]
+}
+
+// parser walks a token stream, skipping whitespace and comments
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func newParser(tokens []Token) *parser {
+	return &parser{tokens: tokens}
+}
+
+func (p *parser) skipTrivia() {
+	for p.pos < len(p.tokens) && p.tokens[p.pos].IsTrivia() {
+		p.pos++
+	}
+}
+
+// peek returns the next significant token without consuming it
+func (p *parser) peek() Token {
+	return p.peekN(0)
+}
+
+// peekN returns the n-th significant token ahead without consuming anything
+func (p *parser) peekN(n int) Token {
+	for i := p.pos; i < len(p.tokens); i++ {
+		if p.tokens[i].IsTrivia() {
+			continue
+		}
+		if n == 0 {
+			return p.tokens[i]
+		}
+		n--
+	}
+	return Token{Kind: TokEOF}
+}
+
+// next consumes and returns the next significant token
+func (p *parser) next() Token {
+	p.skipTrivia()
+	if p.pos >= len(p.tokens) {
+		return Token{Kind: TokEOF}
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok
+}
+
+// atEnd reports whether only trivia remains
+func (p *parser) atEnd() bool {
+	return p.peek().Kind == TokEOF
+}
+
+// accept consumes the next token if it is one of the given keywords
+func (p *parser) accept(keywords ...string) bool {
+	tok := p.peek()
+	for _, kw := range keywords {
+		if tok.Is(kw) {
+			p.next()
+			return true
+		}
+	}
+	return false
+}
+
+// acceptSeq consumes a sequence of keywords only if all of them match
+func (p *parser) acceptSeq(keywords ...string) bool {
+	for i, kw := range keywords {
+		if !p.peekN(i).Is(kw) {
+			return false
+		}
+	}
+	for range keywords {
+		p.next()
+	}
+	return true
+}
+
+// acceptOp consumes the next token if it is the given operator
+func (p *parser) acceptOp(op string) bool {
+	if p.peek().IsOp(op) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// expect consumes the given keyword or fails
+func (p *parser) expect(keyword string) error {
+	if !p.accept(keyword) {
+		return p.errorf("expected %s", keyword)
+	}
+	return nil
+}
+
+// expectOp consumes the given operator or fails
+func (p *parser) expectOp(op string) error {
+	if !p.acceptOp(op) {
+		return p.errorf("expected '%s'", op)
+	}
+	return nil
+}
+
+// expectEnd fails unless the statement has been fully consumed
+func (p *parser) expectEnd() error {
+	if !p.atEnd() {
+		return p.errorf("unexpected input")
+	}
+	return nil
+}
+
+// ident consumes an identifier and returns its name
+func (p *parser) ident() (string, error) {
+	tok := p.peek()
+	if !tok.IsIdent() {
+		return "", p.errorf("expected identifier")
+	}
+	p.next()
+	return tok.Value, nil
+}
+
+// qualifiedName consumes a dotted name such as db.table
+func (p *parser) qualifiedName() ([]string, error) {
+	first, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{first}
+	for p.peek().IsOp(".") {
+		p.next()
+		part, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// stringLiteral consumes a quoted string and returns its decoded value
+func (p *parser) stringLiteral() (string, error) {
+	tok := p.peek()
+	if tok.Kind != TokString && tok.Kind != TokDoubleString {
+		return "", p.errorf("expected string literal")
+	}
+	p.next()
+	return tok.Value, nil
+}
+
+// mark returns the position of the next significant token
+func (p *parser) mark() int {
+	p.skipTrivia()
+	return p.pos
+}
+
+// since returns the tokens consumed after start, without surrounding trivia
+func (p *parser) since(start int) []Token {
+	return trimTrivia(p.tokens[start:p.pos])
+}
+
+// skipGroup consumes a parenthesized group
+func (p *parser) skipGroup() error {
+	start := p.mark()
+	end := matchParen(p.tokens, start)
+	if end < 0 {
+		return p.errorf("unbalanced parentheses")
+	}
+	p.pos = end + 1
+	return nil
+}
+
+// rest returns the unconsumed tokens, including trivia
+func (p *parser) rest() []Token {
+	return p.tokens[p.pos:]
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	tok := p.peek()
+	if tok.Kind == TokEOF {
+		return fmt.Errorf("syntax error: %s at end of input", msg)
+	}
+	return fmt.Errorf("syntax error: %s near '%s'", msg, tok.Text)
+}
+
+// helpRequested reports whether the statement ends with "--help"
+func (p *parser) helpRequested() bool {
+	n := len(p.tokens)
+	return n >= 3 && p.tokens[n-1].Is("HELP") && p.tokens[n-2].IsOp("-") && p.tokens[n-3].IsOp("-")
+}
+
+// helpTopic builds a HelpStmt from the words preceding "--help"
+func (p *parser) helpTopic() *HelpStmt {
+	var words []string
+	for _, tok := range p.tokens[:len(p.tokens)-3] {
+		if !tok.IsTrivia() {
+			words = append(words, strings.ToUpper(tok.Text))
+		}
+	}
+	return &HelpStmt{Topic: strings.Join(words, " ")}
+}
+
+func (p *parser) parseStatement() (Statement, error) {
+	if p.helpRequested() {
+		return p.helpTopic(), nil
+	}
+
+	switch p.peek().Upper() {
+	case "SHOW":
+		return p.parseShow()
+	case "DESC", "DESCRIBE", "EXPLAIN":
+		if next := p.peekN(1); next.IsIdent() && !explainable[next.Upper()] {
+			return p.parseDescribe()
+		}
+	case "USE":
+		return p.parseUse()
+	case "INSERT", "REPLACE":
+		return p.parseInsert()
+	case "UPDATE":
+		if p.isMultiUpdate() {
+			return p.parseUpdateJoin()
+		}
+	case "DELETE":
+		switch {
+		case !p.isMultiDelete():
+		case p.peekN(1).Is("FROM"):
+			// DELETE FROM t USING is also PostgreSQL's form
+			return p.parseOrRaw(p.parseDeleteJoin)
+		default:
+			return p.parseDeleteJoin()
+		}
+	case "CREATE":
+		switch {
+		case p.isCreateTable():
+			return p.parseCreateTable()
+		case p.peekN(1).Is("DATABASE"):
+			return p.parseCreateDatabase()
+		case p.peekN(1).Is("USER"):
+			return p.parseOrRaw(p.parseCreateUser)
+		case p.isCreateIndex():
+			return p.parseOrRaw(p.parseCreateIndex)
+		case p.isCreateView():
+			return p.parseOrRaw(p.parseCreateView)
+		case p.isCreateRoutine():
+			return p.parseOrRaw(p.parseCreateRoutine)
+		case p.isCreateWithDefiner("TRIGGER"):
+			return p.parseOrRaw(p.parseCreateTrigger)
+		}
+	case "ALTER":
+		switch {
+		case p.isAlterTable():
+			return p.parseOrRaw(p.parseAlterTable)
+		case p.peekN(1).Is("USER"):
+			return p.parseOrRaw(p.parseCreateUser)
+		case p.isCreateView():
+			return p.parseOrRaw(p.parseCreateView)
+		}
+	case "RENAME":
+		switch {
+		case p.peekN(1).Is("TABLE"):
+			return p.parseRenameTable()
+		case p.peekN(1).Is("USER"):
+			return p.parseRenameUser()
+		}
+	case "DROP":
+		switch {
+		case p.isDropIndexOn():
+			return p.parseDropIndexOn()
+		case p.peekN(1).Is("DATABASE"):
+			return p.parseDropDatabase()
+		case p.peekN(1).Is("USER"):
+			return p.parseDropUser()
+		case p.peekN(1).Is("TRIGGER"):
+			return p.parseOrRaw(p.parseDropTrigger)
+		case p.peekN(1).Is("PREPARE"):
+			return p.parseOrRaw(p.parseDeallocate)
+		}
+	case "TRUNCATE":
+		return p.parseOrRaw(p.parseTruncate)
+	case "GRANT", "REVOKE":
+		return p.parseOrRaw(p.parseGrant)
+	case "SET":
+		switch {
+		case p.peekN(1).Is("PASSWORD"):
+			return p.parseSetPassword()
+		case p.mixesVariables():
+			return nil, fmt.Errorf("user variables and system variables must be set in separate SET statements")
+		case p.isSetTransaction():
+			return p.parseSetTransaction()
+		case p.peekN(1).Kind == TokVariable:
+			return p.parseOrRaw(p.parseSetVariables)
+		default:
+			return p.parseOrRaw(p.parseSet)
+		}
+	case "CALL":
+		return p.parseOrRaw(p.parseCall)
+	case "PREPARE":
+		return p.parseOrRaw(p.parsePrepare)
+	case "EXECUTE":
+		return p.parseOrRaw(p.parseExecute)
+	case "DEALLOCATE":
+		return p.parseOrRaw(p.parseDeallocate)
+	case "START":
+		if p.peekN(1).Is("TRANSACTION") {
+			return p.parseTransaction()
+		}
+	case "BEGIN", "SAVEPOINT":
+		return p.parseTransaction()
+	case "COMMIT", "ROLLBACK":
+		// COMMIT PREPARED and ROLLBACK PREPARED are PostgreSQL's
+		if !p.peekN(1).Is("PREPARED") {
+			return p.parseTransaction()
+		}
+	case "RELEASE":
+		return p.parseOrRaw(p.parseTransaction)
+	case "XA":
+		return p.parseXA()
+	case "LOCK":
+		switch {
+		case p.peekN(1).Is("TABLES"):
+			return p.parseLockTables()
+		case p.peekN(1).Is("TABLE"):
+			// LOCK TABLE t IN mode MODE is PostgreSQL's form
+			return p.parseOrRaw(p.parseLockTables)
+		}
+	case "UNLOCK":
+		return p.parseUnlockTables()
+	case "FLUSH":
+		if p.peekN(1).Is("PRIVILEGES") && p.peekN(2).Kind == TokEOF {
+			return &FlushPrivilegesStmt{}, nil
+		}
+	}
+	return &RawStmt{Tokens: p.tokens}, nil
+}
+
+// parseOrRaw parses a MySQL form whose leading words PostgreSQL shares. A
+// statement that does not parse is left raw, as it may be PostgreSQL's own
+// form, such as CREATE INDEX CONCURRENTLY or TRUNCATE a, b CASCADE.
+func (p *parser) parseOrRaw(parse func() (Statement, error)) (Statement, error) {
+	if stmt, err := parse(); err == nil {
+		return stmt, nil
+	}
+	return &RawStmt{Tokens: p.tokens}, nil
+}
+
+// explainable lists the words that turn DESC/EXPLAIN into a query plan request
+var explainable = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true,
+	"WITH": true, "TABLE": true, "FORMAT": true, "ANALYZE": true, "EXTENDED": true,
+	"PARTITIONS": true, "FOR": true, "VALUES": true,
+}
+
+// showObjects normalizes the object words of SHOW statements
+var showObjects = map[string]string{
+	"FIELDS":          "COLUMNS",
+	"INDEXES":         "INDEX",
+	"KEYS":            "INDEX",
+	"STORAGE ENGINES": "ENGINES",
+	"CHARACTER SET":   "CHARSET",
+	"SCHEMAS":         "SCHEMAS",
+}
+
+// showClauseWords end the object words of a SHOW statement
+var showClauseWords = map[string]bool{"FROM": true, "IN": true, "LIKE": true, "WHERE": true, "FOR": true}
+
+func (p *parser) parseShow() (Statement, error) {
+	p.next() // SHOW
+	stmt := &ShowStmt{}
+
+	for {
+		switch {
+		case p.accept("FULL"):
+			stmt.Full = true
+			continue
+		case p.accept("GLOBAL"):
+			stmt.Global = true
+			continue
+		case p.accept("SESSION", "LOCAL"):
+			continue
+		}
+		break
+	}
+
+	var words []string
+	if p.accept("CREATE") {
+		kind, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		words = []string{"CREATE", strings.ToUpper(kind)}
+		name, err := p.qualifiedName()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Name = name[len(name)-1]
+		if len(name) > 1 {
+			stmt.Database = name[0]
+		}
+	} else {
+		for tok := p.peek(); tok.Kind == TokIdent && !showClauseWords[tok.Upper()]; tok = p.peek() {
+			words = append(words, tok.Upper())
+			p.next()
+		}
+		if len(words) == 0 {
+			return nil, p.errorf("expected SHOW object")
+		}
+	}
+	stmt.Object = strings.Join(words, " ")
+	if normalized, ok := showObjects[stmt.Object]; ok {
+		stmt.Object = normalized
+	}
+
+	if stmt.Object == "GRANTS" && p.accept("FOR") {
+		user, host, err := p.userSpec()
+		if err != nil {
+			return nil, err
+		}
+		stmt.User, stmt.Host = user, host
+	}
+
+	for p.accept("FROM", "IN") {
+		name, err := p.qualifiedName()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case stmt.Object == "COLUMNS" || stmt.Object == "INDEX":
+			if stmt.Name == "" {
+				stmt.Name = name[len(name)-1]
+				if len(name) > 1 {
+					stmt.Database = name[0]
+				}
+			} else {
+				stmt.Database = name[0]
+			}
+		default:
+			stmt.Database = name[0]
+		}
+	}
+
+	switch {
+	case p.accept("LIKE"):
+		pattern, err := p.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Like, stmt.HasLike = pattern, true
+	case p.accept("WHERE"):
+		stmt.Where = p.rest()
+		p.pos = len(p.tokens)
+	}
+
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// userSpec consumes a MySQL account name: user, 'user', 'user'@'host' or CURRENT_USER()
+func (p *parser) userSpec() (user, host string, err error) {
+	tok := p.next()
+	switch {
+	case tok.Is("CURRENT_USER"):
+		if p.acceptOp("(") {
+			if err := p.expectOp(")"); err != nil {
+				return "", "", err
+			}
+		}
+		return "CURRENT_USER", "", nil
+	case tok.IsIdent(), tok.Kind == TokString, tok.Kind == TokDoubleString:
+		user = tok.Value
+	default:
+		return "", "", fmt.Errorf("syntax error: expected user name near '%s'", tok.Text)
+	}
+	// The lexer reads the @host suffix as a user variable
+	if hostTok := p.peek(); hostTok.Kind == TokVariable {
+		p.next()
+		host = hostTok.Value
+	}
+	return user, host, nil
+}
+
+func (p *parser) parseDescribe() (Statement, error) {
+	p.next() // DESC / DESCRIBE / EXPLAIN
+	name, err := p.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &DescribeStmt{Table: name[len(name)-1]}
+	if len(name) > 1 {
+		stmt.Database = name[0]
+	}
+	if tok := p.peek(); tok.IsIdent() || tok.Kind == TokString {
+		p.next()
+		stmt.Column = tok.Value
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseUse() (Statement, error) {
+	p.next() // USE
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return &UseStmt{Database: name}, nil
+}
@@ -0,0 +1,134 @@
+package translator
+
+import (
+	"testing"
+)
+
+func TestLexRoundTrip(t *testing.T) {
+	inputs := []string{
+		"SELECT `order`, \"it\\\"s\" FROM t WHERE a <=> b -- trailing",
+		"SELECT x'4142', b'101', 0x1F, @v, @@session.sql_mode # note",
+		"SELECT /* block */ 1.5e3, .5, 'a''b' FROM `we``ird`",
+	}
+
+	for _, input := range inputs {
+		tokens, err := Lex(input)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", input, err)
+		}
+		if got := render(tokens); got != input {
+			t.Errorf("round trip mismatch: got %s, want %s", got, input)
+		}
+	}
+}
+
+func TestLexTokenKinds(t *testing.T) {
+	tokens, err := Lex("SELECT `a``b`, 'it\\'s', \"dq\", x'ff', b'10', @u, @@version, ? <=> ->>")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []Token
+	for _, tok := range tokens {
+		if !tok.IsTrivia() && !tok.IsOp(",") {
+			got = append(got, tok)
+		}
+	}
+
+	want := []struct {
+		kind  TokenKind
+		value string
+	}{
+		{TokIdent, "SELECT"},
+		{TokQuotedIdent, "a`b"},
+		{TokString, "it's"},
+		{TokDoubleString, "dq"},
+		{TokHex, "ff"},
+		{TokBit, "10"},
+		{TokVariable, "u"},
+		{TokSystemVariable, "version"},
+		{TokParam, ""},
+		{TokOperator, "<=>"},
+		{TokOperator, "->>"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d tokens, got %d: %v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || got[i].Value != w.value {
+			t.Errorf("token %d: expected kind %d value %q, got kind %d value %q", i, w.kind, w.value, got[i].Kind, got[i].Value)
+		}
+	}
+}
+
+func TestLexUnterminatedString(t *testing.T) {
+	if _, err := Lex("SELECT 'abc"); err == nil {
+		t.Error("expected error for unterminated string")
+	}
+}
+
+func TestParseShow(t *testing.T) {
+	stmt, err := Parse("SHOW FULL COLUMNS FROM `shop`.`users` LIKE 'id%';")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	show, ok := stmt.(*ShowStmt)
+	if !ok {
+		t.Fatalf("expected *ShowStmt, got %T", stmt)
+	}
+	if show.Object != "COLUMNS" || !show.Full || show.Name != "users" || show.Database != "shop" {
+		t.Errorf("unexpected statement: %+v", show)
+	}
+	if !show.HasLike || show.Like != "id%" {
+		t.Errorf("expected LIKE 'id%%', got: %+v", show)
+	}
+}
+
+func TestParseShowGrantsFor(t *testing.T) {
+	stmt, err := Parse("SHOW GRANTS FOR 'app'@'%'")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	show := stmt.(*ShowStmt)
+	if show.User != "app" || show.Host != "%" {
+		t.Errorf("expected app@%%, got %s@%s", show.User, show.Host)
+	}
+}
+
+func TestParseHelp(t *testing.T) {
+	stmt, err := Parse("show create --help;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	help, ok := stmt.(*HelpStmt)
+	if !ok || help.Topic != "SHOW CREATE" {
+		t.Errorf("expected help for SHOW CREATE, got %#v", stmt)
+	}
+}
+
+func TestParseDescribeVsExplain(t *testing.T) {
+	stmt, err := Parse("DESCRIBE orders status")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if desc, ok := stmt.(*DescribeStmt); !ok || desc.Table != "orders" || desc.Column != "status" {
+		t.Errorf("expected DESCRIBE orders status, got %#v", stmt)
+	}
+
+	stmt, err = Parse("DESC SELECT 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := stmt.(*RawStmt); !ok {
+		t.Errorf("expected DESC SELECT to stay a raw statement, got %T", stmt)
+	}
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	if _, err := Parse("SHOW TABLES FROM"); err == nil {
+		t.Error("expected syntax error for SHOW TABLES FROM without a name")
+	}
+}
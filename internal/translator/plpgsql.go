@@ -0,0 +1,1020 @@
+package translator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// plpgsql translates the compound statement of a stored routine to
+// PL/pgSQL. Constructs PL/pgSQL cannot express are collected as problems,
+// so that all of them are reported at once.
+type plpgsql struct {
+	t        *Translator // Translates the SQL statements of the body
+	p        *parser
+	function bool
+	trigger  bool              // Translating a trigger body, where RETURN and COMMIT are not allowed
+	blocks   []*block          // Enclosing BEGIN ... END blocks, innermost last
+	handling int               // Depth of handler statements being translated
+	labels   int               // Number of block labels generated
+	vars     map[string]string // PostgreSQL types of parameters and variables, by lower-cased name
+	problems []string
+	warnings []string
+}
+
+// block is a BEGIN ... END block with the conditions and handlers it declares
+type block struct {
+	label      string
+	conditions map[string]string // DECLARE ... CONDITION, by lower-cased name
+	notFound   *handler          // Run where FETCH or SELECT ... INTO finds no row
+	exits      []*handler        // EXIT handlers for errors: the block's EXCEPTION clause
+	continues  []*handler        // CONTINUE handlers for errors: each statement is guarded
+}
+
+// handler is a translated DECLARE ... HANDLER
+type handler struct {
+	exit       bool
+	conditions []string // PL/pgSQL conditions, e.g. OTHERS or SQLSTATE '23000'
+	body       []string
+}
+
+// notFoundCondition is what conditionValue returns for MySQL's no-data condition
+const notFoundCondition = "NOT FOUND"
+
+func (t *Translator) newPLpgSQL(body []Token, function bool) *plpgsql {
+	inner := *t
+	inner.routine = true
+	return &plpgsql{t: &inner, p: newParser(body), function: function, vars: map[string]string{}}
+}
+
+// translate translates the routine's body. A body that is a single statement
+// rather than BEGIN ... END is wrapped in a block.
+func (r *plpgsql) translate() ([]string, error) {
+	isBlock := r.p.peek().Is("BEGIN") || r.p.peekN(1).IsOp(":") && r.p.peekN(2).Is("BEGIN")
+	lines, err := r.statement()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.p.expectEnd(); err != nil {
+		return nil, err
+	}
+	if !isBlock {
+		lines = append(append([]string{"BEGIN"}, indent(lines)...), "END;")
+	}
+	return lines, nil
+}
+
+func (r *plpgsql) problem(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	for _, known := range r.problems {
+		if known == msg {
+			return
+		}
+	}
+	r.problems = append(r.problems, msg)
+}
+
+// statement translates one statement, without its terminating semicolon
+func (r *plpgsql) statement() ([]string, error) {
+	label := ""
+	if r.p.peek().IsIdent() && r.p.peekN(1).IsOp(":") {
+		label = r.p.next().Text
+		r.p.next()
+	}
+	switch tok := r.p.peek(); {
+	case tok.Is("BEGIN"):
+		return r.block(label)
+	case tok.Is("LOOP"), tok.Is("WHILE"), tok.Is("REPEAT"):
+		return r.loop(label)
+	case label != "":
+		return nil, r.p.errorf("only BEGIN, LOOP, WHILE and REPEAT can be labeled")
+	case tok.Is("IF"):
+		return r.ifStatement()
+	case tok.Is("CASE"):
+		return r.caseStatement()
+	case tok.Is("DECLARE"):
+		return nil, r.p.errorf("DECLARE is only allowed at the start of a BEGIN ... END block")
+	}
+	return r.simple()
+}
+
+// statements translates the statements up to one of the keywords that end
+// a compound statement's part, such as END or ELSE
+func (r *plpgsql) statements(ends ...string) ([]string, error) {
+	var lines []string
+	for !r.atKeyword(ends...) {
+		if r.p.atEnd() {
+			return nil, r.p.errorf("expected %s", ends[len(ends)-1])
+		}
+		stmt, err := r.statement()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.p.expectOp(";"); err != nil {
+			return nil, err
+		}
+		lines = append(lines, stmt...)
+	}
+	return lines, nil
+}
+
+func (r *plpgsql) atKeyword(keywords ...string) bool {
+	tok := r.p.peek()
+	for _, kw := range keywords {
+		if tok.Is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokensUntil consumes the tokens up to the end of the statement or, outside
+// parentheses and CASE expressions, one of the given keywords
+func (r *plpgsql) tokensUntil(keywords ...string) []Token {
+	p := r.p
+	start := p.mark()
+	depth, cases := 0, 0
+	for ; p.pos < len(p.tokens); p.pos++ {
+		tok := p.tokens[p.pos]
+		switch {
+		case tok.IsOp("("):
+			depth++
+		case tok.IsOp(")"):
+			depth--
+		case tok.IsOp(";"):
+			return p.since(start)
+		case depth > 0:
+		case tok.Is("CASE"):
+			cases++
+		case tok.Is("END") && cases > 0:
+			cases--
+		case cases == 0:
+			for _, kw := range keywords {
+				if tok.Is(kw) {
+					return p.since(start)
+				}
+			}
+		}
+	}
+	return p.since(start)
+}
+
+// endLabel skips the label that may follow the END of a block or loop
+func (r *plpgsql) endLabel() {
+	if r.p.peek().IsIdent() {
+		r.p.next()
+	}
+}
+
+// block translates BEGIN ... END. MySQL declares variables, conditions,
+// cursors and handlers at the start of the block.
+func (r *plpgsql) block(label string) ([]string, error) {
+	r.p.next() // BEGIN
+	b := &block{label: label, conditions: map[string]string{}}
+	r.blocks = append(r.blocks, b)
+	defer func() { r.blocks = r.blocks[:len(r.blocks)-1] }()
+
+	var decls []string
+	for r.p.accept("DECLARE") {
+		lines, err := r.declare(b)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.p.expectOp(";"); err != nil {
+			return nil, err
+		}
+		decls = append(decls, lines...)
+	}
+	body, err := r.statements("END")
+	if err != nil {
+		return nil, err
+	}
+	r.p.next() // END
+	r.endLabel()
+
+	var lines []string
+	if b.label != "" {
+		lines = append(lines, "<<"+b.label+">>")
+	}
+	if len(decls) > 0 {
+		lines = append(lines, "DECLARE")
+		lines = append(lines, indent(decls)...)
+	}
+	lines = append(lines, "BEGIN")
+	lines = append(lines, indent(body)...)
+	if len(b.exits) > 0 {
+		lines = append(lines, "EXCEPTION")
+		for _, h := range byPrecedence(b.exits) {
+			lines = append(lines, indent(h.when())...)
+		}
+	}
+	end := "END"
+	if b.label != "" {
+		end += " " + b.label
+	}
+	return append(lines, end+";"), nil
+}
+
+// declare translates DECLARE, after the keyword. Variables and cursors
+// become PL/pgSQL declarations; conditions and handlers are kept in b.
+func (r *plpgsql) declare(b *block) ([]string, error) {
+	if tok := r.p.peek(); (tok.Is("CONTINUE") || tok.Is("EXIT") || tok.Is("UNDO")) && r.p.peekN(1).Is("HANDLER") {
+		return nil, r.handler(b)
+	}
+
+	var names []Token
+	for {
+		name := r.p.next()
+		if !name.IsIdent() {
+			return nil, fmt.Errorf("syntax error: expected variable name near '%s'", name.Text)
+		}
+		names = append(names, name)
+		if !r.p.acceptOp(",") {
+			break
+		}
+	}
+	switch {
+	case len(names) == 1 && r.p.accept("CONDITION"):
+		if err := r.p.expect("FOR"); err != nil {
+			return nil, err
+		}
+		cond, err := r.conditionValue()
+		if err != nil {
+			return nil, err
+		}
+		b.conditions[strings.ToLower(names[0].Value)] = cond
+		return nil, nil
+	case len(names) == 1 && r.p.accept("CURSOR"):
+		if err := r.p.expect("FOR"); err != nil {
+			return nil, err
+		}
+		query := r.query(r.tokensUntil())
+		if len(query) != 1 {
+			return nil, nil
+		}
+		return []string{names[0].Text + " CURSOR FOR " + query[0]}, nil
+	}
+
+	v := &ColumnDef{Name: names[0]}
+	if err := r.p.parseDataType(v); err != nil {
+		return nil, err
+	}
+	pgType, _, err := columnType(v)
+	if err != nil {
+		r.problem("%v", err)
+	}
+	for _, name := range names {
+		r.vars[strings.ToLower(name.Value)] = pgType
+	}
+	value := ""
+	if r.p.accept("DEFAULT") {
+		value = " := " + r.value(names[0], r.tokensUntil())
+	}
+	var lines []string
+	for _, name := range names {
+		lines = append(lines, name.Text+" "+pgType+value+";")
+	}
+	return lines, nil
+}
+
+// handler translates DECLARE ... HANDLER. NOT FOUND handlers run inline
+// after each FETCH and SELECT ... INTO; error handlers become EXCEPTION
+// clauses, around the block for EXIT and around each statement for CONTINUE.
+func (r *plpgsql) handler(b *block) error {
+	kind := r.p.next().Upper()
+	r.p.next() // HANDLER
+	if kind == "UNDO" {
+		return fmt.Errorf("UNDO handlers are not supported")
+	}
+	if err := r.p.expect("FOR"); err != nil {
+		return err
+	}
+	h := &handler{exit: kind == "EXIT"}
+	notFound := false
+	for {
+		cond, err := r.conditionValue()
+		if err != nil {
+			return err
+		}
+		switch cond {
+		case notFoundCondition:
+			notFound = true
+		case "":
+		default:
+			h.conditions = append(h.conditions, cond)
+		}
+		if !r.p.acceptOp(",") {
+			break
+		}
+	}
+
+	// ROLLBACK and RESIGNAL is what an unhandled error already does to a
+	// PostgreSQL procedure's transaction, and a handler would keep the block
+	// from committing
+	if h.exit && !notFound && r.rollsBackAndResignals() {
+		return nil
+	}
+
+	r.handling++
+	body, err := r.statement()
+	r.handling--
+	if err != nil {
+		return err
+	}
+	h.body = body
+
+	if notFound {
+		if h.exit && b.label == "" {
+			r.labels++
+			b.label = fmt.Sprintf("mygo_block%d", r.labels)
+		}
+		b.notFound = &handler{exit: h.exit, body: body}
+	}
+	switch {
+	case len(h.conditions) == 0:
+	case h.exit:
+		b.exits = append(b.exits, h)
+	default:
+		b.continues = append(b.continues, h)
+	}
+	return nil
+}
+
+// rollsBackAndResignals consumes a handler statement of the form
+// BEGIN ROLLBACK; RESIGNAL; END
+func (r *plpgsql) rollsBackAndResignals() bool {
+	n := 0
+	for _, word := range []string{"BEGIN", "ROLLBACK", ";", "RESIGNAL", ";", "END"} {
+		tok := r.p.peekN(n)
+		if word == ";" && tok.Is("WORK") {
+			n++
+			tok = r.p.peekN(n)
+		}
+		if !tok.Is(word) && !tok.IsOp(word) {
+			return false
+		}
+		n++
+	}
+	if !r.p.peekN(n).IsOp(";") {
+		return false
+	}
+	for ; n > 0; n-- {
+		r.p.next()
+	}
+	return true
+}
+
+// when renders the handler as a WHEN clause of an EXCEPTION section
+func (h *handler) when() []string {
+	body := h.body
+	if len(body) == 0 {
+		body = []string{"NULL;"}
+	}
+	return append([]string{"WHEN " + strings.Join(h.conditions, " OR ") + " THEN"}, indent(body)...)
+}
+
+// byPrecedence orders handlers so that OTHERS comes last: PL/pgSQL takes the
+// first matching WHEN clause, MySQL the most specific handler
+func byPrecedence(handlers []*handler) []*handler {
+	sorted := append([]*handler(nil), handlers...)
+	catchesAll := func(h *handler) bool {
+		for _, cond := range h.conditions {
+			if cond == "OTHERS" {
+				return true
+			}
+		}
+		return false
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return !catchesAll(sorted[i]) && catchesAll(sorted[j])
+	})
+	return sorted
+}
+
+// mysqlStates maps the SQLSTATE values MySQL uses differently from PostgreSQL
+var mysqlStates = map[string]string{
+	"02000": notFoundCondition,
+	"40001": "transaction_rollback",
+	"42S01": "duplicate_table",
+	"42S02": "undefined_table",
+	"42S21": "duplicate_column",
+	"42S22": "undefined_column",
+}
+
+// mysqlErrors maps MySQL error numbers to PostgreSQL conditions
+var mysqlErrors = map[string]string{
+	"1022": "unique_violation", "1062": "unique_violation", "1586": "unique_violation",
+	"1216": "foreign_key_violation", "1217": "foreign_key_violation",
+	"1451": "foreign_key_violation", "1452": "foreign_key_violation",
+	"1048": "not_null_violation", "1364": "not_null_violation", "3819": "check_violation",
+	"1050": "duplicate_table", "1146": "undefined_table", "1054": "undefined_column", "1060": "duplicate_column",
+	"1205": "lock_not_available", "1213": "deadlock_detected",
+	"1264": "numeric_value_out_of_range", "1365": "division_by_zero", "1406": "string_data_right_truncation",
+	"1329": notFoundCondition, "1644": "SQLSTATE '45000'",
+}
+
+// conditionValue translates a condition of DECLARE ... HANDLER or CONDITION.
+// It returns "" for a condition that has no translation.
+func (r *plpgsql) conditionValue() (string, error) {
+	switch tok := r.p.next(); {
+	case tok.Is("SQLSTATE"):
+		r.p.accept("VALUE")
+		state := r.p.next()
+		if state.Kind != TokString {
+			return "", fmt.Errorf("syntax error: expected SQLSTATE value near '%s'", state.Text)
+		}
+		if cond, ok := mysqlStates[state.Value]; ok {
+			return cond, nil
+		}
+		if strings.HasPrefix(state.Value, "01") {
+			r.problem("warning conditions such as SQLSTATE '%s' are not supported in routines, PostgreSQL warnings do not raise conditions", state.Value)
+			return "", nil
+		}
+		return "SQLSTATE " + quoteLiteral(state.Value), nil
+	case tok.Kind == TokNumber:
+		if cond, ok := mysqlErrors[tok.Text]; ok {
+			return cond, nil
+		}
+		r.problem("MySQL error %s has no PostgreSQL equivalent", tok.Text)
+		return "", nil
+	case tok.Is("SQLEXCEPTION"):
+		return "OTHERS", nil
+	case tok.Is("SQLWARNING"):
+		r.problem("SQLWARNING handlers are not supported in routines, PostgreSQL warnings do not raise conditions")
+		return "", nil
+	case tok.Is("NOT"):
+		if err := r.p.expect("FOUND"); err != nil {
+			return "", err
+		}
+		return notFoundCondition, nil
+	case tok.IsIdent():
+		for i := len(r.blocks) - 1; i >= 0; i-- {
+			if cond, ok := r.blocks[i].conditions[strings.ToLower(tok.Value)]; ok {
+				return cond, nil
+			}
+		}
+		return "", fmt.Errorf("undefined CONDITION: %s", tok.Value)
+	default:
+		return "", fmt.Errorf("syntax error: expected condition near '%s'", tok.Text)
+	}
+}
+
+// guard wraps a statement in a block that catches the errors of the CONTINUE
+// handlers in scope, so that execution goes on after a failure as in MySQL
+func (r *plpgsql) guard(lines []string) []string {
+	if r.handling > 0 || len(lines) == 0 {
+		return lines
+	}
+	var handlers []*handler
+	for i := len(r.blocks) - 1; i >= 0; i-- {
+		handlers = append(handlers, r.blocks[i].continues...)
+		if len(r.blocks[i].exits) > 0 {
+			// An inner block's EXIT handlers come before outer CONTINUE handlers
+			break
+		}
+	}
+	if len(handlers) == 0 {
+		return lines
+	}
+	out := append([]string{"BEGIN"}, indent(lines)...)
+	out = append(out, "EXCEPTION")
+	for _, h := range byPrecedence(handlers) {
+		out = append(out, indent(h.when())...)
+	}
+	return append(out, "END;")
+}
+
+// catchesErrors reports whether a block in scope has error handlers, which
+// make PL/pgSQL run it as a subtransaction
+func (r *plpgsql) catchesErrors() bool {
+	for _, b := range r.blocks {
+		if len(b.exits) > 0 || len(b.continues) > 0 {
+			return true
+		}
+	}
+	return r.handling > 0
+}
+
+// notFound runs the NOT FOUND handler in scope, if any, after a statement
+// that found no row
+func (r *plpgsql) notFound() []string {
+	for i := len(r.blocks) - 1; i >= 0; i-- {
+		b := r.blocks[i]
+		if b.notFound == nil {
+			continue
+		}
+		body := b.notFound.body
+		if b.notFound.exit {
+			body = append(append([]string(nil), body...), "EXIT "+b.label+";")
+		}
+		return append(append([]string{"IF NOT FOUND THEN"}, indent(body)...), "END IF;")
+	}
+	return nil
+}
+
+// loop translates LOOP, WHILE ... DO and REPEAT ... UNTIL, which all become
+// PL/pgSQL loops
+func (r *plpgsql) loop(label string) ([]string, error) {
+	kind := r.p.next().Upper()
+	var lines []string
+	if label != "" {
+		lines = append(lines, "<<"+label+">>")
+	}
+	switch kind {
+	case "LOOP":
+		body, err := r.statements("END")
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "LOOP")
+		lines = append(lines, indent(body)...)
+	case "WHILE":
+		cond := r.condition(r.tokensUntil("DO"))
+		if err := r.p.expect("DO"); err != nil {
+			return nil, err
+		}
+		body, err := r.statements("END")
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "WHILE "+cond+" LOOP")
+		lines = append(lines, indent(body)...)
+	case "REPEAT":
+		body, err := r.statements("UNTIL")
+		if err != nil {
+			return nil, err
+		}
+		r.p.next() // UNTIL
+		cond := r.condition(r.tokensUntil("END"))
+		lines = append(lines, "LOOP")
+		lines = append(lines, indent(append(body, "EXIT WHEN "+cond+";"))...)
+	}
+	if err := r.p.expect("END"); err != nil {
+		return nil, err
+	}
+	if err := r.p.expect(kind); err != nil {
+		return nil, err
+	}
+	r.endLabel()
+	end := "END LOOP"
+	if label != "" {
+		end += " " + label
+	}
+	return append(lines, end+";"), nil
+}
+
+// ifStatement translates IF ... THEN ... [ELSEIF ...] [ELSE ...] END IF
+func (r *plpgsql) ifStatement() ([]string, error) {
+	r.p.next() // IF
+	var lines []string
+	keyword := "IF"
+	for {
+		cond := r.condition(r.tokensUntil("THEN"))
+		if err := r.p.expect("THEN"); err != nil {
+			return nil, err
+		}
+		body, err := r.statements("ELSEIF", "ELSE", "END")
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, keyword+" "+cond+" THEN")
+		lines = append(lines, indent(body)...)
+		if !r.p.accept("ELSEIF") {
+			break
+		}
+		keyword = "ELSIF"
+	}
+	if r.p.accept("ELSE") {
+		body, err := r.statements("END")
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "ELSE")
+		lines = append(lines, indent(body)...)
+	}
+	if err := r.p.expect("END"); err != nil {
+		return nil, err
+	}
+	if err := r.p.expect("IF"); err != nil {
+		return nil, err
+	}
+	return append(lines, "END IF;"), nil
+}
+
+// caseStatement translates the CASE statement, which PL/pgSQL spells the same
+func (r *plpgsql) caseStatement() ([]string, error) {
+	r.p.next() // CASE
+	head := "CASE"
+	if !r.p.peek().Is("WHEN") {
+		head += " " + r.expr(r.tokensUntil("WHEN"))
+	}
+	lines := []string{head}
+	for r.p.accept("WHEN") {
+		value := r.expr(r.tokensUntil("THEN"))
+		if err := r.p.expect("THEN"); err != nil {
+			return nil, err
+		}
+		body, err := r.statements("WHEN", "ELSE", "END")
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "WHEN "+value+" THEN")
+		lines = append(lines, indent(body)...)
+	}
+	if len(lines) == 1 {
+		return nil, r.p.errorf("expected WHEN")
+	}
+	if r.p.accept("ELSE") {
+		body, err := r.statements("END")
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "ELSE")
+		lines = append(lines, indent(body)...)
+	}
+	if err := r.p.expect("END"); err != nil {
+		return nil, err
+	}
+	if err := r.p.expect("CASE"); err != nil {
+		return nil, err
+	}
+	return append(lines, "END CASE;"), nil
+}
+
+// simple translates a statement that is not compound
+func (r *plpgsql) simple() ([]string, error) {
+	tokens := r.tokensUntil()
+	if len(tokens) == 0 {
+		return nil, r.p.errorf("expected statement")
+	}
+	switch verb := tokens[0].Upper(); verb {
+	case "SET":
+		return r.guard(r.set(tokens[1:])), nil
+	case "LEAVE", "ITERATE":
+		label := trimTrivia(tokens[1:])
+		if len(label) != 1 || !label[0].IsIdent() {
+			return nil, fmt.Errorf("syntax error: expected label after %s", verb)
+		}
+		if verb == "LEAVE" {
+			return []string{"EXIT " + label[0].Text + ";"}, nil
+		}
+		return []string{"CONTINUE " + label[0].Text + ";"}, nil
+	case "RETURN":
+		if !r.function {
+			return nil, fmt.Errorf("RETURN is only allowed in a FUNCTION")
+		}
+		return []string{"RETURN " + r.expr(tokens[1:]) + ";"}, nil
+	case "OPEN", "CLOSE":
+		return r.guard([]string{render(flatten(tokens)) + ";"}), nil
+	case "FETCH":
+		r.checkVariables(tokens)
+		lines := []string{render(flatten(tokens)) + ";"}
+		found := r.notFound()
+		if found == nil {
+			// Without a handler, fetching past the last row is an error
+			found = append(append([]string{"IF NOT FOUND THEN"},
+				indent([]string{"RAISE EXCEPTION USING ERRCODE = '02000', MESSAGE = 'No data - zero rows fetched, selected, or processed';"})...),
+				"END IF;")
+		}
+		return r.guard(append(lines, found...)), nil
+	case "SIGNAL", "RESIGNAL":
+		lines, err := r.signal(tokens)
+		return r.guard(lines), err
+	case "GET":
+		lines, err := r.diagnostics(tokens)
+		return r.guard(lines), err
+	case "START":
+		// A procedure always runs in a transaction, a new one after each COMMIT
+		return nil, nil
+	case "COMMIT", "ROLLBACK":
+		return r.endTransaction(tokens)
+	case "SAVEPOINT", "RELEASE", "LOCK", "UNLOCK", "USE":
+		r.problem("%s is not supported in routines", verb)
+		return nil, nil
+	case "PREPARE", "EXECUTE", "DEALLOCATE":
+		r.problem("%s is not supported in routines, PL/pgSQL runs dynamic SQL with EXECUTE format(...)", verb)
+		return nil, nil
+	case "SELECT", "WITH":
+		if findTopLevel(tokens, 0, "INTO") < 0 {
+			r.problem("SELECT without INTO is not supported in routines, PL/pgSQL cannot return its result set")
+			return nil, nil
+		}
+		lines := r.query(tokens)
+		return r.guard(append(lines, r.notFound()...)), nil
+	}
+	return r.guard(r.query(tokens)), nil
+}
+
+// query translates an SQL statement of the body like any other statement
+func (r *plpgsql) query(tokens []Token) []string {
+	r.checkVariables(tokens)
+	tokens = flatten(tokens)
+	stmt, err := parseTokens(tokens)
+	var result *TranslationResult
+	if err == nil {
+		result, err = r.t.translateStatement(stmt, render(tokens))
+	}
+	switch {
+	case err != nil:
+		r.problem("%v", err)
+		return nil
+	case result.IsSpecial:
+		r.problem("%s is not supported in routines", tokens[0].Upper())
+		return nil
+	case len(result.Setup) > 0:
+		r.problem("GET_LOCK() is not supported in routines")
+		return nil
+	}
+	r.warnings = append(r.warnings, result.Warnings...)
+
+	statements := result.Statements
+	if len(statements) == 0 && result.Query != "" {
+		statements = []string{result.Query}
+	}
+	lines := make([]string, len(statements))
+	for i, s := range statements {
+		lines[i] = s + ";"
+	}
+	return lines
+}
+
+// expr translates an expression
+func (r *plpgsql) expr(tokens []Token) string {
+	r.checkVariables(tokens)
+	for i, tok := range tokens {
+		if (tok.Is("ROW_COUNT") || tok.Is("FOUND_ROWS")) && isFunctionCall(tokens, i) {
+			r.problem("%s() is not supported in routine expressions, only in SET var = ROW_COUNT()", tok.Upper())
+		}
+	}
+	out, err := r.t.rewriteTokens(flatten(tokens))
+	if err != nil {
+		r.problem("%v", err)
+		return render(tokens)
+	}
+	return render(out)
+}
+
+// numeric reports whether name is a numeric parameter or variable
+func (r *plpgsql) numeric(name Token) bool {
+	pgType := r.vars[strings.ToLower(name.Value)]
+	for _, prefix := range []string{"smallint", "integer", "bigint", "numeric", "decimal", "real", "double"} {
+		if strings.HasPrefix(pgType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// value translates the value assigned to a variable. MySQL stores TRUE and
+// FALSE as 1 and 0, so numeric variables are often set to them.
+func (r *plpgsql) value(name Token, tokens []Token) string {
+	if flat := flatten(tokens); len(flat) == 1 && r.numeric(name) {
+		switch {
+		case flat[0].Is("TRUE"):
+			return "1"
+		case flat[0].Is("FALSE"):
+			return "0"
+		}
+	}
+	return r.expr(tokens)
+}
+
+// condition translates the condition of IF, WHILE or REPEAT. MySQL takes a
+// number as a truth value, as in the common IF done THEN, where PL/pgSQL
+// wants a boolean.
+func (r *plpgsql) condition(tokens []Token) string {
+	flat := flatten(tokens)
+	switch {
+	case len(flat) == 1 && r.numeric(flat[0]):
+		return flat[0].Text + " <> 0"
+	case len(flat) == 3 && flat[0].Is("NOT") && r.numeric(flat[2]):
+		return flat[2].Text + " = 0"
+	}
+	return r.expr(tokens)
+}
+
+// checkVariables reports user and system variables, which PL/pgSQL cannot
+// reach: they live in the client's session, not on the server
+func (r *plpgsql) checkVariables(tokens []Token) {
+	for _, tok := range tokens {
+		switch tok.Kind {
+		case TokVariable:
+			r.problem("user variable %s is not supported in routines", tok.Text)
+		case TokSystemVariable:
+			r.problem("system variable %s is not supported in routines", tok.Text)
+		}
+	}
+}
+
+// set translates SET assignments to local variables and parameters
+func (r *plpgsql) set(tokens []Token) []string {
+	var lines []string
+	for _, part := range splitTopLevel(tokens, ",") {
+		part = trimTrivia(part)
+		eq := -1
+		for i, tok := range part {
+			if tok.IsOp("=") || tok.IsOp(":=") {
+				eq = i
+				break
+			}
+		}
+		if eq < 0 {
+			r.problem("SET %s is not supported in routines", renderTrimmed(part))
+			continue
+		}
+		target, value := trimTrivia(part[:eq]), trimTrivia(part[eq+1:])
+		r.checkVariables(target)
+		name := render(flatten(target))
+		if len(target) != 1 && !(len(target) == 3 && target[1].IsOp(".")) {
+			r.problem("SET %s is not supported in routines", name)
+			continue
+		}
+		if len(value) == 3 && value[0].Is("ROW_COUNT") && value[1].IsOp("(") && value[2].IsOp(")") {
+			lines = append(lines, "GET DIAGNOSTICS "+name+" = ROW_COUNT;")
+			continue
+		}
+		lines = append(lines, name+" := "+r.value(target[len(target)-1], value)+";")
+	}
+	return lines
+}
+
+// signalItems maps SIGNAL's condition items to RAISE options
+var signalItems = map[string]string{
+	"MESSAGE_TEXT": "MESSAGE", "TABLE_NAME": "TABLE", "COLUMN_NAME": "COLUMN",
+	"CONSTRAINT_NAME": "CONSTRAINT", "SCHEMA_NAME": "SCHEMA",
+}
+
+// signal translates SIGNAL and RESIGNAL to RAISE. A bare RESIGNAL re-raises
+// the error being handled.
+func (r *plpgsql) signal(tokens []Token) ([]string, error) {
+	p := newParser(tokens)
+	resignal := p.next().Is("RESIGNAL")
+	if resignal && p.atEnd() {
+		return []string{"RAISE;"}, nil
+	}
+
+	level := "EXCEPTION"
+	var options []string
+	switch tok := p.peek(); {
+	case tok.Is("SQLSTATE"):
+		p.next()
+		p.accept("VALUE")
+		state := p.next()
+		if state.Kind != TokString {
+			return nil, fmt.Errorf("syntax error: expected SQLSTATE value near '%s'", state.Text)
+		}
+		if strings.HasPrefix(state.Value, "01") {
+			level = "WARNING"
+		}
+		options = append(options, "ERRCODE = "+state.Text)
+	case tok.IsIdent() && !tok.Is("SET"):
+		p.next()
+		cond, err := r.signalCondition(tok)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, "ERRCODE = "+cond)
+	case resignal:
+		options = append(options, "ERRCODE = SQLSTATE")
+	default:
+		return nil, fmt.Errorf("syntax error: expected SQLSTATE near '%s'", tok.Text)
+	}
+
+	message := false
+	if p.accept("SET") {
+		for {
+			item := p.next()
+			if err := p.expectOp("="); err != nil {
+				return nil, err
+			}
+			value := p.next()
+			r.checkVariables([]Token{value})
+			if option, ok := signalItems[item.Upper()]; ok {
+				options = append(options, option+" = "+value.Text)
+				message = message || option == "MESSAGE"
+			} else {
+				r.warnings = append(r.warnings, fmt.Sprintf("%s of %s was dropped, PostgreSQL errors have no such item", item.Upper(), tokens[0].Upper()))
+			}
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	if resignal && !message {
+		options = append(options, "MESSAGE = SQLERRM")
+	}
+	return []string{"RAISE " + level + " USING " + strings.Join(options, ", ") + ";"}, nil
+}
+
+// signalCondition returns the error code of a condition named in SIGNAL,
+// which MySQL only allows for conditions declared with an SQLSTATE
+func (r *plpgsql) signalCondition(name Token) (string, error) {
+	for i := len(r.blocks) - 1; i >= 0; i-- {
+		cond, ok := r.blocks[i].conditions[strings.ToLower(name.Value)]
+		switch {
+		case !ok:
+		case strings.HasPrefix(cond, "SQLSTATE "):
+			return strings.TrimPrefix(cond, "SQLSTATE "), nil
+		default:
+			return "", fmt.Errorf("SIGNAL/RESIGNAL can only use a CONDITION defined with SQLSTATE")
+		}
+	}
+	return "", fmt.Errorf("undefined CONDITION: %s", name.Value)
+}
+
+// diagnosticItems are the items of GET DIAGNOSTICS both databases provide.
+// ROW_COUNT describes the last statement, the others the error being handled.
+var diagnosticItems = map[string]bool{
+	"ROW_COUNT": true, "RETURNED_SQLSTATE": true, "MESSAGE_TEXT": true, "TABLE_NAME": true,
+	"COLUMN_NAME": true, "CONSTRAINT_NAME": true, "SCHEMA_NAME": true,
+}
+
+// diagnostics translates GET DIAGNOSTICS. Condition information comes from
+// GET STACKED DIAGNOSTICS in PL/pgSQL, which only has the current error.
+func (r *plpgsql) diagnostics(tokens []Token) ([]string, error) {
+	p := newParser(tokens)
+	p.next() // GET
+	p.accept("CURRENT", "STACKED")
+	if err := p.expect("DIAGNOSTICS"); err != nil {
+		return nil, err
+	}
+	stmt := "GET DIAGNOSTICS "
+	if p.accept("CONDITION") {
+		if n := p.next(); n.Text != "1" {
+			r.problem("GET DIAGNOSTICS CONDITION %s is not supported in routines, PL/pgSQL only has the current error", n.Text)
+		}
+		stmt = "GET STACKED DIAGNOSTICS "
+	}
+	var items []string
+	for {
+		target := p.next()
+		r.checkVariables([]Token{target})
+		if err := p.expectOp("="); err != nil {
+			return nil, err
+		}
+		item := p.next().Upper()
+		if !diagnosticItems[item] {
+			r.problem("GET DIAGNOSTICS item %s is not supported in routines", item)
+		}
+		items = append(items, target.Text+" = "+item)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return []string{stmt + strings.Join(items, ", ") + ";"}, nil
+}
+
+// endTransaction translates COMMIT and ROLLBACK, which PL/pgSQL procedures
+// allow outside blocks with error handlers
+func (r *plpgsql) endTransaction(tokens []Token) ([]string, error) {
+	p := newParser(tokens)
+	verb := p.next().Upper()
+	if r.function || r.trigger {
+		return nil, fmt.Errorf("explicit or implicit commit is not allowed in stored function or trigger")
+	}
+	p.accept("WORK")
+	stmt := verb
+	switch {
+	case p.acceptSeq("AND", "NO", "CHAIN"):
+	case p.acceptSeq("AND", "CHAIN"):
+		stmt += " AND CHAIN"
+	}
+	if !p.atEnd() {
+		r.problem("%s is not supported in routines", renderTrimmed(tokens))
+		return nil, nil
+	}
+	if r.catchesErrors() {
+		r.problem("%s inside a block with handlers is not supported in routines, PL/pgSQL runs such blocks as subtransactions", verb)
+		return nil, nil
+	}
+	return []string{stmt + ";"}, nil
+}
+
+// flatten renders tokens on one line: comments are dropped and whitespace
+// is folded into single spaces
+func flatten(tokens []Token) []Token {
+	var out []Token
+	for _, tok := range trimTrivia(tokens) {
+		if !tok.IsTrivia() {
+			out = append(out, tok)
+		} else if out[len(out)-1].Kind != TokSpace {
+			out = append(out, Token{Kind: TokSpace, Text: " "})
+		}
+	}
+	return out
+}
+
+// indent indents translated lines one level
+func indent(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = "    " + strings.ReplaceAll(line, "\n", "\n    ")
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrepareStmt is PREPARE name FROM {'statement' | @variable}
+type PrepareStmt struct {
+	Name   Token
+	Source Token // String or user variable holding the statement
+}
+
+// ExecuteStmt is EXECUTE name [USING @variable, ...]
+type ExecuteStmt struct {
+	Name  Token
+	Using []Token
+}
+
+// DeallocateStmt is {DEALLOCATE | DROP} PREPARE name
+type DeallocateStmt struct {
+	Name Token
+}
+
+func (*PrepareStmt) statementNode()    {}
+func (*ExecuteStmt) statementNode()    {}
+func (*DeallocateStmt) statementNode() {}
+
+// parsePrepare parses MySQL's PREPARE. PostgreSQL's PREPARE name AS
+// statement is passed through.
+func (p *parser) parsePrepare() (Statement, error) {
+	p.next() // PREPARE
+	stmt := &PrepareStmt{Name: p.next()}
+	if !stmt.Name.IsIdent() {
+		return nil, fmt.Errorf("syntax error: expected statement name near '%s'", stmt.Name.Text)
+	}
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	stmt.Source = p.next()
+	if stmt.Source.Kind != TokString && stmt.Source.Kind != TokVariable {
+		return nil, fmt.Errorf("syntax error: expected statement string or user variable near '%s'", stmt.Source.Text)
+	}
+	return stmt, p.expectEnd()
+}
+
+// parseExecute parses MySQL's EXECUTE. PostgreSQL's EXECUTE name(args) is
+// passed through.
+func (p *parser) parseExecute() (Statement, error) {
+	p.next() // EXECUTE
+	stmt := &ExecuteStmt{Name: p.next()}
+	if !stmt.Name.IsIdent() {
+		return nil, fmt.Errorf("syntax error: expected statement name near '%s'", stmt.Name.Text)
+	}
+	if p.accept("USING") {
+		for {
+			tok := p.next()
+			if tok.Kind != TokVariable {
+				return nil, fmt.Errorf("syntax error: expected user variable near '%s'", tok.Text)
+			}
+			stmt.Using = append(stmt.Using, tok)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	return stmt, p.expectEnd()
+}
+
+// parseDeallocate parses DEALLOCATE PREPARE and DROP PREPARE. PostgreSQL's
+// DEALLOCATE without PREPARE is passed through.
+func (p *parser) parseDeallocate() (Statement, error) {
+	p.next() // DEALLOCATE or DROP
+	if err := p.expect("PREPARE"); err != nil {
+		return nil, err
+	}
+	stmt := &DeallocateStmt{Name: p.next()}
+	if !stmt.Name.IsIdent() || stmt.Name.Is("ALL") {
+		return nil, fmt.Errorf("syntax error: expected statement name near '%s'", stmt.Name.Text)
+	}
+	return stmt, p.expectEnd()
+}
+
+// translatePrepare leaves the statement to the client, which translates it
+// and keeps it under its name; its ? placeholders become $1, $2, ... The
+// client reads a statement given as a user variable from the variable.
+func (t *Translator) translatePrepare(s *PrepareStmt) *TranslationResult {
+	result := &TranslationResult{IsSpecial: true, SpecialType: "prepare", Args: []string{strings.ToLower(s.Name.Value)}}
+	if s.Source.Kind == TokVariable {
+		result.Params = []string{variableName(s.Source)}
+	} else {
+		result.Args = append(result.Args, s.Source.Value)
+	}
+	return result
+}
+
+// translateExecute has the client run a statement it prepared, binding the
+// values of the USING variables to its placeholders. Query runs a statement
+// prepared with PostgreSQL's PREPARE instead.
+func (t *Translator) translateExecute(s *ExecuteStmt) *TranslationResult {
+	result := &TranslationResult{
+		Query:       "EXECUTE " + s.Name.Text,
+		IsSpecial:   true,
+		SpecialType: "execute",
+		Args:        []string{strings.ToLower(s.Name.Value)},
+	}
+	for _, tok := range s.Using {
+		result.Params = append(result.Params, variableName(tok))
+	}
+	return result
+}
+
+// translateDeallocate has the client forget a prepared statement. Query
+// deallocates one prepared with PostgreSQL's PREPARE instead.
+func (t *Translator) translateDeallocate(s *DeallocateStmt) *TranslationResult {
+	return &TranslationResult{
+		Query:       "DEALLOCATE " + s.Name.Text,
+		IsSpecial:   true,
+		SpecialType: "deallocate",
+		Args:        []string{strings.ToLower(s.Name.Value)},
+	}
+}
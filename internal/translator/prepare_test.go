@@ -0,0 +1,57 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslatePrepare(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input       string
+		specialType string
+		query       string
+		args        []string
+		params      []string
+	}{
+		{"PREPARE s FROM 'SELECT * FROM users WHERE id = ?'", "prepare", "", []string{"s", "SELECT * FROM users WHERE id = ?"}, nil},
+		{"PREPARE Stmt FROM @sql", "prepare", "", []string{"stmt"}, []string{"sql"}},
+		{"EXECUTE s", "execute", "EXECUTE s", []string{"s"}, nil},
+		{"EXECUTE s USING @a, @B", "execute", "EXECUTE s", []string{"s"}, []string{"a", "b"}},
+		{"DEALLOCATE PREPARE s", "deallocate", "DEALLOCATE s", []string{"s"}, nil},
+		{"DROP PREPARE `S`", "deallocate", `DEALLOCATE "S"`, []string{"s"}, nil},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.SpecialType != tt.specialType || result.Query != tt.query ||
+			!reflect.DeepEqual(result.Args, tt.args) || !reflect.DeepEqual(result.Params, tt.params) {
+			t.Errorf("for %s: got %s %q %q %q", tt.input, result.SpecialType, result.Query, result.Args, result.Params)
+		}
+	}
+
+	// The prepared statement is translated on its own, numbering its placeholders
+	result, err := tr.Translate("SELECT * FROM users WHERE id = ? AND name LIKE ? LIMIT 1, 10")
+	if err != nil || result.Query != "SELECT * FROM users WHERE id = $1 AND name LIKE $2 LIMIT 10 OFFSET 1" || result.Placeholders != 2 {
+		t.Errorf("got %+v, %v", result, err)
+	}
+
+	// PostgreSQL's own statements are passed through
+	for _, input := range []string{
+		"PREPARE s (integer) AS SELECT * FROM users WHERE id = $1",
+		"EXECUTE s(1)",
+		"DEALLOCATE s",
+		"DEALLOCATE ALL",
+	} {
+		result, err := tr.Translate(input)
+		if err != nil || result.IsSpecial || result.Query != input {
+			t.Errorf("for %s: got %+v, %v", input, result, err)
+		}
+	}
+}
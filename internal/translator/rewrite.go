@@ -0,0 +1,147 @@
+package translator
+
+import "fmt"
+
+// rewriteTokens runs the token-level MySQL to PostgreSQL rewrites over a
+// statement or expression. The tokens must already have been through
+// rewriteLiterals, so every pass sees PostgreSQL lexical forms.
+func (t *Translator) rewriteTokens(tokens []Token) ([]Token, error) {
+	// Operators first, so function rewrites see PostgreSQL operators and
+	// the || they generate is not mistaken for MySQL's OR
+	tokens, err := rewriteOperators(tokens, t.pipesAsConcat)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err = rewriteJSONOperators(tokens)
+	if err != nil {
+		return nil, err
+	}
+	tokens, warnings, err := rewriteFulltext(tokens)
+	if err != nil {
+		return nil, err
+	}
+	t.warnings = append(t.warnings, warnings...)
+	tokens, err = t.rewriteLastInsertID(tokens)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err = rewriteFunctions(tokens)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err = rewriteIntervals(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return rewriteLimit(rewriteLockingReads(tokens)), nil
+}
+
+// prevSignificant returns the index of the last non-trivia token before i, or -1
+func prevSignificant(tokens []Token, i int) int {
+	i--
+	for i >= 0 && tokens[i].IsTrivia() {
+		i--
+	}
+	return i
+}
+
+// nextSignificant returns the index of the first non-trivia token at or after i, or len(tokens)
+func nextSignificant(tokens []Token, i int) int {
+	for i < len(tokens) && tokens[i].IsTrivia() {
+		i++
+	}
+	return i
+}
+
+// findTopLevel returns the index of the first keyword outside parentheses at
+// or after from that matches one of keywords, or -1
+func findTopLevel(tokens []Token, from int, keywords ...string) int {
+	depth := 0
+	for i := from; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.IsOp("("):
+			depth++
+		case tok.IsOp(")"):
+			depth--
+		case depth == 0 && tok.Kind == TokIdent:
+			for _, kw := range keywords {
+				if tok.Is(kw) {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// pgTokens lexes PostgreSQL text produced by a rewrite. Generated text is
+// always well-formed, so a lexing failure is a bug in the rewriter.
+func pgTokens(sql string) []Token {
+	tokens, err := lexPostgres(sql)
+	if err != nil {
+		panic(fmt.Sprintf("translator generated invalid SQL %q: %v", sql, err))
+	}
+	return tokens
+}
+
+// concatTokens joins token slices into a new slice
+func concatTokens(parts ...[]Token) []Token {
+	var out []Token
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+// trimTrivia drops leading and trailing whitespace and comments
+func trimTrivia(tokens []Token) []Token {
+	start, end := 0, len(tokens)
+	for start < end && tokens[start].IsTrivia() {
+		start++
+	}
+	for end > start && tokens[end-1].IsTrivia() {
+		end--
+	}
+	return tokens[start:end]
+}
+
+// matchParen returns the index of the parenthesis closing the one at open, or -1
+func matchParen(tokens []Token, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch {
+		case tokens[i].IsOp("("):
+			depth++
+		case tokens[i].IsOp(")"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits tokens at the given operator outside of parentheses
+func splitTopLevel(tokens []Token, op string) [][]Token {
+	var parts [][]Token
+	depth, start := 0, 0
+	for i, tok := range tokens {
+		switch {
+		case tok.IsOp("("):
+			depth++
+		case tok.IsOp(")"):
+			depth--
+		case tok.IsOp(op) && depth == 0:
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, tokens[start:])
+}
+
+// renderTrimmed renders tokens without surrounding whitespace
+func renderTrimmed(tokens []Token) string {
+	return render(trimTrivia(tokens))
+}
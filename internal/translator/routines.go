@@ -0,0 +1,215 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CreateRoutineStmt is CREATE [DEFINER = user] PROCEDURE|FUNCTION name
+// ([IN|OUT|INOUT] param type, ...) [RETURNS type] [characteristic ...] body
+type CreateRoutineStmt struct {
+	Function        bool
+	IfNotExists     bool
+	Name            []Token
+	Params          []RoutineParam
+	Returns         *ColumnDef // Return type of a function
+	Comment         *Token
+	SecurityDefiner bool    // SQL SECURITY DEFINER
+	Body            []Token // The routine's statement, usually BEGIN ... END
+}
+
+// RoutineParam is a parameter of a stored routine
+type RoutineParam struct {
+	Mode string // IN, OUT or INOUT; always IN for functions
+	Def  *ColumnDef
+}
+
+func (*CreateRoutineStmt) statementNode() {}
+
+// isCreateRoutine reports whether the statement is CREATE PROCEDURE or
+// CREATE FUNCTION, possibly with a DEFINER clause
+func (p *parser) isCreateRoutine() bool {
+	return p.isCreateWithDefiner("PROCEDURE", "FUNCTION")
+}
+
+// isCreateWithDefiner reports whether CREATE, past an optional DEFINER
+// clause, is followed by one of keywords
+func (p *parser) isCreateWithDefiner(keywords ...string) bool {
+	for n := 1; ; n++ {
+		tok := p.peekN(n)
+		for _, keyword := range keywords {
+			if tok.Is(keyword) {
+				return true
+			}
+		}
+		switch {
+		case tok.Is("DEFINER"), tok.Is("CURRENT_USER"), tok.IsOp("="), tok.IsOp("("), tok.IsOp(")"),
+			tok.Kind == TokString, tok.Kind == TokVariable, tok.IsIdent() && p.peekN(n-1).IsOp("="):
+		default:
+			return false
+		}
+	}
+}
+
+// parseCreateRoutine parses MySQL's CREATE PROCEDURE and CREATE FUNCTION.
+// PostgreSQL's own forms, with LANGUAGE and a quoted body, fail to parse
+// and are passed through.
+func (p *parser) parseCreateRoutine() (Statement, error) {
+	p.next() // CREATE
+	stmt := &CreateRoutineStmt{}
+	if p.accept("DEFINER") {
+		p.acceptOp("=")
+		if _, _, err := p.userSpec(); err != nil {
+			return nil, err
+		}
+	}
+	stmt.Function = p.next().Is("FUNCTION")
+	stmt.IfNotExists = p.acceptSeq("IF", "NOT", "EXISTS")
+
+	var err error
+	if stmt.Name, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if err := p.expectOp("("); err != nil {
+		return nil, err
+	}
+	for !p.acceptOp(")") {
+		if len(stmt.Params) > 0 {
+			if err := p.expectOp(","); err != nil {
+				return nil, err
+			}
+		}
+		param := RoutineParam{Mode: "IN"}
+		if !stmt.Function && (p.peek().Is("IN") || p.peek().Is("OUT") || p.peek().Is("INOUT")) {
+			param.Mode = p.next().Upper()
+		}
+		param.Def = &ColumnDef{Name: p.next()}
+		if !param.Def.Name.IsIdent() {
+			return nil, fmt.Errorf("syntax error: expected parameter name near '%s'", param.Def.Name.Text)
+		}
+		if err := p.parseDataType(param.Def); err != nil {
+			return nil, err
+		}
+		stmt.Params = append(stmt.Params, param)
+	}
+	if stmt.Function {
+		if err := p.expect("RETURNS"); err != nil {
+			return nil, err
+		}
+		stmt.Returns = &ColumnDef{Name: Token{Kind: TokIdent, Text: "RETURNS"}}
+		if err := p.parseDataType(stmt.Returns); err != nil {
+			return nil, err
+		}
+	}
+
+characteristics:
+	for {
+		switch {
+		case p.accept("COMMENT"):
+			tok := p.next()
+			if tok.Kind != TokString {
+				return nil, fmt.Errorf("syntax error: expected comment string near '%s'", tok.Text)
+			}
+			stmt.Comment = &tok
+		case p.accept("LANGUAGE"):
+			if err := p.expect("SQL"); err != nil {
+				return nil, err
+			}
+		case p.accept("DETERMINISTIC"), p.acceptSeq("NOT", "DETERMINISTIC"), p.acceptSeq("CONTAINS", "SQL"),
+			p.acceptSeq("NO", "SQL"), p.acceptSeq("READS", "SQL", "DATA"), p.acceptSeq("MODIFIES", "SQL", "DATA"):
+		case p.acceptSeq("SQL", "SECURITY"):
+			switch {
+			case p.accept("DEFINER"):
+				stmt.SecurityDefiner = true
+			case p.accept("INVOKER"):
+			default:
+				return nil, p.errorf("expected DEFINER or INVOKER")
+			}
+		default:
+			break characteristics
+		}
+	}
+
+	// A PostgreSQL body is a string, often dollar-quoted, after AS
+	if p.atEnd() || p.peek().Is("AS") {
+		return nil, p.errorf("expected routine body")
+	}
+	stmt.Body = trimTrivia(p.rest())
+	for _, tok := range stmt.Body {
+		if tok.IsOp("$") {
+			return nil, p.errorf("expected routine body")
+		}
+	}
+	return stmt, nil
+}
+
+// translateCreateRoutine creates a PL/pgSQL procedure or function. MySQL
+// routines run with their definer's rights unless SQL SECURITY INVOKER is
+// given; PostgreSQL's default is the invoker's, so only an explicit SQL
+// SECURITY DEFINER becomes SECURITY DEFINER.
+func (t *Translator) translateCreateRoutine(s *CreateRoutineStmt) (*TranslationResult, error) {
+	kind := "PROCEDURE"
+	if s.Function {
+		kind = "FUNCTION"
+	}
+	if s.IfNotExists {
+		return nil, fmt.Errorf("CREATE %s IF NOT EXISTS is not supported on PostgreSQL", kind)
+	}
+
+	body := t.newPLpgSQL(s.Body, s.Function)
+	var params []string
+	for _, param := range s.Params {
+		pgType, _, err := columnType(param.Def)
+		if err != nil {
+			return nil, err
+		}
+		body.vars[strings.ToLower(param.Def.Name.Value)] = pgType
+		def := param.Def.Name.Text + " " + pgType
+		if param.Mode != "IN" {
+			def = param.Mode + " " + def
+		}
+		params = append(params, def)
+	}
+	signature := render(s.Name) + "(" + strings.Join(params, ", ") + ")"
+	header := "CREATE " + kind + " " + signature
+	if s.Function {
+		pgType, _, err := columnType(s.Returns)
+		if err != nil {
+			return nil, err
+		}
+		header += " RETURNS " + pgType
+	}
+	header += "\nLANGUAGE plpgsql"
+	if s.SecurityDefiner {
+		header += " SECURITY DEFINER"
+	}
+
+	lines, err := body.translate()
+	if err != nil {
+		return nil, err
+	}
+	if len(body.problems) > 0 {
+		return nil, fmt.Errorf("%s %s cannot be translated to PL/pgSQL: %s",
+			strings.ToLower(kind), render(s.Name), strings.Join(body.problems, "; "))
+	}
+
+	// Like MySQL, let local variables win over columns of the same name,
+	// which PL/pgSQL reports as ambiguous by default
+	code := "#variable_conflict use_variable\n" + strings.Join(lines, "\n")
+	statements := []string{header + " AS " + dollarQuote(code)}
+	if s.Comment != nil {
+		statements = append(statements, fmt.Sprintf("COMMENT ON %s %s IS %s", kind, signature, s.Comment.Text))
+	}
+	result := statementsResult(statements)
+	result.Warnings = body.warnings
+	return result, nil
+}
+
+// dollarQuote quotes a routine body with $$, or $mygo$ if the body contains $$
+func dollarQuote(code string) string {
+	quote := "$$"
+	if strings.Contains(code, quote) {
+		quote = "$mygo$"
+	}
+	return quote + "\n" + code + "\n" + quote
+}
@@ -0,0 +1,178 @@
+package translator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateCreateRoutine(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"CREATE FUNCTION discount(price DECIMAL(10,2), pct INT) RETURNS DECIMAL(10,2) DETERMINISTIC\n" +
+				"RETURN ROUND(price * (1 - pct / 100), 2)",
+			[]string{"CREATE FUNCTION discount(price decimal(10,2), pct integer) RETURNS decimal(10,2)\n" +
+				"LANGUAGE plpgsql AS $$\n#variable_conflict use_variable\n" +
+				"BEGIN\n    RETURN ROUND(price * (1 - pct / 100), 2);\nEND;\n$$"},
+		},
+		{
+			"CREATE DEFINER = `root`@`%` PROCEDURE set_status(IN p_id INT UNSIGNED, OUT p_old VARCHAR(20))\n" +
+				"COMMENT 'Marks a user active' SQL SECURITY DEFINER\n" +
+				"proc: BEGIN\n" +
+				"  DECLARE n INT DEFAULT 0;\n" +
+				"  SELECT status INTO p_old FROM users WHERE id = p_id;\n" +
+				"  IF p_old IS NULL THEN\n" +
+				"    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'no such user';\n" +
+				"  ELSEIF p_old = \"active\" THEN\n" +
+				"    LEAVE proc;\n" +
+				"  END IF;\n" +
+				"  UPDATE users SET status = 'active' WHERE id = p_id;\n" +
+				"  SET n = ROW_COUNT();\n" +
+				"  COMMIT;\n" +
+				"END proc",
+			[]string{
+				"CREATE PROCEDURE set_status(p_id bigint, OUT p_old varchar(20))\n" +
+					"LANGUAGE plpgsql SECURITY DEFINER AS $$\n#variable_conflict use_variable\n" +
+					"<<proc>>\nDECLARE\n    n integer := 0;\nBEGIN\n" +
+					"    SELECT status INTO p_old FROM users WHERE id = p_id;\n" +
+					"    IF p_old IS NULL THEN\n" +
+					"        RAISE EXCEPTION USING ERRCODE = '45000', MESSAGE = 'no such user';\n" +
+					"    ELSIF p_old = 'active' THEN\n" +
+					"        EXIT proc;\n" +
+					"    END IF;\n" +
+					"    UPDATE users SET status = 'active' WHERE id = p_id;\n" +
+					"    GET DIAGNOSTICS n = ROW_COUNT;\n" +
+					"    COMMIT;\n" +
+					"END proc;\n$$",
+				"COMMENT ON PROCEDURE set_status(p_id bigint, OUT p_old varchar(20)) IS 'Marks a user active'",
+			},
+		},
+		{
+			// The usual cursor loop: the NOT FOUND handler runs after each FETCH
+			"CREATE PROCEDURE total(OUT p_sum BIGINT)\n" +
+				"BEGIN\n" +
+				"  DECLARE done BOOLEAN DEFAULT FALSE;\n" +
+				"  DECLARE finished INT DEFAULT FALSE;\n" +
+				"  DECLARE v INT;\n" +
+				"  DECLARE cur CURSOR FOR SELECT amount FROM orders;\n" +
+				"  DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = TRUE, finished = TRUE;\n" +
+				"  SET p_sum = 0;\n" +
+				"  OPEN cur;\n" +
+				"  read_loop: LOOP\n" +
+				"    FETCH cur INTO v;\n" +
+				"    IF finished THEN LEAVE read_loop; END IF;\n" +
+				"    SET p_sum = p_sum + v;\n" +
+				"  END LOOP;\n" +
+				"  CLOSE cur;\n" +
+				"  WHILE NOT done DO SET done = TRUE; END WHILE;\n" +
+				"  REPEAT SET p_sum = p_sum - 1; UNTIL p_sum < 10 END REPEAT;\n" +
+				"END",
+			[]string{"CREATE PROCEDURE total(OUT p_sum bigint)\n" +
+				"LANGUAGE plpgsql AS $$\n#variable_conflict use_variable\n" +
+				"DECLARE\n    done boolean := FALSE;\n    finished integer := 0;\n    v integer;\n" +
+				"    cur CURSOR FOR SELECT amount FROM orders;\n" +
+				"BEGIN\n" +
+				"    p_sum := 0;\n" +
+				"    OPEN cur;\n" +
+				"    <<read_loop>>\n    LOOP\n" +
+				"        FETCH cur INTO v;\n" +
+				"        IF NOT FOUND THEN\n            done := TRUE;\n            finished := 1;\n        END IF;\n" +
+				"        IF finished <> 0 THEN\n            EXIT read_loop;\n        END IF;\n" +
+				"        p_sum := p_sum + v;\n" +
+				"    END LOOP read_loop;\n" +
+				"    CLOSE cur;\n" +
+				"    WHILE NOT done LOOP\n        done := TRUE;\n    END LOOP;\n" +
+				"    LOOP\n        p_sum := p_sum - 1;\n        EXIT WHEN p_sum < 10;\n    END LOOP;\n" +
+				"END;\n$$"},
+		},
+		{
+			// EXIT handlers become the block's EXCEPTION clause, CONTINUE
+			// handlers guard each statement
+			"CREATE FUNCTION find(p INT) RETURNS INT\n" +
+				"BEGIN\n" +
+				"  DECLARE r INT DEFAULT 0;\n" +
+				"  DECLARE dup CONDITION FOR 1062;\n" +
+				"  DECLARE EXIT HANDLER FOR NOT FOUND RETURN -1;\n" +
+				"  DECLARE EXIT HANDLER FOR SQLEXCEPTION RETURN -2;\n" +
+				"  BEGIN\n" +
+				"    DECLARE CONTINUE HANDLER FOR dup, SQLSTATE '42S02' SET r = 1;\n" +
+				"    INSERT INTO seen VALUES (p);\n" +
+				"  END;\n" +
+				"  SELECT id INTO r FROM items WHERE id = p;\n" +
+				"  CASE WHEN r > 10 THEN RETURN 10; ELSE RETURN r; END CASE;\n" +
+				"END",
+			[]string{"CREATE FUNCTION find(p integer) RETURNS integer\n" +
+				"LANGUAGE plpgsql AS $$\n#variable_conflict use_variable\n" +
+				"<<mygo_block1>>\nDECLARE\n    r integer := 0;\n" +
+				"BEGIN\n" +
+				"    BEGIN\n        BEGIN\n            INSERT INTO seen VALUES (p);\n" +
+				"        EXCEPTION\n            WHEN unique_violation OR undefined_table THEN\n                r := 1;\n        END;\n    END;\n" +
+				"    SELECT id INTO r FROM items WHERE id = p;\n" +
+				"    IF NOT FOUND THEN\n        RETURN -1;\n        EXIT mygo_block1;\n    END IF;\n" +
+				"    CASE\n    WHEN r > 10 THEN\n        RETURN 10;\n    ELSE\n        RETURN r;\n    END CASE;\n" +
+				"EXCEPTION\n    WHEN OTHERS THEN\n        RETURN -2;\n" +
+				"END mygo_block1;\n$$"},
+		},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		got := result.Statements
+		if got == nil {
+			got = []string{result.Query}
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, strings.Join(got, "\n--\n"), strings.Join(tt.expected, "\n--\n"))
+		}
+	}
+}
+
+func TestTranslateCreateRoutineErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	// PostgreSQL's own definitions are passed through
+	for _, input := range []string{
+		"CREATE FUNCTION f(a integer) RETURNS integer LANGUAGE plpgsql AS $$ BEGIN RETURN a; END $$",
+		"CREATE FUNCTION f(a integer) RETURNS integer AS $$ BEGIN RETURN a; END $$ LANGUAGE plpgsql",
+	} {
+		result, err := tr.Translate(input)
+		if err != nil || result.Query != input {
+			t.Errorf("for %s: got %+v, %v", input, result, err)
+		}
+	}
+
+	// Every construct that cannot be translated is reported
+	_, err := tr.Translate("CREATE PROCEDURE p() BEGIN DECLARE CONTINUE HANDLER FOR SQLWARNING BEGIN END; " +
+		"SELECT * FROM t; SET @x = 1; PREPARE s FROM 'SELECT 1'; END")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, want := range []string{"SQLWARNING", "SELECT without INTO", "@x", "PREPARE"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+
+	for _, input := range []string{
+		"CREATE PROCEDURE p() BEGIN SELECT 1 INTO @x; END",
+		"CREATE PROCEDURE p() BEGIN SET x = 1; DECLARE y INT; END",
+		"CREATE PROCEDURE p() RETURN 1",
+		"CREATE FUNCTION f() RETURNS INT BEGIN COMMIT; RETURN 1; END",
+		"CREATE PROCEDURE p() BEGIN DECLARE EXIT HANDLER FOR SQLEXCEPTION BEGIN ROLLBACK; END; COMMIT; END",
+		"CREATE PROCEDURE IF NOT EXISTS p() BEGIN END",
+	} {
+		if _, err := tr.Translate(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
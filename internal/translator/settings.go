@@ -0,0 +1,389 @@
+package translator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetStmt sets system variables:
+//
+//	SET [GLOBAL | SESSION | LOCAL | PERSIST] variable = value, ...
+//	SET @@[global. | session.]variable = value, ...
+//	SET NAMES charset [COLLATE collation] | SET CHARACTER SET charset
+type SetStmt struct {
+	Assignments []SetAssignment
+}
+
+// SetAssignment sets one system variable
+type SetAssignment struct {
+	Scope     string // GLOBAL, SESSION, LOCAL, PERSIST or PERSIST_ONLY as written, if any
+	Name      Token  // Variable as written; SET NAMES and CHARACTER SET name NAMES
+	Value     []Token
+	Collation string // SET NAMES ... COLLATE
+}
+
+func (*SetStmt) statementNode() {}
+
+// variable returns the lower-cased name of the variable
+func (a SetAssignment) variable() string {
+	return strings.ToLower(a.Name.Value)
+}
+
+// parseSet parses MySQL's SET of system variables. PostgreSQL's forms that
+// MySQL lacks, such as SET name TO value and SET TIME ZONE, fail to parse and
+// are passed through.
+func (p *parser) parseSet() (Statement, error) {
+	p.next() // SET
+	stmt := &SetStmt{}
+	for {
+		var a SetAssignment
+		if p.peek().Is("NAMES") || p.peek().Is("CHARSET") || p.peek().Is("CHARACTER") && p.peekN(1).Is("SET") {
+			if err := p.setNames(&a); err != nil {
+				return nil, err
+			}
+		} else if err := p.setVariable(&a); err != nil {
+			return nil, err
+		}
+		stmt.Assignments = append(stmt.Assignments, a)
+		if !p.acceptOp(",") {
+			return stmt, p.expectEnd()
+		}
+	}
+}
+
+// setNames parses NAMES charset [COLLATE collation] or CHARACTER SET charset
+func (p *parser) setNames(a *SetAssignment) error {
+	a.Name = p.next()
+	a.Name.Value = "NAMES"
+	p.accept("SET") // CHARACTER SET
+	tok := p.next()
+	if tok.Kind != TokIdent && tok.Kind != TokString {
+		return fmt.Errorf("syntax error: expected character set near '%s'", tok.Text)
+	}
+	a.Value = []Token{tok}
+	if p.accept("COLLATE") {
+		collation := p.next()
+		if collation.Kind != TokIdent && collation.Kind != TokString {
+			return fmt.Errorf("syntax error: expected collation near '%s'", collation.Text)
+		}
+		a.Collation = collation.Value
+	}
+	return nil
+}
+
+// setVariable parses [scope] variable = value
+func (p *parser) setVariable(a *SetAssignment) error {
+	if p.peek().Is("GLOBAL") || p.peek().Is("SESSION") || p.peek().Is("LOCAL") ||
+		p.peek().Is("PERSIST") || p.peek().Is("PERSIST_ONLY") {
+		a.Scope = p.next().Upper()
+	}
+	a.Name = p.next()
+	switch {
+	case a.Name.Kind == TokSystemVariable && a.Scope == "":
+		if scope, name, ok := strings.Cut(a.Name.Value, "."); ok {
+			a.Scope, a.Name.Value = strings.ToUpper(scope), name
+		}
+	case a.Name.Kind != TokIdent:
+		return fmt.Errorf("syntax error: expected system variable near '%s'", a.Name.Text)
+	}
+	if !p.acceptOp("=") && !p.acceptOp(":=") {
+		return p.errorf("expected = after %s", a.Name.Text)
+	}
+	start := p.mark()
+	for !p.atEnd() && !p.peek().IsOp(",") {
+		if p.peek().IsOp("(") {
+			if err := p.skipGroup(); err != nil {
+				return err
+			}
+			continue
+		}
+		p.next()
+	}
+	if a.Value = trimTrivia(p.since(start)); len(a.Value) == 0 {
+		return p.errorf("expected value for %s", a.Name.Text)
+	}
+	return nil
+}
+
+// setting carries a MySQL system variable over to a PostgreSQL setting,
+// converting the variable's value to the setting's
+type setting struct {
+	name    string
+	convert func(variable, value string) (string, error)
+}
+
+// sessionSettings are the variables with a PostgreSQL counterpart
+var sessionSettings = map[string]setting{
+	"names":                    {"client_encoding", clientEncoding},
+	"character_set_client":     {"client_encoding", clientEncoding},
+	"time_zone":                {"TimeZone", timeZone},
+	"foreign_key_checks":       {"session_replication_role", replicationRole},
+	"wait_timeout":             {"idle_in_transaction_session_timeout", timeout("s")},
+	"interactive_timeout":      {"idle_in_transaction_session_timeout", timeout("s")},
+	"max_execution_time":       {"statement_timeout", timeout("ms")},
+	"innodb_lock_wait_timeout": {"lock_timeout", timeout("s")},
+	"transaction_isolation":    {"default_transaction_isolation", isolationLevel},
+	"tx_isolation":             {"default_transaction_isolation", isolationLevel},
+	"transaction_read_only":    {"default_transaction_read_only", onOff},
+	"tx_read_only":             {"default_transaction_read_only", onOff},
+}
+
+// ignoredVariables are the variables that PostgreSQL has no equivalent for,
+// which clients and dump files commonly set
+var ignoredVariables = map[string]bool{
+	"unique_checks": true, "sql_notes": true, "sql_warnings": true, "sql_log_bin": true,
+	"sql_safe_updates": true, "sql_auto_is_null": true, "sql_big_selects": true,
+	"sql_buffer_result": true, "sql_quote_show_create": true, "sql_select_limit": true,
+	"sql_require_primary_key": true, "big_tables": true,
+	"character_set_results": true, "character_set_connection": true, "character_set_database": true,
+	"character_set_server": true, "character_set_filesystem": true, "collation_connection": true,
+	"collation_database": true, "collation_server": true, "default_collation_for_utf8mb4": true,
+	"group_concat_max_len": true, "max_allowed_packet": true, "net_buffer_length": true,
+	"net_read_timeout": true, "net_write_timeout": true, "lc_time_names": true,
+	"session_track_schema": true, "session_track_state_change": true,
+	"session_track_system_variables": true, "session_track_transaction_info": true,
+	"information_schema_stats_expiry": true, "default_storage_engine": true, "storage_engine": true,
+	"auto_increment_increment": true, "auto_increment_offset": true, "div_precision_increment": true,
+	"explicit_defaults_for_timestamp": true, "old_alter_table": true, "optimizer_switch": true,
+	"query_cache_type": true, "profiling": true, "innodb_strict_mode": true, "resultset_metadata": true,
+	"long_query_time": true, "sort_buffer_size": true, "join_buffer_size": true, "tmp_table_size": true,
+	"max_heap_table_size": true, "bulk_insert_buffer_size": true, "gtid_next": true, "gtid_purged": true,
+}
+
+// translateSet sets the PostgreSQL counterparts of the variables, and passes
+// PostgreSQL's own settings through. Variables with no equivalent are ignored
+// with a warning.
+func (t *Translator) translateSet(s *SetStmt) (*TranslationResult, error) {
+	result := &TranslationResult{IsSpecial: true, SpecialType: "set"}
+	for _, a := range s.Assignments {
+		name := a.variable()
+		value, literal := settingValue(a.Value)
+		isDefault := len(a.Value) == 1 && a.Value[0].Is("DEFAULT")
+
+		switch set, ok := sessionSettings[name]; {
+		case name == "autocommit":
+			return nil, fmt.Errorf("autocommit must be set in a SET statement of its own")
+		case a.Scope == "GLOBAL" || strings.HasPrefix(a.Scope, "PERSIST"):
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"SET %s %s was ignored: PostgreSQL sets defaults with ALTER DATABASE or ALTER SYSTEM", a.Scope, name))
+
+		case name == "sql_mode":
+			if !literal {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("sql_mode = %s could not be evaluated and was ignored", renderTrimmed(a.Value)))
+				continue
+			}
+			if isDefault {
+				value = ""
+			}
+			pipes, warnings := sqlMode(value)
+			result.Args = []string{pipes}
+			result.Warnings = append(result.Warnings, warnings...)
+
+		case ok:
+			if isDefault {
+				result.Statements = append(result.Statements, "RESET "+set.name)
+				break
+			}
+			if !literal {
+				return nil, fmt.Errorf("setting %s to an expression is not supported on PostgreSQL", name)
+			}
+			converted, err := set.convert(name, value)
+			if err != nil {
+				return nil, err
+			}
+			if converted == "" {
+				result.Statements = append(result.Statements, "RESET "+set.name)
+			} else {
+				result.Statements = append(result.Statements, "SET "+set.name+" = "+quoteLiteral(converted))
+			}
+			if a.Collation != "" {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("COLLATE %s was ignored: PostgreSQL sets collations per column or expression", a.Collation))
+			}
+			if name == "foreign_key_checks" && converted == "replica" {
+				result.Warnings = append(result.Warnings,
+					"FOREIGN_KEY_CHECKS = 0 sets session_replication_role to replica, which also disables triggers and needs superuser privileges")
+			}
+
+		case ignoredVariables[name]:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Variable '%s' has no equivalent on PostgreSQL and was ignored", name))
+
+		default:
+			known, err := t.knownSetting(name)
+			if err != nil {
+				return nil, err
+			}
+			if !known {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("Variable '%s' has no equivalent on PostgreSQL and was ignored", name))
+				continue
+			}
+			rewritten, err := t.rewriteTokens(a.Value)
+			if err != nil {
+				return nil, err
+			}
+			statement := "SET "
+			if a.Scope == "LOCAL" && a.Name.Kind == TokIdent {
+				// PostgreSQL's SET LOCAL lasts until the end of the transaction
+				statement += "LOCAL "
+			}
+			result.Statements = append(result.Statements, statement+a.Name.Value+" = "+render(rewritten))
+		}
+	}
+	return result, nil
+}
+
+// knownSetting reports whether PostgreSQL has a setting of the name. Without
+// the catalog to ask, it is taken not to. Custom settings such as myapp.mode
+// never get here, as they are passed through unparsed.
+func (t *Translator) knownSetting(name string) (bool, error) {
+	if t.catalog == nil {
+		return false, nil
+	}
+	return t.catalog.HasSetting(name)
+}
+
+// settingValue returns the literal a variable is set to, which is false for
+// an expression
+func settingValue(value []Token) (string, bool) {
+	if len(value) == 2 && value[0].IsOp("-") && value[1].Kind == TokNumber {
+		return "-" + value[1].Text, true
+	}
+	if len(value) != 1 {
+		return "", false
+	}
+	switch tok := value[0]; tok.Kind {
+	case TokString, TokIdent:
+		return tok.Value, true
+	case TokNumber:
+		return tok.Text, true
+	}
+	return "", false
+}
+
+// wrongValue is MySQL's error for a value a variable cannot take
+func wrongValue(variable, value string) error {
+	return fmt.Errorf("Variable '%s' can't be set to the value of '%s'", variable, value)
+}
+
+// clientEncodings maps MySQL character sets to PostgreSQL encodings
+var clientEncodings = map[string]string{
+	"utf8": "UTF8", "utf8mb3": "UTF8", "utf8mb4": "UTF8", "ascii": "SQL_ASCII",
+	"latin1": "WIN1252", "latin2": "LATIN2", "latin5": "LATIN5", "latin7": "LATIN7",
+	"cp1250": "WIN1250", "cp1251": "WIN1251", "cp1256": "WIN1256", "cp1257": "WIN1257",
+	"cp866": "WIN866", "koi8r": "KOI8R", "koi8u": "KOI8U", "greek": "ISO_8859_7", "hebrew": "ISO_8859_8",
+	"gbk": "GBK", "gb2312": "EUC_CN", "gb18030": "GB18030", "big5": "BIG5",
+	"sjis": "SJIS", "cp932": "SJIS", "ujis": "EUC_JP", "eucjpms": "EUC_JP", "euckr": "EUC_KR",
+}
+
+func clientEncoding(variable, charset string) (string, error) {
+	encoding, ok := clientEncodings[strings.ToLower(charset)]
+	if !ok {
+		return "", fmt.Errorf("Unknown character set: '%s'", charset)
+	}
+	return encoding, nil
+}
+
+// timeZone converts a time zone. PostgreSQL reads an offset such as +08:00
+// as a POSIX zone, whose sign is the other way round, so it is given as a
+// number of hours instead. SYSTEM resets the zone to the server's.
+func timeZone(variable, zone string) (string, error) {
+	if strings.EqualFold(zone, "SYSTEM") {
+		return "", nil
+	}
+	if zone == "" {
+		return "", fmt.Errorf("Unknown or incorrect time zone: '%s'", zone)
+	}
+	if zone[0] != '+' && zone[0] != '-' {
+		return zone, nil
+	}
+	h, m, ok := strings.Cut(zone[1:], ":")
+	hours, err := strconv.Atoi(h)
+	minutes, err2 := strconv.Atoi(m)
+	if !ok || err != nil || err2 != nil || len(m) != 2 || minutes > 59 || hours*60+minutes > 14*60 {
+		return "", fmt.Errorf("Unknown or incorrect time zone: '%s'", zone)
+	}
+	offset := float64(hours) + float64(minutes)/60
+	if zone[0] == '-' {
+		offset = -offset
+	}
+	return strconv.FormatFloat(offset, 'f', -1, 64), nil
+}
+
+// replicationRole disables foreign key checks the way pg_restore does, by
+// having the session act as a replica, for which PostgreSQL fires no triggers
+func replicationRole(variable, value string) (string, error) {
+	on, err := boolValue(variable, value)
+	if err != nil {
+		return "", err
+	}
+	if on {
+		return "origin", nil
+	}
+	return "replica", nil
+}
+
+// timeout converts a number of seconds or milliseconds to a duration in unit
+func timeout(unit string) func(variable, value string) (string, error) {
+	return func(variable, value string) (string, error) {
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("Incorrect argument type to variable '%s'", variable)
+		}
+		return strconv.FormatUint(n, 10) + unit, nil
+	}
+}
+
+// isolationLevel converts a level such as REPEATABLE-READ
+func isolationLevel(variable, value string) (string, error) {
+	switch level := strings.ToLower(strings.ReplaceAll(value, "-", " ")); level {
+	case "read uncommitted", "read committed", "repeatable read", "serializable":
+		return level, nil
+	}
+	return "", wrongValue(variable, value)
+}
+
+func onOff(variable, value string) (string, error) {
+	on, err := boolValue(variable, value)
+	if err != nil {
+		return "", err
+	}
+	if on {
+		return "on", nil
+	}
+	return "off", nil
+}
+
+func boolValue(variable, value string) (bool, error) {
+	switch strings.ToUpper(value) {
+	case "1", "ON", "TRUE":
+		return true, nil
+	case "0", "OFF", "FALSE":
+		return false, nil
+	}
+	return false, wrongValue(variable, value)
+}
+
+// sqlMode reports whether the modes make || concatenate, as "1" or "0", and
+// warns about those that have no effect on PostgreSQL
+func sqlMode(value string) (string, []string) {
+	pipes := "0"
+	var ignored, warnings []string
+	for _, mode := range strings.Split(value, ",") {
+		switch mode = strings.ToUpper(strings.TrimSpace(mode)); mode {
+		case "":
+		case "PIPES_AS_CONCAT":
+			pipes = "1"
+		case "ANSI":
+			pipes = "1"
+			warnings = append(warnings, "ANSI_QUOTES is not supported: double-quoted text is still read as a string")
+		case "ANSI_QUOTES":
+			warnings = append(warnings, "ANSI_QUOTES is not supported: double-quoted text is still read as a string")
+		default:
+			ignored = append(ignored, mode)
+		}
+	}
+	if len(ignored) > 0 {
+		warnings = append(warnings, fmt.Sprintf("sql_mode %s has no equivalent on PostgreSQL and was ignored", strings.Join(ignored, ",")))
+	}
+	return pipes, warnings
+}
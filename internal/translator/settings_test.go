@@ -0,0 +1,89 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateSet(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{settings: map[string]bool{"statement_timeout": true}})
+
+	tests := []struct {
+		input      string
+		statements []string
+		args       []string
+		warnings   int
+	}{
+		{"SET NAMES utf8mb4", []string{"SET client_encoding = 'UTF8'"}, nil, 0},
+		{"SET NAMES 'latin1' COLLATE 'latin1_swedish_ci'", []string{"SET client_encoding = 'WIN1252'"}, nil, 1},
+		{"SET CHARACTER SET gbk", []string{"SET client_encoding = 'GBK'"}, nil, 0},
+		{"SET NAMES DEFAULT", []string{"RESET client_encoding"}, nil, 0},
+		{"SET time_zone = '+08:00'", []string{"SET TimeZone = '8'"}, nil, 0},
+		{"SET @@session.time_zone = '-05:30'", []string{"SET TimeZone = '-5.5'"}, nil, 0},
+		{"SET time_zone = 'Asia/Shanghai'", []string{"SET TimeZone = 'Asia/Shanghai'"}, nil, 0},
+		{"SET time_zone = 'SYSTEM'", []string{"RESET TimeZone"}, nil, 0},
+		{"SET FOREIGN_KEY_CHECKS = 0", []string{"SET session_replication_role = 'replica'"}, nil, 1},
+		{"SET foreign_key_checks = ON", []string{"SET session_replication_role = 'origin'"}, nil, 0},
+		{"SET UNIQUE_CHECKS = 0", nil, nil, 1},
+		{"SET SESSION wait_timeout = 28800", []string{"SET idle_in_transaction_session_timeout = '28800s'"}, nil, 0},
+		{"SET max_execution_time = 2000", []string{"SET statement_timeout = '2000ms'"}, nil, 0},
+		{"SET innodb_lock_wait_timeout = DEFAULT", []string{"RESET lock_timeout"}, nil, 0},
+		{"SET SESSION transaction_isolation = 'REPEATABLE-READ'", []string{"SET default_transaction_isolation = 'repeatable read'"}, nil, 0},
+		{"SET sql_mode = 'PIPES_AS_CONCAT'", nil, []string{"1"}, 0},
+		{"SET sql_mode = ''", nil, []string{"0"}, 0},
+		{"SET sql_mode = 'STRICT_TRANS_TABLES,NO_ZERO_DATE'", nil, []string{"0"}, 1},
+		{"SET sql_mode = CONCAT(@@sql_mode, ',PIPES_AS_CONCAT')", nil, nil, 1},
+		{"SET GLOBAL max_allowed_packet = 1073741824", nil, nil, 1},
+		{"SET statement_timeout = 5000", []string{"SET statement_timeout = 5000"}, nil, 0},
+		{"SET SESSION optimizer_search_depth = 0", nil, nil, 1},
+		{"SET NAMES utf8mb4, time_zone = '+00:00', sql_notes = 0",
+			[]string{"SET client_encoding = 'UTF8'", "SET TimeZone = '0'"}, nil, 1},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.SpecialType != "set" || !reflect.DeepEqual(result.Statements, tt.statements) ||
+			!reflect.DeepEqual(result.Args, tt.args) || len(result.Warnings) != tt.warnings {
+			t.Errorf("for %s: got %s %q %q %q", tt.input, result.SpecialType, result.Statements, result.Args, result.Warnings)
+		}
+	}
+
+	for _, input := range []string{
+		"SET NAMES klingon",
+		"SET time_zone = '+15:00'",
+		"SET foreign_key_checks = 2",
+		"SET wait_timeout = 'forever'",
+		"SET time_zone = @tz",
+		"SET autocommit = 0, sql_mode = ''",
+		"SET @a = 1, @@time_zone = '+00:00'",
+		"SET NAMES utf8mb4, @a = 1",
+	} {
+		if _, err := tr.Translate(input); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+
+	// PostgreSQL's own forms are passed through
+	for _, input := range []string{
+		"SET search_path TO public",
+		"SET TIME ZONE 'UTC'",
+		"SET ROLE admin",
+		"SET myapp.mode = 'test'",
+	} {
+		result, err := tr.Translate(input)
+		if err != nil || result.IsSpecial || result.Query != input {
+			t.Errorf("for %s: got %+v, %v", input, result, err)
+		}
+	}
+
+	result, err := tr.Translate("SHOW WARNINGS")
+	if err != nil || result.SpecialType != "show_warnings" {
+		t.Errorf("got %+v, %v", result, err)
+	}
+}
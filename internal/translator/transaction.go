@@ -0,0 +1,365 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TransactionStmt is a transaction control statement:
+//
+//	START TRANSACTION [WITH CONSISTENT SNAPSHOT | READ ONLY | READ WRITE], ...
+//	BEGIN [WORK]
+//	COMMIT | ROLLBACK [WORK] [AND [NO] CHAIN] [[NO] RELEASE]
+//	SAVEPOINT s | RELEASE SAVEPOINT s | ROLLBACK [WORK] TO [SAVEPOINT] s
+//	SET [GLOBAL | SESSION] TRANSACTION characteristic, ...
+//	SET [SESSION] autocommit = {0 | 1}
+type TransactionStmt struct {
+	Kind       string   // BEGIN, COMMIT, ROLLBACK, SAVEPOINT, SET TRANSACTION or AUTOCOMMIT
+	Modes      []string // PostgreSQL transaction modes, e.g. ISOLATION LEVEL SERIALIZABLE
+	Snapshot   bool     // WITH CONSISTENT SNAPSHOT
+	Chain      bool     // AND CHAIN
+	Release    bool     // RELEASE: disconnect afterwards
+	Scope      string   // SET TRANSACTION: "" for the next transaction, SESSION or GLOBAL
+	Autocommit bool
+	Tokens     []Token // SAVEPOINT statements, which PostgreSQL spells the same
+}
+
+// XAStmt is XA START | END | PREPARE | COMMIT | ROLLBACK xid, or XA RECOVER
+type XAStmt struct {
+	Action   string
+	Xid      string // Global transaction identifier for PostgreSQL
+	OnePhase bool   // XA COMMIT xid ONE PHASE
+}
+
+func (*TransactionStmt) statementNode() {}
+func (*XAStmt) statementNode()          {}
+
+// isSetTransaction reports whether a SET statement sets transaction
+// characteristics or autocommit, the only setting it names
+func (p *parser) isSetTransaction() bool {
+	n := 1
+	if p.peekN(n).Is("GLOBAL") || p.peekN(n).Is("SESSION") || p.peekN(n).Is("LOCAL") {
+		n++
+	}
+	switch tok := p.peekN(n); {
+	case tok.Is("TRANSACTION"):
+		return true
+	case tok.Is("AUTOCOMMIT"):
+	case tok.Kind == TokSystemVariable && (strings.EqualFold(tok.Value, "autocommit") || strings.EqualFold(tok.Value, "session.autocommit")):
+	default:
+		return false
+	}
+	// A single assignment only
+	return len(splitTopLevel(p.tokens, ",")) == 1
+}
+
+// transactionModes parses the transaction characteristics of START
+// TRANSACTION, BEGIN and SET TRANSACTION. PostgreSQL's own ISOLATION LEVEL
+// and DEFERRABLE are accepted too.
+func (p *parser) transactionModes(stmt *TransactionStmt) error {
+	for !p.atEnd() {
+		switch {
+		case p.acceptSeq("WITH", "CONSISTENT", "SNAPSHOT"):
+			stmt.Snapshot = true
+		case p.acceptSeq("READ", "ONLY"):
+			stmt.Modes = append(stmt.Modes, "READ ONLY")
+		case p.acceptSeq("READ", "WRITE"):
+			stmt.Modes = append(stmt.Modes, "READ WRITE")
+		case p.acceptSeq("ISOLATION", "LEVEL"):
+			var level string
+			switch {
+			case p.acceptSeq("READ", "UNCOMMITTED"):
+				level = "READ UNCOMMITTED"
+			case p.acceptSeq("READ", "COMMITTED"):
+				level = "READ COMMITTED"
+			case p.acceptSeq("REPEATABLE", "READ"):
+				level = "REPEATABLE READ"
+			case p.accept("SERIALIZABLE"):
+				level = "SERIALIZABLE"
+			default:
+				return p.errorf("expected isolation level")
+			}
+			stmt.Modes = append(stmt.Modes, "ISOLATION LEVEL "+level)
+		case p.accept("DEFERRABLE"):
+			stmt.Modes = append(stmt.Modes, "DEFERRABLE")
+		case p.acceptSeq("NOT", "DEFERRABLE"):
+			stmt.Modes = append(stmt.Modes, "NOT DEFERRABLE")
+		default:
+			return p.errorf("expected transaction characteristic")
+		}
+		p.acceptOp(",")
+	}
+	return nil
+}
+
+func (p *parser) parseTransaction() (Statement, error) {
+	verb := p.next().Upper()
+	stmt := &TransactionStmt{Kind: verb}
+	switch verb {
+	case "START":
+		stmt.Kind = "BEGIN"
+		if err := p.expect("TRANSACTION"); err != nil {
+			return nil, err
+		}
+		return stmt, p.transactionModes(stmt)
+
+	case "BEGIN":
+		p.accept("WORK", "TRANSACTION")
+		return stmt, p.transactionModes(stmt)
+
+	case "SAVEPOINT", "RELEASE":
+		stmt.Kind, stmt.Tokens = "SAVEPOINT", p.tokens
+		if verb == "RELEASE" {
+			p.accept("SAVEPOINT")
+		}
+		if _, err := p.ident(); err != nil {
+			return nil, err
+		}
+		return stmt, p.expectEnd()
+	}
+
+	// COMMIT or ROLLBACK
+	p.accept("WORK", "TRANSACTION")
+	if verb == "ROLLBACK" && p.accept("TO") {
+		stmt.Kind, stmt.Tokens = "SAVEPOINT", p.tokens
+		p.accept("SAVEPOINT")
+		if _, err := p.ident(); err != nil {
+			return nil, err
+		}
+		return stmt, p.expectEnd()
+	}
+	if p.accept("AND") {
+		stmt.Chain = !p.accept("NO")
+		if err := p.expect("CHAIN"); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("RELEASE") {
+		stmt.Release = true
+	} else if p.acceptSeq("NO", "RELEASE") {
+		stmt.Release = false
+	}
+	if stmt.Chain && stmt.Release {
+		return nil, p.errorf("AND CHAIN cannot be combined with RELEASE")
+	}
+	return stmt, p.expectEnd()
+}
+
+func (p *parser) parseSetTransaction() (Statement, error) {
+	p.next() // SET
+	stmt := &TransactionStmt{Kind: "SET TRANSACTION"}
+	switch {
+	case p.accept("GLOBAL"):
+		stmt.Scope = "GLOBAL"
+	case p.accept("SESSION", "LOCAL"):
+		stmt.Scope = "SESSION"
+	}
+
+	if p.accept("TRANSACTION") {
+		if err := p.transactionModes(stmt); err != nil {
+			return nil, err
+		}
+		if stmt.Snapshot || len(stmt.Modes) == 0 {
+			return nil, p.errorf("expected ISOLATION LEVEL, READ ONLY or READ WRITE")
+		}
+		return stmt, nil
+	}
+
+	if p.next().Kind == TokSystemVariable && stmt.Scope == "" {
+		stmt.Scope = "SESSION"
+	}
+	stmt.Kind = "AUTOCOMMIT"
+	if stmt.Scope == "GLOBAL" {
+		return nil, fmt.Errorf("SET GLOBAL autocommit is not supported on PostgreSQL, which always autocommits outside a transaction")
+	}
+	if !p.acceptOp("=") && !p.acceptOp(":=") {
+		return nil, p.errorf("expected =")
+	}
+	switch value := p.next(); {
+	case value.Text == "1" || value.Is("ON") || value.Is("TRUE"):
+		stmt.Autocommit = true
+	case value.Text == "0" || value.Is("OFF") || value.Is("FALSE"):
+	default:
+		return nil, fmt.Errorf("variable 'autocommit' can't be set to the value of '%s'", value.Text)
+	}
+	return stmt, p.expectEnd()
+}
+
+// xid parses a MySQL XA transaction identifier: gtrid [, bqual [, formatID]].
+// PostgreSQL identifies prepared transactions by one string, which is the
+// gtrid alone in the common case and gtrid,bqual,formatID otherwise.
+func (p *parser) xid() (string, error) {
+	var parts []string
+	for len(parts) < 3 {
+		tok := p.next()
+		switch {
+		case tok.Kind == TokString && len(parts) < 2:
+			parts = append(parts, tok.Value)
+		case tok.Kind == TokNumber && len(parts) == 2:
+			parts = append(parts, tok.Text)
+		default:
+			return "", fmt.Errorf("syntax error: expected XA transaction identifier near '%s'", tok.Text)
+		}
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	if parts[0] == "" {
+		return "", fmt.Errorf("XA transaction identifier cannot be empty")
+	}
+	if len(parts) == 1 || len(parts) == 2 && parts[1] == "" || len(parts) == 3 && parts[1] == "" && parts[2] == "1" {
+		return parts[0], nil
+	}
+	if len(parts) == 2 {
+		parts = append(parts, "1")
+	}
+	return strings.Join(parts, ","), nil
+}
+
+func (p *parser) parseXA() (Statement, error) {
+	p.next() // XA
+	stmt := &XAStmt{Action: p.next().Upper()}
+	switch stmt.Action {
+	case "BEGIN":
+		stmt.Action = "START"
+	case "START", "END", "PREPARE", "COMMIT", "ROLLBACK":
+	case "RECOVER":
+		p.accept("CONVERT")
+		p.accept("XID")
+		return stmt, p.expectEnd()
+	default:
+		return nil, fmt.Errorf("syntax error: unknown XA command '%s'", stmt.Action)
+	}
+
+	var err error
+	if stmt.Xid, err = p.xid(); err != nil {
+		return nil, err
+	}
+	switch stmt.Action {
+	case "START":
+		if p.accept("JOIN", "RESUME") {
+			return nil, fmt.Errorf("XA START ... JOIN and RESUME are not supported")
+		}
+	case "END":
+		if p.accept("SUSPEND") {
+			return nil, fmt.Errorf("XA END ... SUSPEND is not supported")
+		}
+	case "COMMIT":
+		stmt.OnePhase = p.acceptSeq("ONE", "PHASE")
+	}
+	return stmt, p.expectEnd()
+}
+
+// translateTransaction hands transaction control to the client, which keeps
+// track of the open transaction. Args[0] says what to do; Query holds the
+// PostgreSQL statement where there is one.
+func (t *Translator) translateTransaction(s *TransactionStmt) (*TranslationResult, error) {
+	result := &TranslationResult{IsSpecial: true, SpecialType: "transaction"}
+	switch s.Kind {
+	case "BEGIN":
+		// WITH CONSISTENT SNAPSHOT takes the snapshot right away, which
+		// PostgreSQL does at the first query of a REPEATABLE READ transaction
+		modes := s.Modes
+		if s.Snapshot {
+			if !strings.Contains(strings.Join(modes, ","), "ISOLATION") {
+				modes = append([]string{"ISOLATION LEVEL REPEATABLE READ"}, modes...)
+			}
+			result.Statements = []string{"SELECT 1"}
+		}
+		result.Args = []string{"begin", strings.Join(modes, ", ")}
+
+	case "COMMIT", "ROLLBACK":
+		result.Query = s.Kind
+		result.Args = []string{strings.ToLower(s.Kind)}
+		switch {
+		case s.Chain:
+			result.Query += " AND CHAIN"
+			result.Args = append(result.Args, "chain")
+		case s.Release:
+			result.Args = append(result.Args, "release")
+		}
+
+	case "SAVEPOINT":
+		result.Query = renderTrimmed(s.Tokens)
+		result.Args = []string{"savepoint"}
+
+	case "SET TRANSACTION":
+		modes := strings.Join(s.Modes, ", ")
+		switch s.Scope {
+		case "":
+			// Only the next transaction, which the client starts with them
+			result.Args = []string{"next", modes}
+		case "SESSION":
+			return &TranslationResult{Query: "SET SESSION CHARACTERISTICS AS TRANSACTION " + modes}, nil
+		case "GLOBAL":
+			return t.setGlobalTransaction(s)
+		}
+
+	case "AUTOCOMMIT":
+		value := "0"
+		if s.Autocommit {
+			value = "1"
+		}
+		result.Args = []string{"autocommit", value}
+	}
+	return result, nil
+}
+
+// transactionDefaults maps transaction modes to the settings holding their defaults
+var transactionDefaults = map[string][2]string{
+	"ISOLATION LEVEL READ UNCOMMITTED": {"default_transaction_isolation", "read uncommitted"},
+	"ISOLATION LEVEL READ COMMITTED":   {"default_transaction_isolation", "read committed"},
+	"ISOLATION LEVEL REPEATABLE READ":  {"default_transaction_isolation", "repeatable read"},
+	"ISOLATION LEVEL SERIALIZABLE":     {"default_transaction_isolation", "serializable"},
+	"READ ONLY":                        {"default_transaction_read_only", "on"},
+	"READ WRITE":                       {"default_transaction_read_only", "off"},
+	"DEFERRABLE":                       {"default_transaction_deferrable", "on"},
+	"NOT DEFERRABLE":                   {"default_transaction_deferrable", "off"},
+}
+
+// setGlobalTransaction changes the defaults of new sessions. PostgreSQL keeps
+// them per database rather than per server, and ALTER DATABASE needs the
+// name, hence the DO block.
+func (t *Translator) setGlobalTransaction(s *TransactionStmt) (*TranslationResult, error) {
+	var statements []string
+	for _, mode := range s.Modes {
+		setting := transactionDefaults[mode]
+		statements = append(statements, fmt.Sprintf("EXECUTE format('ALTER DATABASE %%I SET %s = %s', current_database());",
+			setting[0], strings.ReplaceAll(quoteLiteral(setting[1]), "'", "''")))
+	}
+	return &TranslationResult{
+		Query:    "DO $mygo$ BEGIN " + strings.Join(statements, " ") + " END $mygo$",
+		Warnings: []string{"SET GLOBAL TRANSACTION applies to new sessions on the current database only"},
+	}, nil
+}
+
+// xaRecoverQuery lists prepared transactions in the columns of MySQL's XA
+// RECOVER, splitting identifiers with a branch qualifier back into their parts
+const xaRecoverQuery = `SELECT coalesce(m[3], '1')::int AS "formatID", length(coalesce(m[1], gid)) AS gtrid_length, ` +
+	`length(coalesce(m[2], '')) AS bqual_length, coalesce(m[1] || m[2], gid) AS data ` +
+	`FROM pg_prepared_xacts LEFT JOIN LATERAL regexp_match(gid, '^(.*),(.*),(-?[0-9]+)$') AS r(m) ON true ` +
+	`WHERE database = current_database() ORDER BY prepared`
+
+// translateXA maps MySQL's XA transactions onto two-phase commit: XA START
+// begins a transaction, XA PREPARE is PREPARE TRANSACTION, and XA COMMIT
+// and ROLLBACK finish a prepared transaction with COMMIT or ROLLBACK
+// PREPARED. The client checks the identifiers against the open transaction.
+func (t *Translator) translateXA(s *XAStmt) *TranslationResult {
+	result := &TranslationResult{IsSpecial: true, SpecialType: "transaction", Args: []string{"xa_" + strings.ToLower(s.Action), s.Xid}}
+	gid := quoteLiteral(s.Xid)
+	switch s.Action {
+	case "RECOVER":
+		return &TranslationResult{Query: xaRecoverQuery}
+	case "PREPARE":
+		result.Query = "PREPARE TRANSACTION " + gid
+	case "COMMIT":
+		result.Query = "COMMIT PREPARED " + gid
+		if s.OnePhase {
+			result.Query = "COMMIT"
+			result.Args = append(result.Args, "one_phase")
+		}
+	case "ROLLBACK":
+		result.Query = "ROLLBACK PREPARED " + gid
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateTransaction(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		query string
+		args  []string
+	}{
+		{"START TRANSACTION", "", []string{"begin", ""}},
+		{"BEGIN WORK", "", []string{"begin", ""}},
+		{"START TRANSACTION READ ONLY", "", []string{"begin", "READ ONLY"}},
+		{"START TRANSACTION WITH CONSISTENT SNAPSHOT, READ WRITE", "", []string{"begin", "ISOLATION LEVEL REPEATABLE READ, READ WRITE"}},
+		{"BEGIN ISOLATION LEVEL SERIALIZABLE", "", []string{"begin", "ISOLATION LEVEL SERIALIZABLE"}},
+		{"COMMIT", "COMMIT", []string{"commit"}},
+		{"COMMIT WORK AND CHAIN", "COMMIT AND CHAIN", []string{"commit", "chain"}},
+		{"ROLLBACK AND NO CHAIN RELEASE", "ROLLBACK", []string{"rollback", "release"}},
+		{"SAVEPOINT `before`", `SAVEPOINT "before"`, []string{"savepoint"}},
+		{"ROLLBACK WORK TO SAVEPOINT sp1", "ROLLBACK WORK TO SAVEPOINT sp1", []string{"savepoint"}},
+		{"RELEASE SAVEPOINT sp1", "RELEASE SAVEPOINT sp1", []string{"savepoint"}},
+		{"SET TRANSACTION ISOLATION LEVEL READ COMMITTED, READ ONLY", "", []string{"next", "ISOLATION LEVEL READ COMMITTED, READ ONLY"}},
+		{"SET autocommit = 0", "", []string{"autocommit", "0"}},
+		{"SET @@session.autocommit = ON", "", []string{"autocommit", "1"}},
+		{"XA START 'tx1'", "", []string{"xa_start", "tx1"}},
+		{"XA END 'tx1'", "", []string{"xa_end", "tx1"}},
+		{"XA PREPARE 'tx1'", "PREPARE TRANSACTION 'tx1'", []string{"xa_prepare", "tx1"}},
+		{"XA COMMIT 'tx1'", "COMMIT PREPARED 'tx1'", []string{"xa_commit", "tx1"}},
+		{"XA COMMIT 'tx1' ONE PHASE", "COMMIT", []string{"xa_commit", "tx1", "one_phase"}},
+		{"XA ROLLBACK 'tx1', 'b1', 7", "ROLLBACK PREPARED 'tx1,b1,7'", []string{"xa_rollback", "tx1,b1,7"}},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.SpecialType != "transaction" || result.Query != tt.query || !reflect.DeepEqual(result.Args, tt.args) {
+			t.Errorf("for %s: got %s %q %q, want %q %q", tt.input, result.SpecialType, result.Query, result.Args, tt.query, tt.args)
+		}
+	}
+
+	result, err := tr.Translate("START TRANSACTION WITH CONSISTENT SNAPSHOT")
+	if err != nil || !reflect.DeepEqual(result.Statements, []string{"SELECT 1"}) {
+		t.Errorf("WITH CONSISTENT SNAPSHOT: got %+v, %v", result, err)
+	}
+}
+
+func TestTranslateTransactionQueries(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+			"SET SESSION CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+		},
+		{
+			"SET GLOBAL TRANSACTION READ ONLY",
+			"DO $mygo$ BEGIN EXECUTE format('ALTER DATABASE %I SET default_transaction_read_only = ''on''', current_database()); END $mygo$",
+		},
+		{"COMMIT PREPARED 'tx1'", "COMMIT PREPARED 'tx1'"},
+		{"XA RECOVER", xaRecoverQuery},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.IsSpecial || result.Query != tt.expected {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.expected)
+		}
+	}
+
+	for _, input := range []string{
+		"SET autocommit = 2",
+		"SET GLOBAL autocommit = 0",
+		"COMMIT AND CHAIN RELEASE",
+		"XA START 'tx1' JOIN",
+		"SET TRANSACTION",
+	} {
+		if _, err := tr.Translate(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package translator
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 
 	"gomypg/internal/db"
@@ -10,7 +9,14 @@ import (
 
 // Translator translates MySQL commands to PostgreSQL equivalents
 type Translator struct {
-	dbType db.DBType
+	dbType        db.DBType
+	catalog       Catalog
+	pipesAsConcat bool  // MySQL's PIPES_AS_CONCAT SQL mode: || concatenates instead of OR
+	lastInsertID  int64 // What LAST_INSERT_ID() returns
+	routine       bool  // Translating a statement inside a stored routine body
+
+	varTypes map[string]string // PostgreSQL types of the session's user variables, by lower-cased name
+	warnings []string          // What the token rewrites of the statement could not carry over
 }
 
 // New creates a new translator
@@ -18,12 +24,35 @@ func New(dbType db.DBType) *Translator {
 	return &Translator{dbType: dbType}
 }
 
+// SetPipesAsConcat chooses how || is translated: as string concatenation, or
+// as logical OR like MySQL's default SQL mode
+func (t *Translator) SetPipesAsConcat(enabled bool) {
+	t.pipesAsConcat = enabled
+}
+
+// SetLastInsertID records the first key generated by the session's latest
+// INSERT, which later LAST_INSERT_ID() calls return
+func (t *Translator) SetLastInsertID(id int64) {
+	t.lastInsertID = id
+}
+
 // TranslationResult holds the translated query and metadata
 type TranslationResult struct {
 	Query       string
 	IsSpecial   bool   // Special command that needs custom handling
 	SpecialType string // Type of special command
 	Args        []string
+	Statements  []string // Several statements to run in order in one transaction, instead of Query
+	Warnings    []string // Parts of the statement PostgreSQL could not express
+	Setup       []string // Statements to run before Query, such as creating helper functions it calls
+
+	ReturnsInsertID bool // Query returns the generated key of each inserted row
+	NoTransaction   bool // PostgreSQL refuses to run Query inside a transaction block
+
+	Placeholders int               // Number of ? placeholders, numbered $1, $2, ... in Query
+	Params       []string          // User variables whose values are bound to the parameters after the placeholders
+	OutVars      []string          // User variables set from the columns of the row Query returns
+	SelectVars   map[string]string // User variables set from the last row Query returns, by column name
 }
 
 // Translate converts MySQL-style commands to the appropriate database dialect
@@ -40,174 +69,210 @@ func (t *Translator) Translate(input string) (*TranslationResult, error) {
 }
 
 func (t *Translator) translateForPostgres(input string) (*TranslationResult, error) {
-	// Remove trailing semicolon for pattern matching
-	trimmedInput := strings.TrimSuffix(input, ";")
-	upperTrimmed := strings.ToUpper(trimmedInput)
-
-	// Handle help commands
-	if result := t.handleHelpCommands(trimmedInput); result != nil {
-		return result, nil
+	t.warnings = nil
+	// Handle PostgreSQL backslash commands
+	if strings.HasPrefix(input, "\\") {
+		return t.translateBackslashCommand(input)
 	}
 
-	// SHOW DATABASES -> SELECT datname FROM pg_database
-	if upperTrimmed == "SHOW DATABASES" {
-		return &TranslationResult{
-			Query: "SELECT datname AS \"Database\" FROM pg_database WHERE datistemplate = false ORDER BY datname",
-		}, nil
+	tokens, err := Lex(input)
+	if err != nil {
+		return nil, err
+	}
+	// Convert literals first: numbering ? placeholders and decoding MySQL
+	// quoting has to see the statement in its original order
+	tokens, err = rewriteLiterals(tokens)
+	if err != nil {
+		return nil, err
 	}
 
-	// SHOW TABLES -> \dt equivalent
-	if upperTrimmed == "SHOW TABLES" {
-		return &TranslationResult{
-			Query: `SELECT tablename AS "Tables_in_database" 
-					FROM pg_tables 
-					WHERE schemaname = 'public' 
-					ORDER BY tablename`,
-		}, nil
+	placeholders := countPlaceholders(tokens)
+	tokens, bound, err := t.bindUserVariables(tokens)
+	if err != nil {
+		return nil, err
 	}
 
-	// SHOW FULL TABLES
-	if upperTrimmed == "SHOW FULL TABLES" {
-		return &TranslationResult{
-			Query: `SELECT tablename AS "Tables_in_database", 
-					'BASE TABLE' AS "Table_type"
-					FROM pg_tables 
-					WHERE schemaname = 'public' 
-					ORDER BY tablename`,
-		}, nil
+	stmt, err := parseTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+	result, err := t.translateStatement(stmt, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(result.Warnings, t.warnings...)
+	result.Placeholders = placeholders
+	if bound != nil {
+		if result.IsSpecial || len(result.Statements) > 0 {
+			return nil, fmt.Errorf("user variables are not supported in this statement on PostgreSQL")
+		}
+		result.Params = bound.params
+		result.OutVars = bound.outVars
+		result.SelectVars = bound.selectVars
+		result.Warnings = append(result.Warnings, bound.warnings...)
 	}
+	return result, nil
+}
 
-	// SHOW TABLES FROM/IN database
-	showTablesFromRe := regexp.MustCompile(`(?i)^SHOW\s+TABLES\s+(FROM|IN)\s+(\w+)$`)
-	if matches := showTablesFromRe.FindStringSubmatch(trimmedInput); matches != nil {
-		dbName := matches[2]
+// translateStatement translates a parsed statement; input is its source text
+func (t *Translator) translateStatement(stmt Statement, input string) (*TranslationResult, error) {
+	switch s := stmt.(type) {
+	case *HelpStmt:
+		return t.translateHelp(s), nil
+	case *ShowStmt:
+		return t.translateShow(s, input)
+	case *DescribeStmt:
+		return t.translateDescribe(s), nil
+	case *UseStmt:
 		return &TranslationResult{
-			Query: fmt.Sprintf(`SELECT tablename AS "Tables_in_%s" 
-					FROM pg_tables 
-					WHERE schemaname = 'public' 
-					ORDER BY tablename`, dbName),
 			IsSpecial:   true,
-			SpecialType: "cross_db_query",
-			Args:        []string{dbName},
-		}, nil
+			SpecialType: "use_database",
+			Args:        []string{s.Database},
+		}, nil
+	case *InsertStmt:
+		return t.translateInsert(s)
+	case *UpdateJoinStmt:
+		return t.translateUpdateJoin(s)
+	case *DeleteJoinStmt:
+		return t.translateDeleteJoin(s)
+	case *CreateTableStmt:
+		return t.translateCreateTable(s)
+	case *AlterTableStmt:
+		return t.translateAlterTable(s)
+	case *RenameTableStmt:
+		return t.translateRenameTable(s)
+	case *CreateIndexStmt:
+		return t.translateCreateIndex(s)
+	case *CreateViewStmt:
+		return t.translateCreateView(s)
+	case *CreateRoutineStmt:
+		return t.translateCreateRoutine(s)
+	case *CreateTriggerStmt:
+		return t.translateCreateTrigger(s)
+	case *DropTriggerStmt:
+		return t.translateDropTrigger(s)
+	case *CallStmt:
+		return t.translateCall(s)
+	case *SetVariablesStmt:
+		return t.translateSetVariables(s)
+	case *SetStmt:
+		return t.translateSet(s)
+	case *PrepareStmt:
+		return t.translatePrepare(s), nil
+	case *ExecuteStmt:
+		return t.translateExecute(s), nil
+	case *DeallocateStmt:
+		return t.translateDeallocate(s), nil
+	case *TruncateStmt:
+		return t.translateTruncate(s)
+	case *CreateDatabaseStmt:
+		return t.translateCreateDatabase(s)
+	case *DropDatabaseStmt:
+		return t.translateDropDatabase(s)
+	case *CreateUserStmt:
+		return t.translateCreateUser(s)
+	case *DropUserStmt:
+		return t.translateDropUser(s)
+	case *RenameUserStmt:
+		return t.translateRenameUser(s)
+	case *GrantStmt:
+		return t.translateGrant(s)
+	case *SetPasswordStmt:
+		return t.translateSetPassword(s)
+	case *LockTablesStmt:
+		return t.translateLockTables(s), nil
+	case *UnlockTablesStmt:
+		return &TranslationResult{IsSpecial: true, SpecialType: "unlock_tables"}, nil
+	case *TransactionStmt:
+		return t.translateTransaction(s)
+	case *XAStmt:
+		return t.translateXA(s), nil
+	case *FlushPrivilegesStmt:
+		// PostgreSQL applies role and privilege changes immediately
+		return &TranslationResult{}, nil
+	case *RawStmt:
+		return t.translateRaw(s)
+	default:
+		return nil, fmt.Errorf("unsupported statement type %T", stmt)
 	}
+}
 
-	// SHOW COLUMNS FROM table / DESC table / DESCRIBE table
-	showColumnsRe := regexp.MustCompile(`(?i)^(SHOW\s+COLUMNS\s+FROM|DESC|DESCRIBE)\s+(\w+)$`)
-	if matches := showColumnsRe.FindStringSubmatch(trimmedInput); matches != nil {
-		tableName := matches[2]
-		return &TranslationResult{
-			Query: fmt.Sprintf(`SELECT 
-				column_name AS "Field",
-				data_type AS "Type",
-				CASE WHEN is_nullable = 'YES' THEN 'YES' ELSE 'NO' END AS "Null",
-				CASE 
-					WHEN column_default LIKE 'nextval%%' THEN 'PRI'
-					ELSE ''
-				END AS "Key",
-				column_default AS "Default",
-				CASE 
-					WHEN column_default LIKE 'nextval%%' THEN 'auto_increment'
-					ELSE ''
-				END AS "Extra"
-			FROM information_schema.columns 
-			WHERE table_schema = 'public' AND table_name = '%s'
-			ORDER BY ordinal_position`, tableName),
-		}, nil
+func (t *Translator) translateShow(s *ShowStmt, input string) (*TranslationResult, error) {
+	if s.Where != nil {
+		return nil, fmt.Errorf("SHOW %s ... WHERE is not supported on PostgreSQL, use LIKE instead", s.Object)
 	}
 
-	// SHOW FULL COLUMNS FROM table
-	showFullColumnsRe := regexp.MustCompile(`(?i)^SHOW\s+FULL\s+COLUMNS\s+FROM\s+(\w+)$`)
-	if matches := showFullColumnsRe.FindStringSubmatch(trimmedInput); matches != nil {
-		tableName := matches[1]
+	switch s.Object {
+	// SHOW DATABASES -> SELECT datname FROM pg_database
+	case "DATABASES":
 		return &TranslationResult{
-			Query: fmt.Sprintf(`SELECT 
-				column_name AS "Field",
-				data_type AS "Type",
-				character_set_name AS "Collation",
-				CASE WHEN is_nullable = 'YES' THEN 'YES' ELSE 'NO' END AS "Null",
-				'' AS "Key",
-				column_default AS "Default",
-				'' AS "Extra",
-				'select,insert,update,references' AS "Privileges",
-				'' AS "Comment"
-			FROM information_schema.columns 
-			WHERE table_schema = 'public' AND table_name = '%s'
-			ORDER BY ordinal_position`, tableName),
+			Query: "SELECT datname AS \"Database\" FROM pg_database WHERE datistemplate = false" +
+				likeFilter("datname", s) + " ORDER BY datname",
 		}, nil
-	}
+
+	// SHOW [FULL] TABLES [FROM db] [LIKE 'pattern']
+	case "TABLES":
+		return t.showTables(s), nil
+
+	// SHOW [FULL] COLUMNS FROM table
+	case "COLUMNS":
+		if s.Name == "" {
+			return nil, fmt.Errorf("SHOW COLUMNS requires FROM table")
+		}
+		if s.Full {
+			return t.showFullColumns(s.Name, likeFilter("column_name", s)), nil
+		}
+		return t.showColumns(s.Name, likeFilter("column_name", s)), nil
 
 	// SHOW CREATE TABLE table
-	showCreateTableRe := regexp.MustCompile(`(?i)^SHOW\s+CREATE\s+TABLE\s+(\w+)$`)
-	if matches := showCreateTableRe.FindStringSubmatch(trimmedInput); matches != nil {
-		tableName := matches[1]
+	case "CREATE TABLE":
 		return &TranslationResult{
 			IsSpecial:   true,
 			SpecialType: "show_create_table",
-			Args:        []string{tableName},
+			Args:        []string{s.Name},
 		}, nil
-	}
 
 	// SHOW CREATE DATABASE database
-	showCreateDatabaseRe := regexp.MustCompile(`(?i)^SHOW\s+CREATE\s+DATABASE\s+(\w+)$`)
-	if matches := showCreateDatabaseRe.FindStringSubmatch(trimmedInput); matches != nil {
-		dbName := matches[1]
+	case "CREATE DATABASE", "CREATE SCHEMA":
 		return &TranslationResult{
 			IsSpecial:   true,
 			SpecialType: "show_create_database",
-			Args:        []string{dbName},
+			Args:        []string{s.Name},
 		}, nil
-	}
 
 	// SHOW INDEX FROM table / SHOW INDEXES FROM table / SHOW KEYS FROM table
-	showIndexRe := regexp.MustCompile(`(?i)^SHOW\s+(INDEX|INDEXES|KEYS)\s+FROM\s+(\w+)$`)
-	if matches := showIndexRe.FindStringSubmatch(trimmedInput); matches != nil {
-		tableName := matches[2]
+	case "INDEX":
+		if s.Name == "" {
+			return nil, fmt.Errorf("SHOW INDEX requires FROM table")
+		}
 		return &TranslationResult{
 			Query: fmt.Sprintf(`SELECT 
 				schemaname AS "Table",
 				indexname AS "Key_name",
 				indexdef AS "Index_definition"
 			FROM pg_indexes 
-			WHERE schemaname = 'public' AND tablename = '%s'`, tableName),
+			WHERE schemaname = 'public' AND tablename = %s`, quoteLiteral(s.Name)),
 		}, nil
-	}
 
 	// SHOW STATUS
-	if upperTrimmed == "SHOW STATUS" {
+	case "STATUS":
 		return &TranslationResult{
 			Query: `SELECT name AS "Variable_name", setting AS "Value" 
-					FROM pg_settings 
+					FROM pg_settings` + whereLike("name", s) + ` 
 					ORDER BY name 
 					LIMIT 50`,
 		}, nil
-	}
 
-	// SHOW VARIABLES / SHOW GLOBAL VARIABLES
-	if upperTrimmed == "SHOW VARIABLES" || upperTrimmed == "SHOW GLOBAL VARIABLES" {
+	// SHOW [GLOBAL] VARIABLES [LIKE 'pattern']
+	case "VARIABLES":
 		return &TranslationResult{
 			Query: `SELECT name AS "Variable_name", setting AS "Value" 
-					FROM pg_settings 
+					FROM pg_settings` + whereLike("name", s) + ` 
 					ORDER BY name`,
 		}, nil
-	}
-
-	// SHOW VARIABLES LIKE 'pattern'
-	showVarsLikeRe := regexp.MustCompile(`(?i)^SHOW\s+(GLOBAL\s+)?VARIABLES\s+LIKE\s+'([^']+)'$`)
-	if matches := showVarsLikeRe.FindStringSubmatch(trimmedInput); matches != nil {
-		pattern := strings.ReplaceAll(matches[2], "%", "%%")
-		pattern = strings.ReplaceAll(pattern, "_", ".")
-		pattern = strings.ReplaceAll(pattern, "%%", ".*")
-		return &TranslationResult{
-			Query: fmt.Sprintf(`SELECT name AS "Variable_name", setting AS "Value" 
-					FROM pg_settings 
-					WHERE name ~ '%s'
-					ORDER BY name`, pattern),
-		}, nil
-	}
 
-	// SHOW PROCESSLIST
-	if upperTrimmed == "SHOW PROCESSLIST" || upperTrimmed == "SHOW FULL PROCESSLIST" {
+	// SHOW [FULL] PROCESSLIST
+	case "PROCESSLIST":
 		return &TranslationResult{
 			Query: `SELECT 
 				pid AS "Id",
@@ -221,36 +286,13 @@ func (t *Translator) translateForPostgres(input string) (*TranslationResult, err
 			FROM pg_stat_activity 
 			WHERE pid <> pg_backend_pid()`,
 		}, nil
-	}
-
-	// SHOW GRANTS
-	if upperTrimmed == "SHOW GRANTS" {
-		return &TranslationResult{
-			Query: `SELECT 
-				grantee AS "User",
-				privilege_type AS "Privilege",
-				table_schema || '.' || table_name AS "On"
-			FROM information_schema.role_table_grants 
-			WHERE grantee = current_user`,
-		}, nil
-	}
 
-	// SHOW GRANTS FOR user
-	showGrantsForRe := regexp.MustCompile(`(?i)^SHOW\s+GRANTS\s+FOR\s+'?(\w+)'?(@'?[^']*'?)?$`)
-	if matches := showGrantsForRe.FindStringSubmatch(trimmedInput); matches != nil {
-		userName := matches[1]
-		return &TranslationResult{
-			Query: fmt.Sprintf(`SELECT 
-				grantee AS "User",
-				privilege_type AS "Privilege",
-				table_schema || '.' || table_name AS "On"
-			FROM information_schema.role_table_grants 
-			WHERE grantee = '%s'`, userName),
-		}, nil
-	}
+	// SHOW GRANTS [FOR user]
+	case "GRANTS":
+		return t.showGrants(s)
 
 	// SHOW TABLE STATUS
-	if upperTrimmed == "SHOW TABLE STATUS" {
+	case "TABLE STATUS":
 		return &TranslationResult{
 			Query: `SELECT 
 				relname AS "Name",
@@ -259,21 +301,19 @@ func (t *Translator) translateForPostgres(input string) (*TranslationResult, err
 				n_live_tup AS "Rows"
 			FROM pg_stat_user_tables 
 			JOIN pg_class ON relname = pg_stat_user_tables.relname
-			WHERE schemaname = 'public'`,
+			WHERE schemaname = 'public'` + likeFilter("pg_stat_user_tables.relname", s),
 		}, nil
-	}
 
 	// SHOW SCHEMAS
-	if upperTrimmed == "SHOW SCHEMAS" {
+	case "SCHEMAS":
 		return &TranslationResult{
 			Query: `SELECT schema_name AS "Database" 
-					FROM information_schema.schemata 
+					FROM information_schema.schemata` + whereLike("schema_name", s) + ` 
 					ORDER BY schema_name`,
 		}, nil
-	}
 
 	// SHOW TRIGGERS
-	if upperTrimmed == "SHOW TRIGGERS" {
+	case "TRIGGERS":
 		return &TranslationResult{
 			Query: `SELECT 
 				trigger_name AS "Trigger",
@@ -282,12 +322,11 @@ func (t *Translator) translateForPostgres(input string) (*TranslationResult, err
 				action_statement AS "Statement",
 				action_timing AS "Timing"
 			FROM information_schema.triggers 
-			WHERE trigger_schema = 'public'`,
+			WHERE trigger_schema = 'public'` + likeFilter("event_object_table", s),
 		}, nil
-	}
 
 	// SHOW FUNCTION STATUS / SHOW PROCEDURE STATUS
-	if upperTrimmed == "SHOW FUNCTION STATUS" || upperTrimmed == "SHOW PROCEDURE STATUS" {
+	case "FUNCTION STATUS", "PROCEDURE STATUS":
 		return &TranslationResult{
 			Query: `SELECT 
 				routine_name AS "Name",
@@ -295,32 +334,20 @@ func (t *Translator) translateForPostgres(input string) (*TranslationResult, err
 				routine_schema AS "Db",
 				external_language AS "Language"
 			FROM information_schema.routines 
-			WHERE routine_schema = 'public'`,
-		}, nil
-	}
-
-	// USE database
-	useDbRe := regexp.MustCompile(`(?i)^USE\s+(\w+)$`)
-	if matches := useDbRe.FindStringSubmatch(trimmedInput); matches != nil {
-		return &TranslationResult{
-			IsSpecial:   true,
-			SpecialType: "use_database",
-			Args:        []string{matches[1]},
+			WHERE routine_schema = 'public'` + likeFilter("routine_name", s),
 		}, nil
-	}
 
 	// SHOW ENGINES (PostgreSQL doesn't have storage engines)
-	if upperTrimmed == "SHOW ENGINES" {
+	case "ENGINES":
 		return &TranslationResult{
 			Query: `SELECT 
 				'PostgreSQL' AS "Engine",
 				'DEFAULT' AS "Support",
 				'PostgreSQL native storage' AS "Comment"`,
 		}, nil
-	}
 
 	// SHOW CHARSET / SHOW CHARACTER SET
-	if upperTrimmed == "SHOW CHARSET" || upperTrimmed == "SHOW CHARACTER SET" {
+	case "CHARSET":
 		return &TranslationResult{
 			Query: `SELECT 
 				pg_encoding_to_char(encoding) AS "Charset",
@@ -329,61 +356,212 @@ func (t *Translator) translateForPostgres(input string) (*TranslationResult, err
 			FROM pg_database 
 			WHERE datname = current_database()`,
 		}, nil
-	}
 
 	// SHOW COLLATION
-	if upperTrimmed == "SHOW COLLATION" {
+	case "COLLATION":
 		return &TranslationResult{
 			Query: `SELECT 
 				collname AS "Collation",
 				'utf8' AS "Charset"
-			FROM pg_collation 
+			FROM pg_collation` + whereLike("collname", s) + ` 
 			LIMIT 50`,
 		}, nil
-	}
 
-	// SHOW WARNINGS / SHOW ERRORS (PostgreSQL doesn't have these)
-	if upperTrimmed == "SHOW WARNINGS" || upperTrimmed == "SHOW ERRORS" {
+	// SHOW WARNINGS lists the warnings the client reported for the last statement
+	case "WARNINGS":
+		return &TranslationResult{IsSpecial: true, SpecialType: "show_warnings"}, nil
+
+	// SHOW ERRORS (PostgreSQL doesn't have these)
+	case "ERRORS":
 		return &TranslationResult{
 			Query: `SELECT 'Note' AS "Level", 0 AS "Code", 'PostgreSQL does not store warnings/errors like MySQL' AS "Message"`,
 		}, nil
 	}
 
-	// SELECT DATABASE()
-	if upperTrimmed == "SELECT DATABASE()" {
-		return &TranslationResult{
-			Query: "SELECT current_database() AS \"database()\"",
-		}, nil
+	// Not a MySQL-only SHOW (e.g. SHOW search_path), let PostgreSQL handle it
+	return &TranslationResult{Query: input}, nil
+}
+
+// showTables lists the tables of the public schema
+func (t *Translator) showTables(s *ShowStmt) *TranslationResult {
+	column := "Tables_in_database"
+	if s.Database != "" {
+		column = "Tables_in_" + s.Database
 	}
 
-	// SELECT VERSION()
-	if upperTrimmed == "SELECT VERSION()" {
-		return &TranslationResult{
-			Query: "SELECT version() AS \"version()\"",
-		}, nil
+	columns := fmt.Sprintf("tablename AS %s", quoteIdent(column))
+	if s.Full {
+		columns += `, 
+					'BASE TABLE' AS "Table_type"`
 	}
 
-	// SELECT USER() / SELECT CURRENT_USER()
-	if upperTrimmed == "SELECT USER()" || upperTrimmed == "SELECT CURRENT_USER()" {
-		return &TranslationResult{
-			Query: "SELECT current_user AS \"user()\"",
-		}, nil
+	result := &TranslationResult{
+		Query: fmt.Sprintf(`SELECT %s
+					FROM pg_tables 
+					WHERE schemaname = 'public'%s 
+					ORDER BY tablename`, columns, likeFilter("tablename", s)),
+	}
+	if s.Database != "" {
+		result.IsSpecial = true
+		result.SpecialType = "cross_db_query"
+		result.Args = []string{s.Database}
 	}
+	return result
+}
 
-	// SELECT NOW()
-	if upperTrimmed == "SELECT NOW()" {
-		return &TranslationResult{
-			Query: "SELECT now() AS \"now()\"",
-		}, nil
+// showColumns describes the columns of a table, optionally filtered by extra conditions
+func (t *Translator) showColumns(tableName, filter string) *TranslationResult {
+	return &TranslationResult{
+		Query: fmt.Sprintf(`SELECT 
+				column_name AS "Field",
+				data_type AS "Type",
+				CASE WHEN is_nullable = 'YES' THEN 'YES' ELSE 'NO' END AS "Null",
+				CASE 
+					WHEN column_default LIKE 'nextval%%' THEN 'PRI'
+					ELSE ''
+				END AS "Key",
+				column_default AS "Default",
+				CASE 
+					WHEN column_default LIKE 'nextval%%' THEN 'auto_increment'
+					ELSE ''
+				END AS "Extra"
+			FROM information_schema.columns 
+			WHERE table_schema = 'public' AND table_name = %s%s
+			ORDER BY ordinal_position`, quoteLiteral(tableName), filter),
 	}
+}
 
-	// Handle PostgreSQL backslash commands (translate to MySQL equivalents)
-	if strings.HasPrefix(input, "\\") {
-		return t.translateBackslashCommand(input)
+func (t *Translator) showFullColumns(tableName, filter string) *TranslationResult {
+	return &TranslationResult{
+		Query: fmt.Sprintf(`SELECT 
+				column_name AS "Field",
+				data_type AS "Type",
+				character_set_name AS "Collation",
+				CASE WHEN is_nullable = 'YES' THEN 'YES' ELSE 'NO' END AS "Null",
+				'' AS "Key",
+				column_default AS "Default",
+				'' AS "Extra",
+				'select,insert,update,references' AS "Privileges",
+				'' AS "Comment"
+			FROM information_schema.columns 
+			WHERE table_schema = 'public' AND table_name = %s%s
+			ORDER BY ordinal_position`, quoteLiteral(tableName), filter),
+	}
+}
+
+// translateDescribe handles DESC/DESCRIBE table [column]
+func (t *Translator) translateDescribe(s *DescribeStmt) *TranslationResult {
+	filter := ""
+	if s.Column != "" {
+		filter = " AND column_name LIKE " + quoteLiteral(s.Column)
 	}
+	return t.showColumns(s.Table, filter)
+}
 
-	// No translation needed, return as-is
-	return &TranslationResult{Query: input}, nil
+// translateRaw handles statements without a structural translation
+func (t *Translator) translateRaw(s *RawStmt) (*TranslationResult, error) {
+	if result := t.translateSelectFunction(s); result != nil {
+		return result, nil
+	}
+
+	tokens := s.Tokens
+	switch s.Verb() {
+	case "DESC", "DESCRIBE":
+		// DESC SELECT ... is MySQL's spelling of EXPLAIN
+		tokens = append([]Token{{Kind: TokIdent, Text: "EXPLAIN"}}, tokens[1:]...)
+	case "DO":
+		// MySQL's DO evaluates expressions, as in DO GET_LOCK(...), where
+		// PostgreSQL's runs a code block given as a string
+		if next := nextSignificant(tokens, 1); next < len(tokens) && tokens[next].Kind != TokString &&
+			!tokens[next].IsOp("$") && !tokens[next].Is("LANGUAGE") {
+			tokens = append([]Token{{Kind: TokIdent, Text: "SELECT"}}, tokens[1:]...)
+		}
+	}
+
+	rewritten, err := t.rewriteTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+	query := render(rewritten)
+	return &TranslationResult{Query: query, Setup: lockSetup(query), NoTransaction: noTransaction(rewritten)}, nil
+}
+
+// noTransaction reports whether PostgreSQL refuses to run the statement
+// inside a transaction block, as it does VACUUM and CREATE INDEX CONCURRENTLY
+func noTransaction(tokens []Token) bool {
+	p := newParser(tokens)
+	switch first := p.next(); {
+	case first.Is("VACUUM"):
+		return true
+	case first.Is("ALTER"):
+		return p.accept("SYSTEM")
+	case first.Is("CREATE"), first.Is("DROP"):
+		if p.accept("DATABASE", "TABLESPACE", "SUBSCRIPTION") {
+			return true
+		}
+		p.accept("UNIQUE")
+		return p.accept("INDEX") && p.accept("CONCURRENTLY")
+	case first.Is("REINDEX"):
+		if p.accept("DATABASE", "SYSTEM") {
+			return true
+		}
+		for !p.atEnd() {
+			if p.next().Is("CONCURRENTLY") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectFunctions maps MySQL information functions to PostgreSQL expressions
+var selectFunctions = map[string]struct{ expr, column string }{
+	"DATABASE":     {"current_database()", "database()"},
+	"VERSION":      {"version()", "version()"},
+	"USER":         {"current_user", "user()"},
+	"CURRENT_USER": {"current_user", "user()"},
+	"NOW":          {"now()", "now()"},
+}
+
+// translateSelectFunction handles SELECT DATABASE(), SELECT VERSION() and friends
+func (t *Translator) translateSelectFunction(s *RawStmt) *TranslationResult {
+	p := newParser(s.Tokens)
+	if !p.accept("SELECT") {
+		return nil
+	}
+	fn, ok := selectFunctions[p.next().Upper()]
+	if !ok || !p.acceptOp("(") || !p.acceptOp(")") || !p.atEnd() {
+		return nil
+	}
+	return &TranslationResult{
+		Query: fmt.Sprintf("SELECT %s AS %s", fn.expr, quoteIdent(fn.column)),
+	}
+}
+
+// likeFilter returns an AND condition for SHOW ... LIKE 'pattern'
+func likeFilter(column string, s *ShowStmt) string {
+	if !s.HasLike {
+		return ""
+	}
+	return fmt.Sprintf(" AND %s LIKE %s", column, quoteLiteral(s.Like))
+}
+
+// whereLike returns a WHERE clause for SHOW ... LIKE 'pattern'
+func whereLike(column string, s *ShowStmt) string {
+	if !s.HasLike {
+		return ""
+	}
+	return fmt.Sprintf(" WHERE %s LIKE %s", column, quoteLiteral(s.Like))
+}
+
+// quoteLiteral renders s as a PostgreSQL string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteIdent renders name as a PostgreSQL quoted identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
 }
 
 func (t *Translator) translateBackslashCommand(input string) (*TranslationResult, error) {
@@ -504,41 +682,23 @@ func (t *Translator) translateBackslashCommand(input string) (*TranslationResult
 	}
 }
 
-// handleHelpCommands handles --help syntax for various commands
-func (t *Translator) handleHelpCommands(input string) *TranslationResult {
-	upperInput := strings.ToUpper(strings.TrimSpace(input))
-	
-	// SHOW --help
-	if upperInput == "SHOW --HELP" {
-		return &TranslationResult{
-			IsSpecial:   true,
-			SpecialType: "show_help",
-		}
-	}
-	
-	// SHOW CREATE --help
-	if upperInput == "SHOW CREATE --HELP" {
-		return &TranslationResult{
-			IsSpecial:   true,
-			SpecialType: "show_create_help",
-		}
-	}
-	
-	// SHOW TABLES --help
-	if upperInput == "SHOW TABLES --HELP" {
-		return &TranslationResult{
-			IsSpecial:   true,
-			SpecialType: "show_tables_help",
-		}
+// translateHelp handles --help syntax for various commands
+func (t *Translator) translateHelp(s *HelpStmt) *TranslationResult {
+	specialType := ""
+	switch s.Topic {
+	case "SHOW":
+		specialType = "show_help"
+	case "SHOW CREATE":
+		specialType = "show_create_help"
+	case "SHOW TABLES":
+		specialType = "show_tables_help"
+	case "SHOW COLUMNS", "DESC", "DESCRIBE":
+		specialType = "show_columns_help"
+	default:
+		specialType = "help"
 	}
-	
-	// SHOW COLUMNS --help or DESC --help
-	if upperInput == "SHOW COLUMNS --HELP" || upperInput == "DESC --HELP" || upperInput == "DESCRIBE --HELP" {
-		return &TranslationResult{
-			IsSpecial:   true,
-			SpecialType: "show_columns_help",
-		}
+	return &TranslationResult{
+		IsSpecial:   true,
+		SpecialType: specialType,
 	}
-	
-	return nil
 }
@@ -151,3 +151,54 @@ func TestTranslateShowIndex(t *testing.T) {
 		t.Errorf("expected query to contain 'users', got: %s", result.Query)
 	}
 }
+
+func TestTranslateShowTablesLike(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	result, err := tr.Translate("SHOW TABLES LIKE 'user%'; -- only users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(result.Query, "tablename LIKE 'user%'") {
+		t.Errorf("expected LIKE filter on tablename, got: %s", result.Query)
+	}
+}
+
+func TestTranslateIrregularWhitespace(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input    string
+		contains string
+	}{
+		{"show\n   full\ttables ;", "Table_type"},
+		{"SHOW /* list */ DATABASES", "pg_database"},
+		{"desc `users`", "table_name = 'users'"},
+		{"SELECT  version( ) ;", "version() AS \"version()\""},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if !strings.Contains(result.Query, tt.contains) {
+			t.Errorf("for %s: expected query to contain %s, got: %s", tt.input, tt.contains, result.Query)
+		}
+	}
+}
+
+func TestTranslateShowPassthrough(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	result, err := tr.Translate("SHOW search_path")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Query != "SHOW search_path" {
+		t.Errorf("expected PostgreSQL SHOW to pass through, got: %s", result.Query)
+	}
+}
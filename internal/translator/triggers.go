@@ -0,0 +1,201 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// triggerFunctionPrefix starts the name of the function generated for each
+// translated trigger, so that DROP TRIGGER can drop it too
+const triggerFunctionPrefix = "mygo_trigger_"
+
+// CreateTriggerStmt is CREATE [DEFINER = user] TRIGGER [IF NOT EXISTS] name
+// {BEFORE|AFTER} {INSERT|UPDATE|DELETE} ON table FOR EACH ROW
+// [{FOLLOWS|PRECEDES} other] body
+type CreateTriggerStmt struct {
+	IfNotExists bool
+	Name        []Token
+	Timing      string // BEFORE or AFTER
+	Event       string // INSERT, UPDATE or DELETE
+	Table       []Token
+	Order       string  // FOLLOWS or PRECEDES clause, if any
+	Body        []Token // The trigger's statement, usually BEGIN ... END
+}
+
+// DropTriggerStmt is DROP TRIGGER [IF EXISTS] [schema.]name
+type DropTriggerStmt struct {
+	IfExists bool
+	Name     []Token
+}
+
+func (*CreateTriggerStmt) statementNode() {}
+func (*DropTriggerStmt) statementNode()   {}
+
+// parseCreateTrigger parses MySQL's CREATE TRIGGER. PostgreSQL's form, which
+// executes a function instead of a body, fails to parse and is passed through.
+func (p *parser) parseCreateTrigger() (Statement, error) {
+	p.next() // CREATE
+	stmt := &CreateTriggerStmt{}
+	if p.accept("DEFINER") {
+		p.acceptOp("=")
+		if _, _, err := p.userSpec(); err != nil {
+			return nil, err
+		}
+	}
+	p.next() // TRIGGER
+	stmt.IfNotExists = p.acceptSeq("IF", "NOT", "EXISTS")
+
+	var err error
+	if stmt.Name, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.peek().Is("BEFORE"), p.peek().Is("AFTER"):
+		stmt.Timing = p.next().Upper()
+	default:
+		return nil, p.errorf("expected BEFORE or AFTER")
+	}
+	switch {
+	case p.peek().Is("INSERT"), p.peek().Is("UPDATE"), p.peek().Is("DELETE"):
+		stmt.Event = p.next().Upper()
+	default:
+		return nil, p.errorf("expected INSERT, UPDATE or DELETE")
+	}
+	if err := p.expect("ON"); err != nil {
+		return nil, err
+	}
+	if stmt.Table, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if !p.acceptSeq("FOR", "EACH", "ROW") {
+		return nil, p.errorf("expected FOR EACH ROW")
+	}
+	if p.peek().Is("FOLLOWS") || p.peek().Is("PRECEDES") {
+		start := p.mark()
+		p.next()
+		if _, err := p.tableName(); err != nil {
+			return nil, err
+		}
+		stmt.Order = renderTrimmed(p.since(start))
+	}
+
+	if p.atEnd() || p.peek().Is("EXECUTE") {
+		return nil, p.errorf("expected trigger body")
+	}
+	stmt.Body = trimTrivia(p.rest())
+	return stmt, nil
+}
+
+// parseDropTrigger parses MySQL's DROP TRIGGER, which names no table.
+// PostgreSQL's DROP TRIGGER ... ON table is passed through.
+func (p *parser) parseDropTrigger() (Statement, error) {
+	p.next() // DROP
+	p.next() // TRIGGER
+	stmt := &DropTriggerStmt{IfExists: p.acceptSeq("IF", "EXISTS")}
+	var err error
+	if stmt.Name, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// translateCreateTrigger creates a trigger function from the trigger's body
+// and a trigger that executes it. MySQL trigger names are unique within a
+// schema rather than per table, which the generated function's name relies on.
+func (t *Translator) translateCreateTrigger(s *CreateTriggerStmt) (*TranslationResult, error) {
+	if s.IfNotExists {
+		return nil, fmt.Errorf("CREATE TRIGGER IF NOT EXISTS is not supported on PostgreSQL")
+	}
+
+	body := t.newPLpgSQL(s.Body, false)
+	body.trigger = true
+	lines, err := body.translate()
+	if err != nil {
+		return nil, err
+	}
+	if len(body.problems) > 0 {
+		return nil, fmt.Errorf("trigger %s cannot be translated to PL/pgSQL: %s",
+			render(s.Name), strings.Join(body.problems, "; "))
+	}
+
+	// A row trigger returns the row to write; before DELETE that is OLD, and
+	// after the event the value is ignored
+	ret := "RETURN NEW;"
+	if s.Event == "DELETE" {
+		ret = "RETURN OLD;"
+	}
+	last := len(lines) - 1
+	if strings.HasPrefix(lines[0], "<<") || containsLine(lines, "EXCEPTION") {
+		// LEAVE and exception handlers exit the block, so return after it
+		lines = append(append([]string{"BEGIN"}, indent(lines)...), indent([]string{ret})[0], "END;")
+	} else {
+		lines = append(append(lines[:last:last], indent([]string{ret})[0]), lines[last])
+	}
+
+	name := s.Name[len(s.Name)-1]
+	function := triggerFunction(s.Name)
+	code := "#variable_conflict use_variable\n" + strings.Join(lines, "\n")
+	statements := []string{
+		fmt.Sprintf("DO $mygo$ BEGIN IF EXISTS (SELECT %s) THEN RAISE EXCEPTION 'Trigger already exists'; END IF; END $mygo$",
+			triggerLookup(s.Name)),
+		"CREATE OR REPLACE FUNCTION " + function + "() RETURNS trigger\nLANGUAGE plpgsql AS " + dollarQuote(code),
+		fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH ROW EXECUTE FUNCTION %s()",
+			name.Text, s.Timing, s.Event, render(s.Table), function),
+	}
+	result := statementsResult(statements)
+	result.Warnings = body.warnings
+	if s.Order != "" {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("%s was ignored: PostgreSQL fires triggers for the same event in order of their names", s.Order))
+	}
+	return result, nil
+}
+
+// translateDropTrigger finds the table of the trigger, which PostgreSQL's
+// DROP TRIGGER needs, then drops the trigger and its generated function
+func (t *Translator) translateDropTrigger(s *DropTriggerStmt) (*TranslationResult, error) {
+	missing := "RAISE EXCEPTION 'Trigger does not exist'"
+	if s.IfExists {
+		missing = "RETURN"
+	}
+	name := s.Name[len(s.Name)-1]
+	return statementsResult([]string{
+		fmt.Sprintf("DO $mygo$ DECLARE tbl regclass; BEGIN SELECT tgrelid::regclass INTO tbl %s; "+
+			"IF tbl IS NULL THEN %s; END IF; EXECUTE format('DROP TRIGGER %%I ON %%s', %s, tbl); END $mygo$",
+			triggerLookup(s.Name), missing, quoteLiteral(catalogName(name))),
+		"DROP FUNCTION IF EXISTS " + triggerFunction(s.Name) + "()",
+	}), nil
+}
+
+// triggerFunction names the function generated for a trigger, in the
+// trigger's schema
+func triggerFunction(name []Token) string {
+	function := pgIdent(triggerFunctionPrefix + catalogName(name[len(name)-1]))
+	if len(name) > 1 {
+		return render(name[:len(name)-1]) + function
+	}
+	return function
+}
+
+// triggerLookup selects from pg_trigger the trigger of a MySQL trigger name,
+// which is either qualified with its schema or on a table in the search path
+func triggerLookup(name []Token) string {
+	lookup := "FROM pg_trigger WHERE NOT tgisinternal AND tgname = " + quoteLiteral(catalogName(name[len(name)-1]))
+	if len(name) > 1 {
+		return lookup + " AND (SELECT relnamespace FROM pg_class WHERE oid = tgrelid) = " +
+			"to_regnamespace(" + quoteLiteral(catalogName(name[0])) + ")"
+	}
+	return lookup + " AND pg_table_is_visible(tgrelid)"
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package translator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateTrigger(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"CREATE TRIGGER trg_orders_touch BEFORE UPDATE ON orders FOR EACH ROW\n" +
+				"BEGIN\n" +
+				"  IF NEW.status <> OLD.status THEN\n" +
+				"    SET NEW.updated_at = NOW(), NEW.note = CONCAT('was ', OLD.status);\n" +
+				"  END IF;\n" +
+				"END",
+			[]string{
+				"DO $mygo$ BEGIN IF EXISTS (SELECT FROM pg_trigger WHERE NOT tgisinternal AND tgname = 'trg_orders_touch' " +
+					"AND pg_table_is_visible(tgrelid)) THEN RAISE EXCEPTION 'Trigger already exists'; END IF; END $mygo$",
+				"CREATE OR REPLACE FUNCTION mygo_trigger_trg_orders_touch() RETURNS trigger\n" +
+					"LANGUAGE plpgsql AS $$\n#variable_conflict use_variable\n" +
+					"BEGIN\n" +
+					"    IF NEW.status <> OLD.status THEN\n" +
+					"        NEW.updated_at := NOW();\n" +
+					"        NEW.note := CONCAT('was ', OLD.status);\n" +
+					"    END IF;\n" +
+					"    RETURN NEW;\n" +
+					"END;\n$$",
+				"CREATE TRIGGER trg_orders_touch BEFORE UPDATE ON orders FOR EACH ROW EXECUTE FUNCTION mygo_trigger_trg_orders_touch()",
+			},
+		},
+		{
+			"CREATE DEFINER=`root`@`localhost` TRIGGER shop.`trg_log` AFTER DELETE ON shop.orders FOR EACH ROW " +
+				"INSERT INTO logs (level, message) VALUES ('INFO', CONCAT('deleted ', OLD.id))",
+			[]string{
+				"DO $mygo$ BEGIN IF EXISTS (SELECT FROM pg_trigger WHERE NOT tgisinternal AND tgname = 'trg_log' " +
+					"AND (SELECT relnamespace FROM pg_class WHERE oid = tgrelid) = to_regnamespace('shop')) " +
+					"THEN RAISE EXCEPTION 'Trigger already exists'; END IF; END $mygo$",
+				"CREATE OR REPLACE FUNCTION shop.mygo_trigger_trg_log() RETURNS trigger\n" +
+					"LANGUAGE plpgsql AS $$\n#variable_conflict use_variable\n" +
+					"BEGIN\n" +
+					"    INSERT INTO logs (level, message) VALUES ('INFO', CONCAT('deleted ', OLD.id));\n" +
+					"    RETURN OLD;\n" +
+					"END;\n$$",
+				`CREATE TRIGGER "trg_log" AFTER DELETE ON shop.orders FOR EACH ROW EXECUTE FUNCTION shop.mygo_trigger_trg_log()`,
+			},
+		},
+		{
+			// LEAVE exits the labeled block, so RETURN follows it
+			"CREATE TRIGGER trg_qty BEFORE INSERT ON items FOR EACH ROW FOLLOWS trg_other\n" +
+				"body: BEGIN\n" +
+				"  IF NEW.qty IS NULL THEN LEAVE body; END IF;\n" +
+				"  IF NEW.qty < 0 THEN SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'negative quantity'; END IF;\n" +
+				"END body",
+			[]string{
+				"DO $mygo$ BEGIN IF EXISTS (SELECT FROM pg_trigger WHERE NOT tgisinternal AND tgname = 'trg_qty' " +
+					"AND pg_table_is_visible(tgrelid)) THEN RAISE EXCEPTION 'Trigger already exists'; END IF; END $mygo$",
+				"CREATE OR REPLACE FUNCTION mygo_trigger_trg_qty() RETURNS trigger\n" +
+					"LANGUAGE plpgsql AS $$\n#variable_conflict use_variable\n" +
+					"BEGIN\n" +
+					"    <<body>>\n" +
+					"    BEGIN\n" +
+					"        IF NEW.qty IS NULL THEN\n" +
+					"            EXIT body;\n" +
+					"        END IF;\n" +
+					"        IF NEW.qty < 0 THEN\n" +
+					"            RAISE EXCEPTION USING ERRCODE = '45000', MESSAGE = 'negative quantity';\n" +
+					"        END IF;\n" +
+					"    END body;\n" +
+					"    RETURN NEW;\n" +
+					"END;\n$$",
+				"CREATE TRIGGER trg_qty BEFORE INSERT ON items FOR EACH ROW EXECUTE FUNCTION mygo_trigger_trg_qty()",
+			},
+		},
+		{
+			"DROP TRIGGER trg_orders_touch",
+			[]string{
+				"DO $mygo$ DECLARE tbl regclass; BEGIN SELECT tgrelid::regclass INTO tbl FROM pg_trigger WHERE NOT tgisinternal " +
+					"AND tgname = 'trg_orders_touch' AND pg_table_is_visible(tgrelid); " +
+					"IF tbl IS NULL THEN RAISE EXCEPTION 'Trigger does not exist'; END IF; " +
+					"EXECUTE format('DROP TRIGGER %I ON %s', 'trg_orders_touch', tbl); END $mygo$",
+				"DROP FUNCTION IF EXISTS mygo_trigger_trg_orders_touch()",
+			},
+		},
+		{
+			"DROP TRIGGER IF EXISTS shop.trg_log",
+			[]string{
+				"DO $mygo$ DECLARE tbl regclass; BEGIN SELECT tgrelid::regclass INTO tbl FROM pg_trigger WHERE NOT tgisinternal " +
+					"AND tgname = 'trg_log' AND (SELECT relnamespace FROM pg_class WHERE oid = tgrelid) = to_regnamespace('shop'); " +
+					"IF tbl IS NULL THEN RETURN; END IF; " +
+					"EXECUTE format('DROP TRIGGER %I ON %s', 'trg_log', tbl); END $mygo$",
+				"DROP FUNCTION IF EXISTS shop.mygo_trigger_trg_log()",
+			},
+		},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Statements, tt.expected) {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, strings.Join(result.Statements, "\n--\n"), strings.Join(tt.expected, "\n--\n"))
+		}
+	}
+
+	result, err := tr.Translate("CREATE TRIGGER t1 BEFORE INSERT ON t FOR EACH ROW PRECEDES t2 SET NEW.a = 1")
+	if err != nil || len(result.Warnings) != 1 || !strings.HasPrefix(result.Warnings[0], "PRECEDES t2 was ignored") {
+		t.Errorf("PRECEDES: got %+v, %v", result, err)
+	}
+}
+
+func TestTranslateTriggerErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	// PostgreSQL's own statements are passed through
+	for _, input := range []string{
+		"CREATE TRIGGER trg_order_status_log AFTER UPDATE OF status ON orders FOR EACH ROW EXECUTE FUNCTION log_order_changes()",
+		"CREATE TRIGGER trg BEFORE UPDATE ON orders FOR EACH ROW EXECUTE FUNCTION update_timestamp()",
+		"DROP TRIGGER IF EXISTS trg_order_status_log ON orders",
+	} {
+		result, err := tr.Translate(input)
+		if err != nil || result.Query != input {
+			t.Errorf("for %s: got %+v, %v", input, result, err)
+		}
+	}
+
+	for _, input := range []string{
+		"CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW BEGIN RETURN 1; END",
+		"CREATE TRIGGER trg AFTER INSERT ON t FOR EACH ROW COMMIT",
+		"CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW SELECT * FROM u",
+		"CREATE TRIGGER IF NOT EXISTS trg BEFORE INSERT ON t FOR EACH ROW SET NEW.a = 1",
+	} {
+		if _, err := tr.Translate(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (p *parser) parseInsert() (Statement, error) {
+	stmt := &InsertStmt{Replace: p.next().Is("REPLACE")}
+	for {
+		switch {
+		case p.accept("LOW_PRIORITY", "DELAYED", "HIGH_PRIORITY"):
+			continue
+		case p.accept("IGNORE"):
+			stmt.Ignore = true
+			continue
+		}
+		break
+	}
+	p.accept("INTO")
+
+	start := p.mark()
+	if _, err := p.qualifiedName(); err != nil {
+		return nil, err
+	}
+	stmt.Table = p.since(start)
+
+	if p.accept("PARTITION") {
+		return nil, fmt.Errorf("INSERT ... PARTITION is not supported on PostgreSQL, insert into the partition table directly")
+	}
+
+	// Column list, unless the parenthesis opens a SELECT
+	if p.peek().IsOp("(") && !p.peekN(1).Is("SELECT") && !p.peekN(1).Is("WITH") {
+		p.next()
+		for {
+			name, err := p.columnRef()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Columns = append(stmt.Columns, name)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	rest := p.rest()
+	end := len(rest)
+	if onDup := findOnDuplicate(rest); onDup >= 0 {
+		end = onDup
+		update := nextSignificant(rest, onDup+1) // DUPLICATE
+		update = nextSignificant(rest, update+1) // KEY
+		update = nextSignificant(rest, update+1) // UPDATE
+		for _, assignment := range splitTopLevel(rest[update+1:], ",") {
+			stmt.OnDuplicate = append(stmt.OnDuplicate, trimTrivia(assignment))
+		}
+	}
+	source := trimTrivia(rest[:end])
+	if len(source) == 0 {
+		return nil, p.errorf("expected VALUES, SELECT or SET")
+	}
+
+	switch first := source[0]; {
+	case first.Is("SET"):
+		if stmt.Columns != nil {
+			return nil, fmt.Errorf("syntax error: INSERT ... SET cannot have a column list")
+		}
+		var values [][]Token
+		for _, assignment := range splitTopLevel(source[1:], ",") {
+			column, value, err := splitAssignment(assignment)
+			if err != nil {
+				return nil, err
+			}
+			stmt.Columns = append(stmt.Columns, column)
+			values = append(values, value)
+		}
+		stmt.Source = concatTokens(pgTokens("VALUES ("), joinTokens(values, ", "), pgTokens(")"))
+
+	case first.Is("VALUES") || first.Is("VALUE"):
+		source[0].Text = "VALUES"
+		if as := findTopLevel(source, 0, "AS"); as >= 0 {
+			alias := trimTrivia(source[as+1:])
+			if len(alias) != 1 || !alias[0].IsIdent() {
+				return nil, fmt.Errorf("row alias column lists (VALUES ... AS alias(cols)) are not supported on PostgreSQL")
+			}
+			stmt.RowAlias = alias[0].Value
+			source = trimTrivia(source[:as])
+		}
+		stmt.Source = source
+
+	default:
+		stmt.Source = source
+	}
+	return stmt, nil
+}
+
+// columnRef consumes a possibly qualified column name and returns its last part
+func (p *parser) columnRef() (Token, error) {
+	tok := p.peek()
+	if _, err := p.qualifiedName(); err != nil {
+		return Token{}, err
+	}
+	for i := p.pos - 1; i >= 0; i-- {
+		if p.tokens[i].IsIdent() {
+			return p.tokens[i], nil
+		}
+	}
+	return tok, nil
+}
+
+// findOnDuplicate returns the index of a top-level ON DUPLICATE KEY UPDATE, or -1
+func findOnDuplicate(tokens []Token) int {
+	for i := findTopLevel(tokens, 0, "ON"); i >= 0; i = findTopLevel(tokens, i+1, "ON") {
+		dup := nextSignificant(tokens, i+1)
+		if dup < len(tokens) && tokens[dup].Is("DUPLICATE") {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitAssignment splits "[tbl.]col = expr" into the column token and the expression
+func splitAssignment(tokens []Token) (Token, []Token, error) {
+	for i, tok := range tokens {
+		if tok.IsOp("=") {
+			target := trimTrivia(tokens[:i])
+			if len(target) == 0 || !target[len(target)-1].IsIdent() {
+				break
+			}
+			return target[len(target)-1], trimTrivia(tokens[i+1:]), nil
+		}
+	}
+	return Token{}, nil, fmt.Errorf("syntax error: expected column = value near '%s'", renderTrimmed(tokens))
+}
+
+// joinTokens joins token slices with a separator
+func joinTokens(parts [][]Token, sep string) []Token {
+	var out []Token
+	for i, part := range parts {
+		if i > 0 {
+			out = append(out, pgTokens(sep)...)
+		}
+		out = append(out, part...)
+	}
+	return out
+}
+
+// translateInsert rewrites the MySQL upsert family to INSERT ... ON CONFLICT:
+//
+//	INSERT IGNORE INTO t ...               -> INSERT INTO t ... ON CONFLICT DO NOTHING
+//	REPLACE INTO t (a, b) VALUES ...       -> ... ON CONFLICT (key) DO UPDATE SET b = EXCLUDED.b
+//	... ON DUPLICATE KEY UPDATE b = VALUES(b)
+//	                                       -> ... ON CONFLICT (key) DO UPDATE SET b = EXCLUDED.b
+//
+// The conflict target is the primary key or the first unique index covering
+// the inserted columns, looked up through the catalog. REPLACE sets the
+// columns it does not insert back to their defaults, as MySQL's new row has them.
+func (t *Translator) translateInsert(s *InsertStmt) (*TranslationResult, error) {
+	key, err := t.insertIDColumn(s)
+	if err != nil {
+		return nil, err
+	}
+	source, err := t.rewriteTokens(s.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "INSERT INTO " + render(s.Table)
+	if s.Columns != nil {
+		var names []string
+		for _, col := range s.Columns {
+			names = append(names, col.Text)
+		}
+		query += " (" + strings.Join(names, ", ") + ")"
+	}
+	query += " " + render(source)
+
+	conflict, warnings, err := t.conflictClause(s)
+	if err != nil {
+		return nil, err
+	}
+	if conflict != "" {
+		query += " " + conflict
+	}
+	result := &TranslationResult{Query: query, Warnings: warnings}
+	if key != "" {
+		result.Query += " RETURNING " + pgIdent(key)
+		result.ReturnsInsertID = true
+	}
+	return result, nil
+}
+
+func (t *Translator) conflictClause(s *InsertStmt) (string, []string, error) {
+	switch {
+	case s.OnDuplicate != nil:
+		key, warnings, err := t.conflictTarget(s, "ON DUPLICATE KEY UPDATE")
+		if err != nil {
+			return "", nil, err
+		}
+		var sets []string
+		for _, assignment := range s.OnDuplicate {
+			set, err := t.rewriteOnDuplicate(assignment, s)
+			if err != nil {
+				return "", nil, err
+			}
+			sets = append(sets, set)
+		}
+		return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(quoteNames(key), ", "), strings.Join(sets, ", ")), warnings, nil
+
+	case s.Replace:
+		key, warnings, err := t.conflictTarget(s, "REPLACE")
+		if err != nil {
+			return "", nil, err
+		}
+		inserted, err := t.insertColumns(s)
+		if err != nil {
+			return "", nil, err
+		}
+		columns, err := t.catalog.Columns(render(s.Table))
+		if err != nil {
+			return "", nil, err
+		}
+		var sets []string
+		for _, col := range columns {
+			switch {
+			case containsString(key, col):
+			case containsString(inserted, col):
+				sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", pgIdent(col), pgIdent(col)))
+			default:
+				sets = append(sets, pgIdent(col)+" = DEFAULT")
+			}
+		}
+		if len(sets) == 0 {
+			return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(quoteNames(key), ", ")), warnings, nil
+		}
+		return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(quoteNames(key), ", "), strings.Join(sets, ", ")), warnings, nil
+
+	case s.Ignore:
+		return "ON CONFLICT DO NOTHING", nil, nil
+	}
+	return "", nil, nil
+}
+
+// insertColumns returns the catalog names of the inserted columns
+func (t *Translator) insertColumns(s *InsertStmt) ([]string, error) {
+	if s.Columns == nil {
+		catalog, err := t.requireCatalog("INSERT without a column list")
+		if err != nil {
+			return nil, err
+		}
+		return catalog.Columns(render(s.Table))
+	}
+	var names []string
+	for _, col := range s.Columns {
+		names = append(names, catalogName(col))
+	}
+	return names, nil
+}
+
+// conflictTarget picks the unique key PostgreSQL should use for ON CONFLICT.
+// ON CONFLICT handles duplicates in that key only, where MySQL handles them
+// in any unique key, which is warned about for tables with several.
+func (t *Translator) conflictTarget(s *InsertStmt, what string) ([]string, []string, error) {
+	catalog, err := t.requireCatalog(what)
+	if err != nil {
+		return nil, nil, err
+	}
+	table := render(s.Table)
+	keys, err := catalog.UniqueKeys(table)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("cannot translate %s: table %s has no primary key or unique index", what, table)
+	}
+
+	columns, err := t.insertColumns(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range keys {
+		covered := true
+		for _, col := range key {
+			if !containsString(columns, col) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			var warnings []string
+			if len(keys) > 1 {
+				warnings = append(warnings, fmt.Sprintf("%s on %s only handles duplicates in (%s): "+
+					"a duplicate in another unique key of the table raises an error", what, table, strings.Join(key, ", ")))
+			}
+			return key, warnings, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("cannot translate %s: no primary key or unique index of %s is covered by the inserted columns", what, table)
+}
+
+// rewriteOnDuplicate translates one ON DUPLICATE KEY UPDATE assignment.
+// VALUES(col) and alias.col refer to the proposed row (EXCLUDED); bare
+// column names refer to the existing row and are qualified with the table,
+// since PostgreSQL treats them as ambiguous.
+func (t *Translator) rewriteOnDuplicate(assignment []Token, s *InsertStmt) (string, error) {
+	column, value, err := splitAssignment(assignment)
+	if err != nil {
+		return "", err
+	}
+	table := s.Table[len(s.Table)-1].Text
+
+	var out []Token
+	for i := 0; i < len(value); i++ {
+		tok := value[i]
+		next := nextSignificant(value, i+1)
+		prev := prevSignificant(value, i)
+		followedBy := func(op string) bool { return next < len(value) && value[next].IsOp(op) }
+		precededBy := func(op string) bool { return prev >= 0 && value[prev].IsOp(op) }
+
+		switch {
+		case tok.Is("VALUES") && followedBy("("):
+			end := matchParen(value, next)
+			var inner []Token
+			if end >= 0 {
+				inner = trimTrivia(value[next+1 : end])
+			}
+			if len(inner) == 0 || !inner[len(inner)-1].IsIdent() {
+				return "", fmt.Errorf("syntax error: expected VALUES(column) near '%s'", renderTrimmed(value))
+			}
+			out = append(out, pgTokens("EXCLUDED."+inner[len(inner)-1].Text)...)
+			i = end
+
+		case tok.IsOp("(") && next < len(value) && value[next].Is("SELECT"):
+			// Leave subqueries alone, their columns belong to their own FROM
+			end := matchParen(value, i)
+			if end < 0 {
+				return "", fmt.Errorf("syntax error: unbalanced parentheses near '%s'", renderTrimmed(value))
+			}
+			out = append(out, value[i:end+1]...)
+			i = end
+
+		case s.RowAlias != "" && tok.IsIdent() && strings.EqualFold(tok.Value, s.RowAlias) && followedBy("."):
+			out = append(out, pgTokens("EXCLUDED")...)
+
+		case isColumnRef(value, i) && !followedBy("(") && !followedBy(".") && !precededBy(".") && !(prev >= 0 && value[prev].Is("AS")):
+			out = append(out, pgTokens(table+"."+tok.Text)...)
+
+		default:
+			out = append(out, tok)
+		}
+	}
+
+	rewritten, err := t.rewriteTokens(out)
+	if err != nil {
+		return "", err
+	}
+	return column.Text + " = " + render(rewritten), nil
+}
+
+// isColumnRef reports whether the identifier at i in tokens can name a
+// column. An interval unit such as DAY or MONTH does, unless it stands where
+// a unit goes.
+func isColumnRef(tokens []Token, i int) bool {
+	tok := tokens[i]
+	switch {
+	case tok.Kind == TokQuotedIdent:
+		return true
+	case isIntervalUnit(tok):
+		return !isUnitPosition(tokens, i)
+	}
+	return tok.Kind == TokIdent && !exprKeywords[tok.Upper()]
+}
+
+// isUnitPosition reports whether the token at i stands where an interval unit
+// goes: after the amount of INTERVAL, before FROM in EXTRACT(unit FROM d), or
+// first in TIMESTAMPADD(unit, ...) and TIMESTAMPDIFF(unit, ...)
+func isUnitPosition(tokens []Token, i int) bool {
+	if next := nextSignificant(tokens, i+1); next < len(tokens) && tokens[next].Is("FROM") {
+		return true
+	}
+	if prev := prevSignificant(tokens, i); prev >= 0 && tokens[prev].IsOp("(") {
+		fn := prevSignificant(tokens, prev)
+		return fn >= 0 && (tokens[fn].Is("TIMESTAMPADD") || tokens[fn].Is("TIMESTAMPDIFF"))
+	}
+	start := primaryStart(tokens, i-1)
+	if start < 0 {
+		return false
+	}
+	before := prevSignificant(tokens, start)
+	if before >= 0 && (tokens[before].IsOp("-") || tokens[before].IsOp("+")) {
+		before = prevSignificant(tokens, before)
+	}
+	return before >= 0 && tokens[before].Is("INTERVAL")
+}
+
+// quoteNames renders catalog names as identifiers
+func quoteNames(names []string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = pgIdent(name)
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
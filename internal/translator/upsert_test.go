@@ -0,0 +1,175 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+// fakeCatalog serves table definitions from memory
+type fakeCatalog struct {
+	keys          map[string][][]string
+	columns       map[string][]string
+	autoIncrement map[string]string
+	procedures    map[string][]string
+	settings      map[string]bool
+	indexes       map[string]map[string]bool // Whether a constraint owns the index
+	database      string
+}
+
+func (c *fakeCatalog) UniqueKeys(table string) ([][]string, error) {
+	return c.keys[table], nil
+}
+
+func (c *fakeCatalog) Columns(table string) ([]string, error) {
+	columns, ok := c.columns[table]
+	if !ok {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+	return columns, nil
+}
+
+func (c *fakeCatalog) AutoIncrementColumn(table string) (string, error) {
+	return c.autoIncrement[table], nil
+}
+
+func (c *fakeCatalog) ProcedureModes(procedure string, args int) ([]string, error) {
+	if modes := c.procedures[procedure]; len(modes) == args {
+		return modes, nil
+	}
+	return nil, nil
+}
+
+func (c *fakeCatalog) Index(table, name string) (string, bool, error) {
+	constraint, ok := c.indexes[table][name]
+	if !ok {
+		return "", false, nil
+	}
+	return name, constraint, nil
+}
+
+func (c *fakeCatalog) GetCurrentDatabase() string {
+	return c.database
+}
+
+func (c *fakeCatalog) HasSetting(name string) (bool, error) {
+	return c.settings[name], nil
+}
+
+func newUpsertTranslator() *Translator {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{
+		keys: map[string][][]string{
+			"users":    {{"id"}, {"email"}},
+			"counters": {{"name", "day"}},
+			"logs":     nil,
+			"stats":    {{"id"}},
+		},
+		columns: map[string][]string{
+			"users":    {"id", "email", "name"},
+			"counters": {"name", "day", "hits"},
+			"logs":     {"id", "msg"},
+			"stats":    {"id", "day", "month", "hour"},
+		},
+	})
+	return tr
+}
+
+func TestTranslateUpsert(t *testing.T) {
+	tr := newUpsertTranslator()
+
+	tests := []struct {
+		input    string
+		want     string
+		warnings int
+	}{
+		{
+			"INSERT IGNORE INTO users (id, name) VALUES (1, 'a')",
+			"INSERT INTO users (id, name) VALUES (1, 'a') ON CONFLICT DO NOTHING",
+			0,
+		},
+		{
+			"REPLACE INTO users (id, email, name) VALUES (1, 'a@x', 'a')",
+			"INSERT INTO users (id, email, name) VALUES (1, 'a@x', 'a') ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, name = EXCLUDED.name",
+			1,
+		},
+		{
+			"REPLACE INTO users (id, email) VALUES (1, 'a@x')",
+			"INSERT INTO users (id, email) VALUES (1, 'a@x') ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, name = DEFAULT",
+			1,
+		},
+		{
+			"REPLACE INTO users VALUES (1, 'a@x', 'a')",
+			"INSERT INTO users VALUES (1, 'a@x', 'a') ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, name = EXCLUDED.name",
+			1,
+		},
+		{
+			"INSERT INTO users (email, name) VALUES ('a@x', 'a') ON DUPLICATE KEY UPDATE name = VALUES(name)",
+			"INSERT INTO users (email, name) VALUES ('a@x', 'a') ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name",
+			1,
+		},
+		{
+			"INSERT INTO counters (name, day, hits) VALUES ('home', '2024-01-01', 1) ON DUPLICATE KEY UPDATE hits = hits + VALUES(hits)",
+			"INSERT INTO counters (name, day, hits) VALUES ('home', '2024-01-01', 1) ON CONFLICT (name, day) DO UPDATE SET hits = counters.hits + EXCLUDED.hits",
+			0,
+		},
+		{
+			"INSERT INTO counters (name, day, hits) VALUES ('home', '2024-01-01', 1) AS new ON DUPLICATE KEY UPDATE hits = hits + new.hits",
+			"INSERT INTO counters (name, day, hits) VALUES ('home', '2024-01-01', 1) ON CONFLICT (name, day) DO UPDATE SET hits = counters.hits + EXCLUDED.hits",
+			0,
+		},
+		{
+			"INSERT INTO stats (id, day) VALUES (1, 1) ON DUPLICATE KEY UPDATE day = day + 1, month = GREATEST(month, day)",
+			"INSERT INTO stats (id, day) VALUES (1, 1) ON CONFLICT (id) DO UPDATE SET day = stats.day + 1, month = GREATEST(stats.month, stats.day)",
+			0,
+		},
+		{
+			"INSERT INTO stats (id, hour) VALUES (1, NOW()) ON DUPLICATE KEY UPDATE hour = hour + INTERVAL 1 HOUR, day = EXTRACT(DAY FROM hour), month = TIMESTAMPDIFF(MONTH, hour, NOW())",
+			"INSERT INTO stats (id, hour) VALUES (1, NOW()) ON CONFLICT (id) DO UPDATE SET hour = stats.hour + INTERVAL '1 hours', " +
+				"day = EXTRACT(DAY FROM stats.hour), " +
+				"month = (extract(year FROM age((NOW())::timestamp, (stats.hour)::timestamp)) * 12 + extract(month FROM age((NOW())::timestamp, (stats.hour)::timestamp)))::bigint",
+			0,
+		},
+		{
+			"INSERT INTO users SET id = 1, name = 'a' ON DUPLICATE KEY UPDATE name = 'b'",
+			"INSERT INTO users (id, name) VALUES (1, 'a') ON CONFLICT (id) DO UPDATE SET name = 'b'",
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.want {
+			t.Errorf("for %s:\n got: %s\nwant: %s", tt.input, result.Query, tt.want)
+		}
+		// users has a second unique key, which ON CONFLICT does not cover
+		if len(result.Warnings) != tt.warnings {
+			t.Errorf("for %s: got warnings %q", tt.input, result.Warnings)
+		}
+	}
+}
+
+func TestTranslateUpsertErrors(t *testing.T) {
+	tests := []struct {
+		tr    *Translator
+		input string
+		want  string
+	}{
+		{New(db.PostgreSQL), "REPLACE INTO users (id) VALUES (1)", "requires a database connection"},
+		{newUpsertTranslator(), "REPLACE INTO logs (id, msg) VALUES (1, 'x')", "no primary key or unique index"},
+		{newUpsertTranslator(), "REPLACE INTO users (name) VALUES ('a')", "covered by the inserted columns"},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.tr.Translate(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("for %s: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,791 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Account is a MySQL account name, 'user'@'host'
+type Account struct {
+	User string
+	Host string // Empty when not given, which MySQL reads as '%'
+}
+
+// UserSpec is one account of CREATE USER or ALTER USER with its password
+type UserSpec struct {
+	Account
+	Password    *Token // IDENTIFIED BY 'password'
+	Unsupported string // An authentication clause PostgreSQL cannot take
+}
+
+// CreateUserStmt is CREATE USER or ALTER USER
+type CreateUserStmt struct {
+	Alter        bool
+	IfExists     bool // IF NOT EXISTS for CREATE USER, IF EXISTS for ALTER USER
+	Users        []*UserSpec
+	DefaultRoles []Account
+	ConnLimit    string
+	Lock         *bool // ACCOUNT LOCK or ACCOUNT UNLOCK
+	Comment      *Token
+	Ignored      []string // Clauses PostgreSQL roles cannot carry
+}
+
+// DropUserStmt is DROP USER [IF EXISTS] account, ...
+type DropUserStmt struct {
+	IfExists bool
+	Users    []Account
+}
+
+// RenameUserStmt is RENAME USER old TO new, ...
+type RenameUserStmt struct {
+	Renames [][2]Account
+}
+
+// Privilege is one entry of a GRANT or REVOKE privilege list
+type Privilege struct {
+	Name    string  // Upper-cased words, e.g. "SELECT" or "CREATE TEMPORARY TABLES"
+	Columns []Token // Column list with parentheses, if given
+}
+
+// GrantStmt is GRANT or REVOKE of privileges on db.*, db.table or *.*, or
+// of roles when Privileges is nil
+type GrantStmt struct {
+	Revoke      bool
+	Privileges  []Privilege
+	Roles       []Account
+	ObjectType  string // TABLE, FUNCTION or PROCEDURE, if given
+	Global      bool   // ON *.*
+	Schema      []Token
+	Table       []Token // Nil for db.* and *
+	Users       []Account
+	GrantOption bool // WITH GRANT OPTION, or WITH ADMIN OPTION for roles
+}
+
+// SetPasswordStmt is SET PASSWORD [FOR account] = 'password'
+type SetPasswordStmt struct {
+	User     *Account
+	Password Token
+}
+
+// FlushPrivilegesStmt is FLUSH PRIVILEGES
+type FlushPrivilegesStmt struct{}
+
+func (*CreateUserStmt) statementNode()      {}
+func (*DropUserStmt) statementNode()        {}
+func (*RenameUserStmt) statementNode()      {}
+func (*GrantStmt) statementNode()           {}
+func (*SetPasswordStmt) statementNode()     {}
+func (*FlushPrivilegesStmt) statementNode() {}
+
+// account consumes an account name, also accepting USER() for the current user
+func (p *parser) account() (Account, error) {
+	if p.peek().Is("USER") && p.peekN(1).IsOp("(") {
+		p.next()
+		p.next()
+		return Account{User: "CURRENT_USER"}, p.expectOp(")")
+	}
+	user, host, err := p.userSpec()
+	return Account{User: user, Host: host}, err
+}
+
+// accounts consumes a comma-separated list of account names
+func (p *parser) accounts() ([]Account, error) {
+	var list []Account
+	for {
+		account, err := p.account()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, account)
+		if !p.acceptOp(",") {
+			return list, nil
+		}
+	}
+}
+
+// parseCreateUser parses CREATE USER [IF NOT EXISTS] and ALTER USER [IF EXISTS]
+func (p *parser) parseCreateUser() (Statement, error) {
+	stmt := &CreateUserStmt{Alter: p.next().Is("ALTER")}
+	p.next() // USER
+	if stmt.Alter {
+		stmt.IfExists = p.acceptSeq("IF", "EXISTS")
+	} else {
+		stmt.IfExists = p.acceptSeq("IF", "NOT", "EXISTS")
+	}
+
+	for {
+		account, err := p.account()
+		if err != nil {
+			return nil, err
+		}
+		spec := &UserSpec{Account: account}
+		switch {
+		case p.acceptSeq("IDENTIFIED", "WITH"):
+			p.next() // Authentication plugin
+			if p.accept("AS") {
+				spec.Unsupported = "WITH ... AS"
+				p.next()
+				break
+			}
+			if !p.accept("BY") {
+				break
+			}
+			fallthrough
+		case p.acceptSeq("IDENTIFIED", "BY"):
+			if p.accept("PASSWORD") {
+				spec.Unsupported = "BY PASSWORD"
+			} else if p.acceptSeq("RANDOM", "PASSWORD") {
+				spec.Unsupported = "BY RANDOM PASSWORD"
+			}
+			if spec.Unsupported != "" {
+				if p.peek().Kind == TokString {
+					p.next()
+				}
+				break
+			}
+			tok := p.next()
+			if tok.Kind != TokString {
+				return nil, fmt.Errorf("syntax error: expected password string near '%s'", tok.Text)
+			}
+			spec.Password = &tok
+			for p.accept("REPLACE") {
+				p.next()
+			}
+			p.acceptSeq("RETAIN", "CURRENT", "PASSWORD")
+		case p.acceptSeq("DISCARD", "OLD", "PASSWORD"):
+		}
+		stmt.Users = append(stmt.Users, spec)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+
+	for !p.atEnd() {
+		switch {
+		case p.acceptSeq("DEFAULT", "ROLE"):
+			roles, err := p.accounts()
+			if err != nil {
+				return nil, err
+			}
+			stmt.DefaultRoles = roles
+		case p.accept("REQUIRE"):
+			if !p.accept("NONE") {
+				stmt.Ignored = append(stmt.Ignored, "REQUIRE")
+				for !p.atEnd() && !p.peek().Is("WITH") && !p.peek().Is("PASSWORD") && !p.peek().Is("ACCOUNT") &&
+					!p.peek().Is("COMMENT") && !p.peek().Is("ATTRIBUTE") {
+					p.next()
+				}
+			}
+		case p.accept("WITH"):
+			// At least one resource option, unlike PostgreSQL's CREATE USER ... WITH
+			if !strings.HasPrefix(p.peek().Upper(), "MAX_") {
+				return nil, p.errorf("expected resource option")
+			}
+			for {
+				switch name := p.peek().Upper(); name {
+				case "MAX_USER_CONNECTIONS":
+					p.next()
+					stmt.ConnLimit = p.next().Text
+					if stmt.ConnLimit == "0" {
+						stmt.ConnLimit = "-1"
+					}
+					continue
+				case "MAX_QUERIES_PER_HOUR", "MAX_UPDATES_PER_HOUR", "MAX_CONNECTIONS_PER_HOUR":
+					p.next()
+					p.next()
+					stmt.Ignored = append(stmt.Ignored, name)
+					continue
+				}
+				break
+			}
+		case p.peek().Is("PASSWORD") || p.peek().Is("FAILED_LOGIN_ATTEMPTS") || p.peek().Is("PASSWORD_LOCK_TIME"):
+			stmt.Ignored = append(stmt.Ignored, p.next().Upper())
+			for !p.atEnd() && !p.peek().Is("PASSWORD") && !p.peek().Is("ACCOUNT") && !p.peek().Is("COMMENT") &&
+				!p.peek().Is("ATTRIBUTE") && !p.peek().Is("FAILED_LOGIN_ATTEMPTS") && !p.peek().Is("PASSWORD_LOCK_TIME") {
+				p.next()
+			}
+		case p.accept("ACCOUNT"):
+			locked := p.next().Is("LOCK")
+			stmt.Lock = &locked
+		case p.accept("COMMENT"), p.accept("ATTRIBUTE"):
+			tok := p.next()
+			stmt.Comment = &tok
+		default:
+			return nil, p.errorf("unexpected user option")
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseDropUser() (Statement, error) {
+	p.next() // DROP
+	p.next() // USER
+	stmt := &DropUserStmt{IfExists: p.acceptSeq("IF", "EXISTS")}
+	users, err := p.accounts()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Users = users
+	return stmt, p.expectEnd()
+}
+
+func (p *parser) parseRenameUser() (Statement, error) {
+	p.next() // RENAME
+	p.next() // USER
+	stmt := &RenameUserStmt{}
+	for {
+		from, err := p.account()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("TO"); err != nil {
+			return nil, err
+		}
+		to, err := p.account()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Renames = append(stmt.Renames, [2]Account{from, to})
+		if !p.acceptOp(",") {
+			return stmt, p.expectEnd()
+		}
+	}
+}
+
+// parseGrant parses GRANT priv [(cols)], ... ON [type] target TO accounts
+// [WITH GRANT OPTION], GRANT role, ... TO accounts [WITH ADMIN OPTION] and
+// the matching REVOKE forms
+func (p *parser) parseGrant() (Statement, error) {
+	stmt := &GrantStmt{Revoke: p.next().Is("REVOKE")}
+	to := "TO"
+	if stmt.Revoke {
+		to = "FROM"
+	}
+
+	if findTopLevel(p.tokens, p.pos, "ON") < 0 {
+		roles, err := p.accounts()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Roles = roles
+	} else {
+		for {
+			var words []string
+			for tok := p.peek(); tok.Kind == TokIdent && !tok.Is("ON"); tok = p.peek() {
+				words = append(words, p.next().Upper())
+			}
+			if words == nil {
+				return nil, p.errorf("expected privilege")
+			}
+			priv := Privilege{Name: strings.Join(words, " ")}
+			if priv.Name == "ALL PRIVILEGES" {
+				priv.Name = "ALL"
+			}
+			if p.peek().IsOp("(") {
+				start := p.mark()
+				if err := p.skipGroup(); err != nil {
+					return nil, err
+				}
+				priv.Columns = p.since(start)
+			}
+			stmt.Privileges = append(stmt.Privileges, priv)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+		if err := p.expect("ON"); err != nil {
+			return nil, err
+		}
+		if tok := p.peek(); tok.Is("TABLE") || tok.Is("FUNCTION") || tok.Is("PROCEDURE") {
+			stmt.ObjectType = p.next().Upper()
+		}
+		if err := p.grantTarget(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expect(to); err != nil {
+		return nil, err
+	}
+	users, err := p.accounts()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Users = users
+	if !stmt.Revoke && (p.acceptSeq("WITH", "GRANT", "OPTION") || p.acceptSeq("WITH", "ADMIN", "OPTION")) {
+		stmt.GrantOption = true
+	}
+	return stmt, p.expectEnd()
+}
+
+// grantTarget parses *, *.*, db.*, db.table or table
+func (p *parser) grantTarget(stmt *GrantStmt) error {
+	if p.acceptOp("*") {
+		if p.acceptOp(".") {
+			if err := p.expectOp("*"); err != nil {
+				return err
+			}
+			stmt.Global = true
+		}
+		return nil
+	}
+	name := p.next()
+	if !name.IsIdent() {
+		return fmt.Errorf("syntax error: expected privilege target near '%s'", name.Text)
+	}
+	if !p.acceptOp(".") {
+		stmt.Table = []Token{name}
+		return nil
+	}
+	stmt.Schema = []Token{name}
+	if p.acceptOp("*") {
+		return nil
+	}
+	table := p.next()
+	if !table.IsIdent() {
+		return fmt.Errorf("syntax error: expected table name near '%s'", table.Text)
+	}
+	stmt.Table = []Token{table}
+	return nil
+}
+
+// parseSetPassword parses SET PASSWORD [FOR account] = {'password' | PASSWORD('password')}
+func (p *parser) parseSetPassword() (Statement, error) {
+	p.next() // SET
+	p.next() // PASSWORD
+	stmt := &SetPasswordStmt{}
+	if p.accept("FOR") {
+		account, err := p.account()
+		if err != nil {
+			return nil, err
+		}
+		stmt.User = &account
+	}
+	if err := p.expectOp("="); err != nil {
+		return nil, err
+	}
+	wrapped := p.accept("PASSWORD") && p.expectOp("(") == nil
+	stmt.Password = p.next()
+	if stmt.Password.Kind != TokString {
+		return nil, fmt.Errorf("syntax error: expected password string near '%s'", stmt.Password.Text)
+	}
+	if wrapped {
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, p.expectEnd()
+}
+
+// roleName renders a MySQL account as a PostgreSQL role. PostgreSQL roles
+// have no host part, so only accounts valid from any host can be mapped.
+func roleName(account Account) (string, error) {
+	if account.User == "CURRENT_USER" && account.Host == "" {
+		return "CURRENT_USER", nil
+	}
+	if account.User == "" {
+		return "", fmt.Errorf("anonymous accounts are not supported on PostgreSQL, every role has a name")
+	}
+	if account.Host != "" && account.Host != "%" {
+		return "", fmt.Errorf("account '%s'@'%s' cannot be represented on PostgreSQL: roles have no host part. "+
+			"Use '%s'@'%%' and restrict where it may connect from in pg_hba.conf",
+			account.User, account.Host, account.User)
+	}
+	return pgIdent(account.User), nil
+}
+
+// roleNames renders a list of accounts as roles
+func roleNames(accounts []Account) (string, error) {
+	var names []string
+	for _, account := range accounts {
+		name, err := roleName(account)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// ifRole wraps statements in a DO block that runs them only when the role
+// exists, or only when it does not, for MySQL's IF [NOT] EXISTS
+func ifRole(account Account, exists bool, statements []string) string {
+	cond := "NOT EXISTS"
+	if exists {
+		cond = "EXISTS"
+	}
+	return fmt.Sprintf("DO $mygo$ BEGIN IF %s (SELECT FROM pg_roles WHERE rolname = %s) THEN %s; END IF; END $mygo$",
+		cond, quoteLiteral(account.User), strings.Join(statements, "; "))
+}
+
+// translateCreateUser maps CREATE USER to CREATE ROLE ... LOGIN and ALTER
+// USER to ALTER ROLE, one account at a time
+func (t *Translator) translateCreateUser(s *CreateUserStmt) (*TranslationResult, error) {
+	defaultRoles, err := roleNames(s.DefaultRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []string
+	for _, user := range s.Users {
+		role, err := roleName(user.Account)
+		if err != nil {
+			return nil, err
+		}
+		if user.Unsupported != "" {
+			return nil, fmt.Errorf("IDENTIFIED %s is not supported on PostgreSQL, give the password with IDENTIFIED BY 'password'", user.Unsupported)
+		}
+		var attrs []string
+		switch {
+		case s.Lock != nil && *s.Lock:
+			attrs = append(attrs, "NOLOGIN")
+		case s.Lock != nil || !s.Alter:
+			attrs = append(attrs, "LOGIN")
+		}
+		if user.Password != nil {
+			attrs = append(attrs, "PASSWORD "+user.Password.Text)
+		}
+		if s.ConnLimit != "" {
+			attrs = append(attrs, "CONNECTION LIMIT "+s.ConnLimit)
+		}
+
+		var stmts []string
+		switch {
+		case !s.Alter:
+			stmts = append(stmts, "CREATE ROLE "+role+" "+strings.Join(attrs, " "))
+		case len(attrs) > 0:
+			stmts = append(stmts, "ALTER ROLE "+role+" "+strings.Join(attrs, " "))
+		}
+		if s.Comment != nil {
+			stmts = append(stmts, fmt.Sprintf("COMMENT ON ROLE %s IS %s", role, s.Comment.Text))
+		}
+		if defaultRoles != "" {
+			// A PostgreSQL role uses the privileges of the roles it is granted
+			stmts = append(stmts, fmt.Sprintf("GRANT %s TO %s", defaultRoles, role))
+		}
+		if s.IfExists && len(stmts) > 0 && role != "CURRENT_USER" {
+			stmts = []string{ifRole(user.Account, s.Alter, stmts)}
+		}
+		statements = append(statements, stmts...)
+	}
+
+	result := statementsResult(statements)
+	for _, clause := range s.Ignored {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s is not supported on PostgreSQL and was ignored", clause))
+	}
+	return result, nil
+}
+
+// translateDropUser drops each role after handing anything it owns to the
+// current user, as MySQL keeps a dropped user's tables
+func (t *Translator) translateDropUser(s *DropUserStmt) (*TranslationResult, error) {
+	var statements []string
+	for _, user := range s.Users {
+		role, err := roleName(user)
+		if err != nil {
+			return nil, err
+		}
+		stmts := []string{
+			"REASSIGN OWNED BY " + role + " TO CURRENT_USER",
+			"DROP OWNED BY " + role,
+			"DROP ROLE " + role,
+		}
+		if s.IfExists {
+			stmts = []string{ifRole(user, true, stmts)}
+		}
+		statements = append(statements, stmts...)
+	}
+	return statementsResult(statements), nil
+}
+
+func (t *Translator) translateRenameUser(s *RenameUserStmt) (*TranslationResult, error) {
+	var statements []string
+	for _, rename := range s.Renames {
+		from, err := roleName(rename[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := roleName(rename[1])
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fmt.Sprintf("ALTER ROLE %s RENAME TO %s", from, to))
+	}
+	return statementsResult(statements), nil
+}
+
+func (t *Translator) translateSetPassword(s *SetPasswordStmt) (*TranslationResult, error) {
+	role := "CURRENT_USER"
+	if s.User != nil {
+		var err error
+		if role, err = roleName(*s.User); err != nil {
+			return nil, err
+		}
+	}
+	return &TranslationResult{Query: fmt.Sprintf("ALTER ROLE %s PASSWORD %s", role, s.Password.Text)}, nil
+}
+
+// tablePrivileges are granted on tables under the same name
+var tablePrivileges = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "REFERENCES": true, "TRIGGER": true,
+}
+
+// ownerPrivileges are MySQL privileges that PostgreSQL reserves for the owner of an object
+var ownerPrivileges = map[string]bool{
+	"ALTER": true, "DROP": true, "INDEX": true, "ALTER ROUTINE": true,
+}
+
+// grants collects the PostgreSQL GRANT or REVOKE statements for one MySQL grant
+type grants struct {
+	s          *GrantStmt
+	users      string
+	statements []string
+	warnings   []string
+}
+
+// add appends GRANT privs ON object TO users, or the REVOKE form
+func (g *grants) add(privs, object string) {
+	if g.s.Revoke {
+		g.statements = append(g.statements, fmt.Sprintf("REVOKE %s ON %s FROM %s", privs, object, g.users))
+		return
+	}
+	stmt := fmt.Sprintf("GRANT %s ON %s TO %s", privs, object, g.users)
+	if g.s.GrantOption {
+		stmt += " WITH GRANT OPTION"
+	}
+	g.statements = append(g.statements, stmt)
+}
+
+// addDefault does the same for objects created later in the schema. Default
+// privileges apply to objects created by the role running the statement.
+func (g *grants) addDefault(schema, privs, objects string) {
+	alter := "ALTER DEFAULT PRIVILEGES IN SCHEMA " + schema + " "
+	if g.s.Revoke {
+		g.statements = append(g.statements, fmt.Sprintf("%sREVOKE %s ON %s FROM %s", alter, privs, objects, g.users))
+		return
+	}
+	stmt := fmt.Sprintf("%sGRANT %s ON %s TO %s", alter, privs, objects, g.users)
+	if g.s.GrantOption {
+		stmt += " WITH GRANT OPTION"
+	}
+	g.statements = append(g.statements, stmt)
+}
+
+// translateGrant maps MySQL privileges to PostgreSQL ones. A MySQL database
+// is a PostgreSQL database here, whose tables live in the public schema: db.*
+// names the database connected to, as does *, and covers all tables,
+// sequences and routines in public, now and as they are created. Other
+// databases cannot be reached from the connection. *.* has no counterpart
+// beyond USAGE, which grants nothing.
+func (t *Translator) translateGrant(s *GrantStmt) (*TranslationResult, error) {
+	users, err := roleNames(s.Users)
+	if err != nil {
+		return nil, err
+	}
+	g := &grants{s: s, users: users}
+
+	if s.Privileges == nil {
+		roles, err := roleNames(s.Roles)
+		if err != nil {
+			return nil, err
+		}
+		if s.Revoke {
+			return &TranslationResult{Query: fmt.Sprintf("REVOKE %s FROM %s", roles, users)}, nil
+		}
+		query := fmt.Sprintf("GRANT %s TO %s", roles, users)
+		if s.GrantOption {
+			query += " WITH ADMIN OPTION"
+		}
+		return &TranslationResult{Query: query}, nil
+	}
+
+	var table []string
+	var all, execute, create, insert bool
+	for _, priv := range s.Privileges {
+		switch {
+		case priv.Name == "ALL":
+			all = true
+		case tablePrivileges[priv.Name]:
+			name := priv.Name
+			if priv.Columns != nil {
+				if s.Table == nil {
+					return nil, fmt.Errorf("column privileges need a table, not a whole database")
+				}
+				name += " " + render(priv.Columns)
+			}
+			table = append(table, name)
+			insert = insert || priv.Name == "INSERT"
+		case priv.Name == "EXECUTE":
+			execute = true
+		case priv.Name == "CREATE", priv.Name == "CREATE VIEW", priv.Name == "CREATE ROUTINE":
+			create = true
+		case priv.Name == "USAGE":
+		case ownerPrivileges[priv.Name]:
+			g.warnings = append(g.warnings, fmt.Sprintf("privilege %s was ignored: on PostgreSQL only the owner of an object may do that", priv.Name))
+		default:
+			g.warnings = append(g.warnings, fmt.Sprintf("privilege %s was ignored: it has no PostgreSQL counterpart", priv.Name))
+		}
+	}
+	if s.Global {
+		if all || execute || create || table != nil {
+			return nil, fmt.Errorf("global privileges (ON *.*) are not supported on PostgreSQL, grant them on a database with db.* instead")
+		}
+		return &TranslationResult{Warnings: g.warnings}, nil
+	}
+
+	if s.Schema != nil {
+		catalog, err := t.requireCatalog("GRANT on a named database")
+		if err != nil {
+			return nil, err
+		}
+		if database := catalog.GetCurrentDatabase(); catalogName(s.Schema[0]) != database {
+			return nil, fmt.Errorf("privileges on database %s are not supported: PostgreSQL grants them in the current database, %s", s.Schema[0].Value, database)
+		}
+	}
+	schema := "public"
+	tablePrivs := strings.Join(table, ", ")
+	if all {
+		tablePrivs = "ALL PRIVILEGES"
+	}
+
+	if s.Table != nil {
+		object := render(s.Table)
+		switch {
+		case s.ObjectType == "FUNCTION" || s.ObjectType == "PROCEDURE":
+			if execute || all {
+				g.add("EXECUTE", s.ObjectType+" "+object)
+			}
+		case tablePrivs != "":
+			g.add(tablePrivs, "TABLE "+object)
+		}
+		if create {
+			g.warnings = append(g.warnings, "privilege CREATE on a single table was ignored: PostgreSQL grants CREATE on schemas")
+		}
+		return g.result(), nil
+	}
+
+	if !s.Revoke && (all || execute || create || tablePrivs != "") {
+		g.add("USAGE", "SCHEMA "+schema)
+	}
+	if tablePrivs != "" {
+		g.add(tablePrivs, "ALL TABLES IN SCHEMA "+schema)
+		g.addDefault(schema, tablePrivs, "TABLES")
+	}
+	if all || insert {
+		// Inserting into a serial column draws from its sequence
+		g.add("USAGE", "ALL SEQUENCES IN SCHEMA "+schema)
+		g.addDefault(schema, "USAGE", "SEQUENCES")
+	}
+	if all || execute {
+		g.add("EXECUTE", "ALL ROUTINES IN SCHEMA "+schema)
+		g.addDefault(schema, "EXECUTE", "FUNCTIONS")
+	}
+	if all || create {
+		g.add("CREATE", "SCHEMA "+schema)
+	}
+	if s.Revoke && all {
+		g.add("USAGE", "SCHEMA "+schema)
+	}
+	return g.result(), nil
+}
+
+func (g *grants) result() *TranslationResult {
+	result := statementsResult(g.statements)
+	result.Warnings = g.warnings
+	return result
+}
+
+// mysqlQuoted is the SQL expression that quotes the name expr in backticks,
+// as MySQL prints names in SHOW GRANTS
+func mysqlQuoted(expr string) string {
+	return "'`' || replace(" + expr + ", '`', '``') || '`'"
+}
+
+// showGrantsQuery lists the privileges of the role $1 as MySQL GRANT
+// statements, one per object: database privileges on *.*, CREATE on a schema
+// and its default table privileges on db.*, then tables, sequences, columns
+// and routines, and last the roles it is a member of. Owning an object is not
+// a grant, so an owner's own privileges are left out. The public schema is
+// printed as the current database, the name GRANT takes for it.
+var showGrantsQuery = strings.NewReplacer(
+	"{grantee}", mysqlQuoted("r.rolname")+" || '@`%`'",
+	"{role}", mysqlQuoted("g.rolname")+" || '@`%`'",
+	"{schema}", mysqlQuoted("CASE n.nspname WHEN 'public' THEN current_database() ELSE n.nspname END"),
+	"{relation}", mysqlQuoted("c.relname"),
+	"{column}", mysqlQuoted("t.attname"),
+	"{routine}", mysqlQuoted("p.proname"),
+).Replace(`WITH r AS (
+	SELECT oid, rolname, rolsuper FROM pg_roles WHERE rolname = $1
+), acl AS (
+	SELECT 1 AS ord, {schema} || '.*' AS object, a.privilege_type AS privilege, a.privilege_type AS base, a.is_grantable
+	FROM pg_namespace n, aclexplode(n.nspacl) a, r
+	WHERE a.grantee = r.oid AND a.grantee <> n.nspowner AND a.privilege_type = 'CREATE'
+	UNION ALL
+	SELECT 1, {schema} || '.*', a.privilege_type, a.privilege_type, a.is_grantable
+	FROM pg_default_acl d JOIN pg_namespace n ON n.oid = d.defaclnamespace, aclexplode(d.defaclacl) a, r
+	WHERE d.defaclobjtype = 'r' AND a.grantee = r.oid
+	UNION ALL
+	SELECT 2, {schema} || '.' || {relation}, a.privilege_type, a.privilege_type, a.is_grantable
+	FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace, aclexplode(c.relacl) a, r
+	WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S') AND a.grantee = r.oid AND a.grantee <> c.relowner
+	UNION ALL
+	SELECT 2, {schema} || '.' || {relation}, a.privilege_type || ' (' || string_agg({column}, ', ' ORDER BY t.attnum) || ')',
+		a.privilege_type, a.is_grantable
+	FROM pg_attribute t JOIN pg_class c ON c.oid = t.attrelid JOIN pg_namespace n ON n.oid = c.relnamespace,
+		aclexplode(t.attacl) a, r
+	WHERE NOT t.attisdropped AND a.grantee = r.oid
+	GROUP BY n.nspname, c.relname, a.privilege_type, a.is_grantable
+	UNION ALL
+	SELECT 3, CASE p.prokind WHEN 'p' THEN 'PROCEDURE ' ELSE 'FUNCTION ' END || {schema} || '.' || {routine},
+		a.privilege_type, a.privilege_type, a.is_grantable
+	FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace, aclexplode(p.proacl) a, r
+	WHERE a.grantee = r.oid AND a.grantee <> p.proowner
+)
+SELECT statement AS "Grants" FROM (
+	SELECT 0 AS ord, '' AS object, 'GRANT ' || CASE
+			WHEN r.rolsuper THEN 'ALL PRIVILEGES'
+			ELSE coalesce((
+				SELECT string_agg(CASE a.privilege_type WHEN 'TEMPORARY' THEN 'CREATE TEMPORARY TABLES' ELSE 'CREATE' END, ', '
+					ORDER BY a.privilege_type)
+				FROM pg_database d, aclexplode(d.datacl) a
+				WHERE d.datname = current_database() AND a.grantee = r.oid AND a.privilege_type IN ('CREATE', 'TEMPORARY')
+			), 'USAGE')
+		END || ' ON *.* TO ' || {grantee} || CASE WHEN r.rolsuper THEN ' WITH GRANT OPTION' ELSE '' END AS statement
+	FROM r
+	UNION ALL
+	SELECT ord, object, 'GRANT ' || CASE
+			WHEN array_agg(privilege) @> ARRAY['SELECT', 'INSERT', 'UPDATE', 'DELETE', 'REFERENCES', 'TRIGGER'] THEN 'ALL PRIVILEGES'
+			ELSE string_agg(privilege, ', ' ORDER BY array_position(
+				ARRAY['SELECT', 'INSERT', 'UPDATE', 'DELETE', 'CREATE', 'REFERENCES', 'TRIGGER', 'USAGE', 'EXECUTE'], base), privilege)
+		END || ' ON ' || object || ' TO ' || {grantee} || CASE WHEN is_grantable THEN ' WITH GRANT OPTION' ELSE '' END
+	FROM acl, r
+	WHERE base IN ('SELECT', 'INSERT', 'UPDATE', 'DELETE', 'CREATE', 'REFERENCES', 'TRIGGER', 'USAGE', 'EXECUTE')
+	GROUP BY ord, object, is_grantable, r.rolname
+	UNION ALL
+	-- Since PostgreSQL 16 a membership may be granted once per grantor
+	SELECT 4, '', 'GRANT ' || string_agg(DISTINCT {role}, ',' ORDER BY {role}) || ' TO ' || {grantee} ||
+		CASE WHEN m.admin_option THEN ' WITH ADMIN OPTION' ELSE '' END
+	FROM pg_auth_members m JOIN pg_roles g ON g.oid = m.roleid, r
+	WHERE m.member = r.oid
+	GROUP BY m.admin_option, r.rolname
+) grants
+ORDER BY ord, object, statement`)
+
+// showGrants looks up the privileges of a role with showGrantsQuery. The
+// client resolves an empty role name to the current user and names the
+// result column after the account, as MySQL does.
+func (t *Translator) showGrants(s *ShowStmt) (*TranslationResult, error) {
+	user := ""
+	if s.User != "" && s.User != "CURRENT_USER" {
+		if _, err := roleName(Account{User: s.User, Host: s.Host}); err != nil {
+			return nil, err
+		}
+		user = s.User
+	}
+	return &TranslationResult{
+		Query:       showGrantsQuery,
+		IsSpecial:   true,
+		SpecialType: "show_grants",
+		Args:        []string{user},
+	}, nil
+}
@@ -0,0 +1,176 @@
+package translator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateUsers(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{database: "shop"})
+
+	tests := []struct {
+		input    string
+		want     []string
+		warnings []string
+	}{
+		{"CREATE USER 'app'@'%' IDENTIFIED BY 'secret'", []string{"CREATE ROLE app LOGIN PASSWORD 'secret'"}, nil},
+		{
+			"CREATE USER IF NOT EXISTS 'Report' IDENTIFIED WITH caching_sha2_password BY 'pw' WITH MAX_USER_CONNECTIONS 5 MAX_QUERIES_PER_HOUR 100",
+			[]string{`DO $mygo$ BEGIN IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'Report') THEN CREATE ROLE "Report" LOGIN PASSWORD 'pw' CONNECTION LIMIT 5; END IF; END $mygo$`},
+			[]string{"MAX_QUERIES_PER_HOUR is not supported on PostgreSQL and was ignored"},
+		},
+		{
+			"CREATE USER a@'%', b DEFAULT ROLE reader ACCOUNT LOCK COMMENT 'batch'",
+			[]string{
+				"CREATE ROLE a NOLOGIN", "COMMENT ON ROLE a IS 'batch'", "GRANT reader TO a",
+				"CREATE ROLE b NOLOGIN", "COMMENT ON ROLE b IS 'batch'", "GRANT reader TO b",
+			},
+			nil,
+		},
+		{"CREATE USER bob WITH PASSWORD 'x'", []string{"CREATE USER bob WITH PASSWORD 'x'"}, nil},
+		{"ALTER USER 'app'@'%' IDENTIFIED BY 'new' PASSWORD EXPIRE", []string{"ALTER ROLE app PASSWORD 'new'"}, []string{"PASSWORD is not supported on PostgreSQL and was ignored"}},
+		{"ALTER USER USER() IDENTIFIED BY 'mine'", []string{"ALTER ROLE CURRENT_USER PASSWORD 'mine'"}, nil},
+		{
+			"ALTER USER IF EXISTS app ACCOUNT UNLOCK",
+			[]string{"DO $mygo$ BEGIN IF EXISTS (SELECT FROM pg_roles WHERE rolname = 'app') THEN ALTER ROLE app LOGIN; END IF; END $mygo$"},
+			nil,
+		},
+		{
+			"DROP USER 'app'@'%'",
+			[]string{"REASSIGN OWNED BY app TO CURRENT_USER", "DROP OWNED BY app", "DROP ROLE app"},
+			nil,
+		},
+		{"RENAME USER old TO new", []string{"ALTER ROLE old RENAME TO new"}, nil},
+		{"SET PASSWORD FOR 'app'@'%' = 'pw'", []string{"ALTER ROLE app PASSWORD 'pw'"}, nil},
+		{"SET PASSWORD = PASSWORD('pw')", []string{"ALTER ROLE CURRENT_USER PASSWORD 'pw'"}, nil},
+		{"FLUSH PRIVILEGES", []string{""}, nil},
+		{
+			"GRANT SELECT, INSERT ON shop.* TO 'app'@'%'",
+			[]string{
+				"GRANT USAGE ON SCHEMA public TO app",
+				"GRANT SELECT, INSERT ON ALL TABLES IN SCHEMA public TO app",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT ON TABLES TO app",
+				"GRANT USAGE ON ALL SEQUENCES IN SCHEMA public TO app",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE ON SEQUENCES TO app",
+			},
+			nil,
+		},
+		{
+			"GRANT ALL PRIVILEGES ON `shop`.* TO admin WITH GRANT OPTION",
+			[]string{
+				"GRANT USAGE ON SCHEMA public TO admin WITH GRANT OPTION",
+				"GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO admin WITH GRANT OPTION",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT ALL PRIVILEGES ON TABLES TO admin WITH GRANT OPTION",
+				"GRANT USAGE ON ALL SEQUENCES IN SCHEMA public TO admin WITH GRANT OPTION",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE ON SEQUENCES TO admin WITH GRANT OPTION",
+				"GRANT EXECUTE ON ALL ROUTINES IN SCHEMA public TO admin WITH GRANT OPTION",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT EXECUTE ON FUNCTIONS TO admin WITH GRANT OPTION",
+				"GRANT CREATE ON SCHEMA public TO admin WITH GRANT OPTION",
+			},
+			nil,
+		},
+		{
+			"GRANT SELECT (id, name), UPDATE (name), DROP ON shop.users TO app",
+			[]string{"GRANT SELECT (id, name), UPDATE (name) ON TABLE users TO app"},
+			[]string{"privilege DROP was ignored: on PostgreSQL only the owner of an object may do that"},
+		},
+		{
+			"REVOKE SELECT ON shop.* FROM app",
+			[]string{
+				"REVOKE SELECT ON ALL TABLES IN SCHEMA public FROM app",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT ON TABLES FROM app",
+			},
+			nil,
+		},
+		{
+			"GRANT SELECT ON * TO app",
+			[]string{
+				"GRANT USAGE ON SCHEMA public TO app",
+				"GRANT SELECT ON ALL TABLES IN SCHEMA public TO app",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT ON TABLES TO app",
+			},
+			nil,
+		},
+		{"GRANT USAGE ON *.* TO app", []string{""}, nil},
+		{"GRANT reader, writer TO app", []string{"GRANT reader, writer TO app"}, nil},
+		{"REVOKE reader FROM app", []string{"REVOKE reader FROM app"}, nil},
+		{"GRANT SELECT ON ALL TABLES IN SCHEMA public TO app", []string{"GRANT SELECT ON ALL TABLES IN SCHEMA public TO app"}, nil},
+	}
+
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		got := result.Statements
+		if got == nil {
+			got = []string{result.Query}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("for %s:\n got: %q\nwant: %q", tt.input, got, tt.want)
+		}
+		if !reflect.DeepEqual(result.Warnings, tt.warnings) {
+			t.Errorf("for %s:\n got warnings: %q\nwant: %q", tt.input, result.Warnings, tt.warnings)
+		}
+	}
+}
+
+func TestTranslateUsersErrors(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetCatalog(&fakeCatalog{database: "shop"})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"CREATE USER 'app'@'localhost' IDENTIFIED BY 'x'", "'app'@'localhost' cannot be represented on PostgreSQL"},
+		{"GRANT SELECT ON shop.* TO 'app'@'10.0.0.%'", "roles have no host part"},
+		{"DROP USER ''@'%'", "anonymous accounts"},
+		{"CREATE USER app IDENTIFIED WITH mysql_native_password AS '*HASH'", "not supported"},
+		{"GRANT SELECT ON *.* TO app", "global privileges"},
+		{"GRANT SELECT ON other.* TO app", "privileges on database other are not supported"},
+		{"GRANT SELECT ON other.users TO app", "privileges on database other are not supported"},
+	}
+	for _, tt := range tests {
+		_, err := tr.Translate(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("for %s: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+}
+
+func TestTranslateShowGrants(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input string
+		user  string
+	}{
+		{"SHOW GRANTS", ""},
+		{"SHOW GRANTS FOR CURRENT_USER()", ""},
+		{"SHOW GRANTS FOR 'app'@'%'", "app"},
+		{"SHOW GRANTS FOR 'O''Brien'@'%'", "O'Brien"},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.SpecialType != "show_grants" || !reflect.DeepEqual(result.Args, []string{tt.user}) {
+			t.Errorf("for %s: got %s %q", tt.input, result.SpecialType, result.Args)
+		}
+		if !strings.Contains(result.Query, "rolname = $1") || strings.Contains(result.Query, "Brien") {
+			t.Errorf("for %s: expected the role as a bind parameter, got %s", tt.input, result.Query)
+		}
+	}
+
+	if _, err := tr.Translate("SHOW GRANTS FOR 'app'@'localhost'"); err == nil {
+		t.Error("expected an error for an account with a host")
+	}
+}
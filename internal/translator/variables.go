@@ -0,0 +1,310 @@
+package translator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetVariablesStmt is SET @var = expr [, @var = expr ...]
+type SetVariablesStmt struct {
+	Names  []string  // Lower-cased variable names
+	Values [][]Token // Expression assigned to each variable
+}
+
+func (*SetVariablesStmt) statementNode() {}
+
+// SetVariableType records the PostgreSQL type of the value of a session user
+// variable, to which its parameter is cast. An empty type leaves it untyped,
+// as for strings, which PostgreSQL then takes for whatever type they are
+// compared with, much as MySQL converts them.
+func (t *Translator) SetVariableType(name, pgType string) {
+	if t.varTypes == nil {
+		t.varTypes = map[string]string{}
+	}
+	t.varTypes[strings.ToLower(name)] = pgType
+}
+
+// variableBinder replaces the user variables a statement reads with bind
+// parameters, numbered after the statement's own ? placeholders. The client
+// keeps the variables and binds their values.
+type variableBinder struct {
+	offset int
+	types  map[string]string
+	names  []string // Variable bound to each parameter, in order
+}
+
+func (t *Translator) newVariableBinder(parts ...[]Token) *variableBinder {
+	b := &variableBinder{types: t.varTypes}
+	for _, tokens := range parts {
+		b.offset += countPlaceholders(tokens)
+	}
+	return b
+}
+
+// bind replaces each @var in tokens with its parameter
+func (b *variableBinder) bind(tokens []Token) []Token {
+	out := make([]Token, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.Kind == TokVariable {
+			tok = b.parameter(tok, "")
+		}
+		out = append(out, tok)
+	}
+	return out
+}
+
+// parameter returns the parameter of a variable, cast to the type of its
+// value or else to fallback
+func (b *variableBinder) parameter(tok Token, fallback string) Token {
+	name := variableName(tok)
+	text := "$" + strconv.Itoa(b.param(name))
+	if pgType := b.types[name]; pgType != "" {
+		text += "::" + pgType
+	} else if fallback != "" {
+		text += "::" + fallback
+	}
+	return Token{Kind: TokParam, Text: text, Pos: tok.Pos}
+}
+
+// param returns the parameter number of a variable, reusing it for a
+// variable that is read more than once
+func (b *variableBinder) param(name string) int {
+	for i, known := range b.names {
+		if known == name {
+			return b.offset + i + 1
+		}
+	}
+	b.names = append(b.names, name)
+	return b.offset + len(b.names)
+}
+
+// variableName returns the name under which the client keeps a user
+// variable; like MySQL's, the names are case-insensitive
+func variableName(tok Token) string {
+	return strings.ToLower(tok.Value)
+}
+
+// countPlaceholders counts the ? placeholders among tokens
+func countPlaceholders(tokens []Token) int {
+	n := 0
+	for _, tok := range tokens {
+		if tok.Kind == TokParam {
+			n++
+		}
+	}
+	return n
+}
+
+// mixesVariables reports whether a SET assigns both user variables and
+// system variables, which are set in different ways
+func (p *parser) mixesVariables() bool {
+	tokens := p.rest()
+	user, system := false, false
+	for _, part := range splitTopLevel(tokens[nextSignificant(tokens, 0)+1:], ",") {
+		if first := nextSignificant(part, 0); first < len(part) {
+			user = user || part[first].Kind == TokVariable
+			system = system || part[first].Kind != TokVariable
+		}
+	}
+	return user && system
+}
+
+func (p *parser) parseSetVariables() (Statement, error) {
+	p.next() // SET
+	stmt := &SetVariablesStmt{}
+	for {
+		tok := p.next()
+		if tok.Kind != TokVariable {
+			return nil, fmt.Errorf("syntax error: expected user variable near '%s'", tok.Text)
+		}
+		if !p.acceptOp("=") && !p.acceptOp(":=") {
+			return nil, p.errorf("expected = after %s", tok.Text)
+		}
+		start := p.mark()
+		for !p.atEnd() && !p.peek().IsOp(",") {
+			if p.peek().IsOp("(") {
+				if err := p.skipGroup(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			p.next()
+		}
+		value := p.since(start)
+		if len(value) == 0 {
+			return nil, p.errorf("expected value for %s", tok.Text)
+		}
+		stmt.Names = append(stmt.Names, variableName(tok))
+		stmt.Values = append(stmt.Values, value)
+		if !p.acceptOp(",") {
+			return stmt, nil
+		}
+	}
+}
+
+// translateSetVariables evaluates the assigned expressions with a SELECT,
+// from whose row the client sets the variables
+func (t *Translator) translateSetVariables(s *SetVariablesStmt) (*TranslationResult, error) {
+	binder := t.newVariableBinder(s.Values...)
+	values := make([]string, len(s.Values))
+	for i, value := range s.Values {
+		rewritten, err := t.rewriteTokens(binder.bind(value))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = render(rewritten)
+	}
+	return &TranslationResult{
+		Query:   "SELECT " + strings.Join(values, ", "),
+		Params:  binder.names,
+		OutVars: s.Names,
+	}, nil
+}
+
+// boundVariables are the user variables of a query, which the client binds
+// and assigns
+type boundVariables struct {
+	params     []string
+	outVars    []string          // SELECT ... INTO @var, ...
+	selectVars map[string]string // SELECT @var := expr, by column name
+	warnings   []string
+}
+
+// bindingVerbs are the statements whose user variables are bound. Others
+// either have none or take them apart themselves, like SET and CALL.
+var bindingVerbs = map[string]bool{
+	"SELECT": true, "WITH": true, "INSERT": true, "REPLACE": true, "UPDATE": true, "DELETE": true, "DO": true,
+}
+
+// maxIdentifierLength is the length beyond which PostgreSQL truncates names
+const maxIdentifierLength = 63
+
+// selectListEnd are the keywords that may follow a SELECT list
+var selectListEnd = []string{"INTO", "FROM", "WHERE", "GROUP", "HAVING", "WINDOW", "ORDER", "LIMIT", "UNION", "FOR", "LOCK"}
+
+// bindUserVariables replaces the user variables a query reads with bind
+// parameters. A SELECT may also assign them: @var := expr items and an INTO
+// @var, ... clause are taken out for the client to carry out with the result.
+func (t *Translator) bindUserVariables(tokens []Token) ([]Token, *boundVariables, error) {
+	first := nextSignificant(tokens, 0)
+	if first == len(tokens) || !bindingVerbs[tokens[first].Upper()] || !hasUserVariables([][]Token{tokens}) {
+		return tokens, nil, nil
+	}
+	bound := &boundVariables{}
+	binder := t.newVariableBinder(tokens)
+
+	if tokens[first].Is("SELECT") {
+		var err error
+		if tokens, bound.outVars, err = selectInto(tokens); err != nil {
+			return nil, nil, err
+		}
+		tokens, bound.selectVars, bound.warnings = binder.bindSelectList(tokens, first+1)
+	}
+	for i, tok := range tokens {
+		if tok.Kind == TokVariable {
+			if next := nextSignificant(tokens, i+1); next < len(tokens) && tokens[next].IsOp(":=") {
+				return nil, nil, fmt.Errorf("assigning %s is only supported as an item of the SELECT list", tok.Text)
+			}
+		}
+	}
+	tokens = binder.bind(tokens)
+	bound.params = binder.names
+	return tokens, bound, nil
+}
+
+// selectInto takes the INTO @var, ... clause out of a SELECT, either after
+// the SELECT list or at the end
+func selectInto(tokens []Token) ([]Token, []string, error) {
+	into := findTopLevel(tokens, 0, "INTO")
+	if into < 0 {
+		return tokens, nil, nil
+	}
+	next := nextSignificant(tokens, into+1)
+	if next == len(tokens) || tokens[next].Kind != TokVariable {
+		// INTO OUTFILE and the like
+		return tokens, nil, nil
+	}
+	var names []string
+	end := next
+	for {
+		names = append(names, variableName(tokens[end]))
+		end = nextSignificant(tokens, end+1)
+		if end == len(tokens) || !tokens[end].IsOp(",") {
+			break
+		}
+		end = nextSignificant(tokens, end+1)
+		if end == len(tokens) || tokens[end].Kind != TokVariable {
+			return nil, nil, fmt.Errorf("syntax error: expected user variable after INTO")
+		}
+	}
+	out := append(append([]Token{}, tokens[:into]...), tokens[end:]...)
+	return out, names, nil
+}
+
+// bindSelectList turns @var := expr items of the SELECT list starting at
+// start into expr AS column, where the column is named after the item as
+// written, as in MySQL's result, unless the item has an alias. A lone @var
+// is named the same way, and cast to text if its type is unknown, which
+// PostgreSQL could not tell otherwise.
+func (b *variableBinder) bindSelectList(tokens []Token, start int) ([]Token, map[string]string, []string) {
+	end := findTopLevel(tokens, start, selectListEnd...)
+	if end < 0 {
+		end = len(tokens)
+	}
+	var vars map[string]string
+	var list []Token
+	var warnings []string
+	for i, item := range splitTopLevel(tokens[start:end], ",") {
+		if i > 0 {
+			list = append(list, Token{Kind: TokOperator, Text: ","})
+		}
+		first := nextSignificant(item, 0)
+		if first == len(item) || item[first].Kind != TokVariable {
+			list = append(list, item...)
+			continue
+		}
+		expr, alias := item, []Token(nil)
+		column := renderTrimmed(item)
+		if len(column) > maxIdentifierLength {
+			column = item[first].Text
+		}
+		if as := findTopLevel(item, 0, "AS"); as >= 0 {
+			expr, alias = item[:as], item[as:]
+			if name := nextSignificant(item, as+1); name < len(item) {
+				column = catalogName(item[name])
+			}
+		}
+
+		var value []Token
+		switch assign := nextSignificant(expr, first+1); {
+		case assign == len(expr):
+			value = []Token{b.parameter(item[first], "text")}
+		case expr[assign].IsOp(":="):
+			name := variableName(item[first])
+			if vars == nil {
+				vars = map[string]string{}
+			}
+			vars[column] = name
+			value = trimTrivia(expr[assign+1:])
+			for _, tok := range value {
+				if tok.Kind == TokVariable && variableName(tok) == name {
+					warnings = append(warnings, fmt.Sprintf("%s is evaluated once for the whole query rather than for each row", renderTrimmed(expr)))
+					break
+				}
+			}
+		default:
+			list = append(list, item...)
+			continue
+		}
+		list = append(append(list, item[:first]...), value...)
+		if alias != nil {
+			list = append(append(list, pgTokens(" ")...), alias...)
+		} else {
+			list = append(list, pgTokens(" AS "+quoteIdent(column))...)
+			list = append(list, item[prevSignificant(item, len(item))+1:]...)
+		}
+	}
+	out := append(append(append([]Token{}, tokens[:start]...), list...), tokens[end:]...)
+	return out, vars, warnings
+}
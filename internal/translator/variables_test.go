@@ -0,0 +1,128 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+
+	"gomypg/internal/db"
+)
+
+func TestTranslateSetVariables(t *testing.T) {
+	tr := New(db.PostgreSQL)
+
+	tests := []struct {
+		input   string
+		query   string
+		params  []string
+		outVars []string
+	}{
+		{"SET @a = 1", "SELECT 1", nil, []string{"a"}},
+		{"SET @Name := CONCAT('x', 'y'), @n = @n + 1", "SELECT CONCAT('x', 'y'), $1 + 1", []string{"n"}, []string{"name", "n"}},
+		{"SET @`my var` = IF(@a > 1, 'big', \"small\")", "SELECT CASE WHEN $1 > 1 THEN 'big' ELSE 'small' END", []string{"a"}, []string{"my var"}},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.query || !reflect.DeepEqual(result.Params, tt.params) || !reflect.DeepEqual(result.OutVars, tt.outVars) {
+			t.Errorf("for %s: got %q %q %q, want %q %q %q", tt.input,
+				result.Query, result.Params, result.OutVars, tt.query, tt.params, tt.outVars)
+		}
+	}
+}
+
+func TestTranslateUserVariables(t *testing.T) {
+	tr := New(db.PostgreSQL)
+	tr.SetVariableType("n", "int8")
+	tr.SetVariableType("cutoff", "")
+
+	tests := []struct {
+		input      string
+		query      string
+		params     []string
+		outVars    []string
+		selectVars map[string]string
+	}{
+		{
+			"SELECT * FROM orders WHERE created_at > @cutoff",
+			"SELECT * FROM orders WHERE created_at > $1",
+			[]string{"cutoff"}, nil, nil,
+		},
+		{
+			"SELECT * FROM users WHERE id = ? AND score > @n AND score < @n * 2 LIMIT 5",
+			"SELECT * FROM users WHERE id = $1 AND score > $2::int8 AND score < $2::int8 * 2 LIMIT 5",
+			[]string{"n"}, nil, nil,
+		},
+		{
+			"SELECT @id, @N",
+			`SELECT $1::text AS "@id", $2::int8 AS "@N"`,
+			[]string{"id", "n"}, nil, nil,
+		},
+		{
+			"SELECT @n := COUNT(*) FROM users",
+			`SELECT COUNT(*) AS "@n := COUNT(*)" FROM users`,
+			nil, nil, map[string]string{"@n := COUNT(*)": "n"},
+		},
+		{
+			"SELECT name, @total := SUM(amount) AS total FROM orders GROUP BY name",
+			"SELECT name, SUM(amount) AS total FROM orders GROUP BY name",
+			nil, nil, map[string]string{"total": "total"},
+		},
+		{
+			"SELECT id, name INTO @id, @name FROM users WHERE id = @n",
+			"SELECT id, name FROM users WHERE id = $1::int8",
+			[]string{"n"}, []string{"id", "name"}, nil,
+		},
+		{
+			"SELECT MAX(id) FROM users INTO @max",
+			"SELECT MAX(id) FROM users",
+			nil, []string{"max"}, nil,
+		},
+		{
+			"UPDATE users SET score = @n, note = @note WHERE id = ?",
+			"UPDATE users SET score = $2::int8, note = $3 WHERE id = $1",
+			[]string{"n", "note"}, nil, nil,
+		},
+		{
+			"INSERT INTO logs (message) VALUES (CONCAT('cutoff ', @cutoff))",
+			"INSERT INTO logs (message) VALUES (CONCAT('cutoff ', $1))",
+			[]string{"cutoff"}, nil, nil,
+		},
+	}
+	for _, tt := range tests {
+		result, err := tr.Translate(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.input, err)
+			continue
+		}
+		if result.Query != tt.query || !reflect.DeepEqual(result.Params, tt.params) ||
+			!reflect.DeepEqual(result.OutVars, tt.outVars) || !reflect.DeepEqual(result.SelectVars, tt.selectVars) {
+			t.Errorf("for %s:\n got: %q %q %q %q\nwant: %q %q %q %q", tt.input,
+				result.Query, result.Params, result.OutVars, result.SelectVars, tt.query, tt.params, tt.outVars, tt.selectVars)
+		}
+	}
+
+	// Row numbering with a variable cannot be carried over
+	result, err := tr.Translate("SELECT @n := @n + 1 AS rownum, name FROM users")
+	if err != nil || len(result.Warnings) != 1 || result.Query != "SELECT $1::int8 + 1 AS rownum, name FROM users" {
+		t.Errorf("got %+v, %v", result, err)
+	}
+
+	for _, input := range []string{
+		"UPDATE users SET score = @n := 1",
+		"SELECT 1 + (@n := 2)",
+		"SELECT id INTO @a, 1 FROM users",
+	} {
+		if _, err := tr.Translate(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+
+	// User names and hosts are not variables
+	result, err = tr.Translate("CREATE USER 'app'@'%'")
+	if err != nil || result.Params != nil {
+		t.Errorf("CREATE USER: got %+v, %v", result, err)
+	}
+}